@@ -1,23 +1,136 @@
 package main
 
 import (
-	"log"
-	"net/http"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/go-ms-project-store/internal/adapters/input/http/routes"
+	"github.com/go-ms-project-store/internal/core/repositories"
+	"github.com/go-ms-project-store/internal/core/services"
+	"github.com/go-ms-project-store/internal/pkg/config"
+	"github.com/go-ms-project-store/internal/pkg/db"
+	"github.com/go-ms-project-store/internal/pkg/health"
 	"github.com/go-ms-project-store/internal/pkg/logger"
-	"github.com/joho/godotenv"
+	"github.com/go-ms-project-store/internal/pkg/metrics"
+	"github.com/go-ms-project-store/internal/pkg/migrate"
+	"github.com/go-ms-project-store/internal/pkg/server"
+	"github.com/go-ms-project-store/internal/pkg/tracing"
 )
 
 func main() {
-	err := godotenv.Load()
+	cfg, err := config.Load(os.Args[1:])
 	if err != nil {
-		logger.Fatal("Error loading .env file")
+		logger.Fatal("Invalid configuration:\n" + err.Error())
 	}
 
-	logger.Info("Starting the application")
-	mux := routes.Routes()
+	logger.Info("Starting the application with configuration:\n" + cfg.String())
 
-	log.Fatal(http.ListenAndServe("localhost:8686", mux))
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing, cfg.App.Name)
+	if err != nil {
+		logger.Fatal("Error while configuring tracing " + err.Error())
+	}
+
+	dbClient := db.GetDBClient(cfg.DB)
+	metrics.RegisterDB(dbClient.DB, cfg.DB.Name)
+
+	healthRegistry := health.NewRegistry(cfg.Server.HealthCheckTimeout)
+	healthRegistry.Register(health.DBChecker(dbClient))
+
+	migrator, err := migrate.New(dbClient)
+	if err != nil {
+		logger.Fatal("Error while loading migrations " + err.Error())
+	}
+
+	// Readiness reports pending migrations either way
+	healthRegistry.Register(health.MigrationsChecker(migrator))
+
+	if cfg.DB.RequireMigrations {
+		pending, err := migrator.Pending(context.Background())
+		if err != nil {
+			logger.Fatal("Error while checking migrations " + err.Error())
+		}
+		if len(pending) > 0 {
+			logger.Fatal(fmt.Sprintf("%d migrations are pending, apply them with the migrate command", len(pending)))
+		}
+	}
+
+	mux := routes.Routes(cfg, dbClient, healthRegistry)
+
+	janitorCtx, stopJanitor := context.WithCancel(context.Background())
+	janitorDone := make(chan struct{})
+	go func() {
+		defer close(janitorDone)
+		services.NewTokenJanitor(repositories.NewAuthRepositoryDB(dbClient), cfg.Auth).Run(janitorCtx)
+	}()
+
+	srv, err := server.New(cfg.Server, mux)
+	if err != nil {
+		logger.Fatal("Error while configuring the server " + err.Error())
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.Info("Listening on " + srv.Addr())
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+
+	exitCode := 0
+wait:
+	for {
+		select {
+		case err := <-serveErr:
+			if err != nil {
+				logger.Error("Server stopped unexpectedly " + err.Error())
+				exitCode = 1
+			}
+			break wait
+		case sig := <-signals:
+			if sig == syscall.SIGHUP {
+				if err := srv.ReloadTLS(); err != nil {
+					logger.Error("Error while reloading TLS certificate " + err.Error())
+				} else {
+					logger.Info("Reloaded TLS certificate")
+				}
+				continue
+			}
+
+			logger.Info("Received " + sig.String() + ", draining connections")
+			healthRegistry.SetDraining()
+			time.Sleep(cfg.Server.DrainDelay)
+
+			ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+			if err := srv.Shutdown(ctx); err != nil {
+				logger.Error("Error while draining connections " + err.Error())
+				exitCode = 1
+			}
+			cancel()
+			break wait
+		}
+	}
+
+	// Let a running cleanup finish its batch before the database closes
+	stopJanitor()
+	<-janitorDone
+
+	if err := dbClient.Close(); err != nil {
+		logger.Error("Error while closing the database " + err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Error("Error while flushing traces " + err.Error())
+	}
+	cancel()
+
+	logger.Info("Application stopped")
+	_ = logger.Sync()
 
+	os.Exit(exitCode)
 }
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/go-ms-project-store/internal/pkg/config"
+	"github.com/go-ms-project-store/internal/pkg/db"
+	"github.com/go-ms-project-store/internal/pkg/migrate"
+)
+
+const usage = `Usage: migrate <command> [steps] [configuration flags]
+
+Commands:
+  up       apply every pending migration
+  down     roll back the last migrations, one unless steps is given
+  status   list the migrations and whether they are applied
+  redo     roll back the last migrations and apply them again
+
+Configuration is read like the API does, e.g. -db-host or DB_HOST.
+`
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) == 0 || !isCommand(args[0]) {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	command, args := args[0], args[1:]
+
+	steps := 1
+	if len(args) > 0 {
+		if n, err := strconv.Atoi(args[0]); err == nil {
+			if n < 1 {
+				return fmt.Errorf("steps must be at least 1")
+			}
+			steps, args = n, args[1:]
+		}
+	}
+
+	cfg, err := config.Load(args)
+	if err != nil {
+		return fmt.Errorf("invalid configuration:\n%w", err)
+	}
+
+	dbClient := db.GetDBClient(cfg.DB)
+	defer dbClient.Close()
+
+	migrator, err := migrate.New(dbClient)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	var done []migrate.Migration
+	switch command {
+	case "up":
+		done, err = migrator.Up(ctx)
+	case "down":
+		done, err = migrator.Down(ctx, steps)
+	case "redo":
+		done, err = migrator.Redo(ctx, steps)
+	case "status":
+		return printStatus(ctx, migrator)
+	}
+
+	for _, migration := range done {
+		fmt.Printf("%s %04d_%s\n", command, migration.Version, migration.Name)
+	}
+	if err != nil {
+		return err
+	}
+	if len(done) == 0 {
+		fmt.Println("Nothing to migrate")
+	}
+
+	return nil
+}
+
+func isCommand(name string) bool {
+	switch name {
+	case "up", "down", "status", "redo":
+		return true
+	}
+
+	return false
+}
+
+func printStatus(ctx context.Context, migrator *migrate.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATE\tAPPLIED AT")
+	for _, status := range statuses {
+		appliedAt := "-"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", status.Version, status.Name, status.State, appliedAt)
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-ms-project-store/internal/pkg/config"
+	"github.com/go-ms-project-store/internal/pkg/db"
+	"github.com/go-ms-project-store/internal/pkg/migrate"
+	"github.com/go-ms-project-store/internal/pkg/seed"
+)
+
+const usage = `Usage: seed [profile] [seed] [configuration flags]
+
+Profiles:
+  minimal     one admin and one customer
+  demo        a few hundred products and a year of orders (default)
+  load-test   thousands of products and two years of orders
+
+The same seed always produces the same data, 1 unless given.
+Configuration is read like the API does, e.g. -db-host or DB_HOST.
+`
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	name := "demo"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	profile, ok := seed.Profiles[name]
+	if !ok {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var value uint64 = 1
+	if len(args) > 0 {
+		if n, err := strconv.ParseUint(args[0], 10, 64); err == nil {
+			value, args = n, args[1:]
+		}
+	}
+
+	cfg, err := config.Load(args)
+	if err != nil {
+		return fmt.Errorf("invalid configuration:\n%w", err)
+	}
+
+	dbClient := db.GetDBClient(cfg.DB)
+	defer dbClient.Close()
+
+	ctx := context.Background()
+
+	migrator, err := migrate.New(dbClient)
+	if err != nil {
+		return err
+	}
+
+	pending, err := migrator.Pending(ctx)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%d migrations are pending, run migrate up first", len(pending))
+	}
+
+	summary, err := seed.New(dbClient, value).Run(ctx, profile)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Seeded profile %s with seed %d\n", name, value)
+	fmt.Printf("  customers   %d\n", summary.Customers)
+	fmt.Printf("  categories  %d\n", summary.Categories)
+	fmt.Printf("  products    %d\n", summary.Products)
+	fmt.Printf("  orders      %d\n", summary.Orders)
+	fmt.Println()
+	fmt.Printf("Every user signs in with the password %q\n", seed.Password)
+	for _, email := range summary.Admins {
+		fmt.Printf("  admin       %s\n", email)
+	}
+
+	return nil
+}
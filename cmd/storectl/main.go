@@ -0,0 +1,357 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-ms-project-store/internal/adapters/input/http/dto"
+	"github.com/go-ms-project-store/internal/adapters/input/http/routes"
+	"github.com/go-ms-project-store/internal/adapters/output/mailer"
+	"github.com/go-ms-project-store/internal/core/enums"
+	"github.com/go-ms-project-store/internal/core/ports"
+	"github.com/go-ms-project-store/internal/core/repositories"
+	"github.com/go-ms-project-store/internal/core/services"
+	"github.com/go-ms-project-store/internal/pkg/config"
+	"github.com/go-ms-project-store/internal/pkg/db"
+	"github.com/go-ms-project-store/internal/pkg/errs"
+	"github.com/go-ms-project-store/internal/pkg/health"
+	"github.com/jmoiron/sqlx"
+	"golang.org/x/term"
+)
+
+const usage = `Usage: storectl <command> [arguments] [configuration flags]
+
+Commands:
+  create-admin <email> <name>   create a user with the admin role
+  reset-password <email>        set a new password and sign the user out everywhere
+  revoke-tokens <email>         sign the user out everywhere, personal tokens included
+  prune-tokens [batch size]     delete expired tokens, TOKEN_CLEANUP_BATCH_SIZE at a time unless given
+  recompute-totals              set the amount of every order to the sum of its items
+  routes                        print the route table
+  check-config                  validate the configuration and print it
+
+Passwords are read from standard input, the password then its confirmation.
+Access tokens issued by the jwt driver stay valid until they expire.
+Configuration is read like the API does, e.g. -db-host or DB_HOST.
+`
+
+// command runs a task with its positional arguments
+type command struct {
+	args int
+	// optional arguments may be left out
+	optional bool
+	run      func(ctx context.Context, app *app, args []string) error
+}
+
+var commands = map[string]command{
+	"create-admin":     {args: 2, run: createAdmin},
+	"reset-password":   {args: 1, run: resetPassword},
+	"revoke-tokens":    {args: 1, run: revokeTokens},
+	"prune-tokens":     {args: 1, optional: true, run: pruneTokens},
+	"recompute-totals": {run: recomputeTotals},
+	"routes":           {run: printRoutes},
+	"check-config":     {run: checkConfig},
+}
+
+// app wires the services the way the API does
+type app struct {
+	cfg      *config.Config
+	dbClient *sqlx.DB
+}
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	args = args[1:]
+
+	var positional []string
+	for len(positional) < cmd.args && len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		positional, args = append(positional, args[0]), args[1:]
+	}
+	if len(positional) < cmd.args && !cmd.optional {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load(args)
+	if err != nil {
+		return fmt.Errorf("invalid configuration:\n%w", err)
+	}
+
+	dbClient := db.GetDBClient(cfg.DB)
+	defer dbClient.Close()
+
+	return cmd.run(context.Background(), &app{cfg: cfg, dbClient: dbClient}, positional)
+}
+
+func (a *app) tokenDriver() (ports.TokenDriver, error) {
+	tokenDriver, err := services.NewTokenDriver(repositories.NewAuthRepositoryDB(a.dbClient), a.cfg)
+	if err != nil {
+		return nil, fmt.Errorf("configuring the token driver: %w", err)
+	}
+
+	return tokenDriver, nil
+}
+
+func (a *app) authService() (services.DefaultAuthService, error) {
+	tokenDriver, err := a.tokenDriver()
+	if err != nil {
+		return services.DefaultAuthService{}, err
+	}
+
+	return services.NewAuthService(
+		repositories.NewAuthRepositoryDB(a.dbClient),
+		tokenDriver,
+		mailer.NewMailer(a.cfg.Mail),
+		services.NewMemoryAttemptStore(),
+		a.cfg,
+	), nil
+}
+
+func createAdmin(ctx context.Context, a *app, args []string) error {
+	password, confirmation, err := readPassword(os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	userRepositoryDB := repositories.NewUserRepositoryDB(a.dbClient)
+
+	role, appErr := userRepositoryDB.RoleRepo().FindByName(ctx, string(enums.AdminRole))
+	if appErr != nil {
+		return fmt.Errorf("finding the admin role: %w", asError(appErr))
+	}
+
+	req := dto.NewUserRequest{
+		Name:                 args[1],
+		Email:                args[0],
+		Password:             password,
+		PasswordConfirmation: confirmation,
+		RoleId:               role.Id,
+	}
+	if validation := req.Validate(); validation != nil {
+		return asError(validation.AsAppError())
+	}
+
+	tokenDriver, err := a.tokenDriver()
+	if err != nil {
+		return err
+	}
+
+	user, appErr := services.NewUserService(userRepositoryDB, services.NewPermissionCache(), tokenDriver).CreateUser(ctx, req)
+	if appErr != nil {
+		return asError(appErr)
+	}
+
+	fmt.Printf("Created admin %s (%s)\n", user.Email, user.UUID)
+
+	return nil
+}
+
+func resetPassword(ctx context.Context, a *app, args []string) error {
+	user, appErr := repositories.NewUserRepositoryDB(a.dbClient).FindByEmail(ctx, args[0])
+	if appErr != nil {
+		return asError(appErr)
+	}
+
+	password, confirmation, err := readPassword(os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	req := dto.SetPasswordRequest{Password: password, PasswordConfirmation: confirmation}
+	if validation := req.Validate(); validation != nil {
+		return asError(validation.AsAppError())
+	}
+
+	authService, err := a.authService()
+	if err != nil {
+		return err
+	}
+
+	if appErr := authService.SetPassword(ctx, uint64(user.Id), req); appErr != nil {
+		return asError(appErr)
+	}
+
+	fmt.Printf("Changed the password of %s and signed them out\n", user.Email)
+
+	return nil
+}
+
+func revokeTokens(ctx context.Context, a *app, args []string) error {
+	user, appErr := repositories.NewUserRepositoryDB(a.dbClient).FindByEmail(ctx, args[0])
+	if appErr != nil {
+		return asError(appErr)
+	}
+
+	authService, err := a.authService()
+	if err != nil {
+		return err
+	}
+
+	if appErr := authService.RevokeAllTokens(ctx, uint64(user.Id)); appErr != nil {
+		return asError(appErr)
+	}
+
+	fmt.Printf("Revoked every token of %s\n", user.Email)
+
+	return nil
+}
+
+func pruneTokens(ctx context.Context, a *app, args []string) error {
+	batchSize := a.cfg.Auth.TokenCleanupBatchSize
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			return fmt.Errorf("batch size must be a positive number")
+		}
+		batchSize = n
+	}
+
+	authService, err := a.authService()
+	if err != nil {
+		return err
+	}
+
+	deleted, appErr := authService.PruneExpiredTokens(ctx, batchSize)
+	if appErr != nil {
+		return fmt.Errorf("deleted %d expired tokens before failing: %w", deleted, asError(appErr))
+	}
+
+	fmt.Printf("Deleted %d expired tokens\n", deleted)
+
+	return nil
+}
+
+func recomputeTotals(ctx context.Context, a *app, args []string) error {
+	orderService := services.NewOrderService(repositories.NewOrderRepositoryDB(a.dbClient))
+
+	updated, appErr := orderService.RecomputeTotals(ctx)
+	if appErr != nil {
+		return asError(appErr)
+	}
+
+	fmt.Printf("Corrected the total of %d orders\n", updated)
+
+	return nil
+}
+
+// printRoutes builds the router like the API does, without serving it
+func printRoutes(ctx context.Context, a *app, args []string) error {
+	mux := routes.Routes(a.cfg, a.dbClient, health.NewRegistry(a.cfg.Server.HealthCheckTimeout))
+
+	type route struct {
+		method  string
+		pattern string
+	}
+
+	var table []route
+	err := chi.Walk(mux, func(method string, pattern string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		table = append(table, route{method: method, pattern: strings.TrimSuffix(pattern, "/*")})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(table, func(i, j int) bool {
+		if table[i].pattern != table[j].pattern {
+			return table[i].pattern < table[j].pattern
+		}
+		return table[i].method < table[j].method
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "METHOD\tPATTERN")
+	for _, r := range table {
+		fmt.Fprintf(w, "%s\t%s\n", r.method, r.pattern)
+	}
+
+	return w.Flush()
+}
+
+// checkConfig only runs once the configuration loaded, which validates it
+func checkConfig(ctx context.Context, a *app, args []string) error {
+	fmt.Print(a.cfg.String())
+	fmt.Println("Configuration is valid")
+
+	return nil
+}
+
+// readPassword prompts for a password and its confirmation. A terminal
+// doesn't echo them, while piped input is read line by line.
+func readPassword(in *os.File) (string, string, error) {
+	read := func() (string, error) {
+		password, err := term.ReadPassword(int(in.Fd()))
+		fmt.Fprintln(os.Stderr)
+		return string(password), err
+	}
+	if !term.IsTerminal(int(in.Fd())) {
+		reader := bufio.NewReader(in)
+		read = func() (string, error) { return readLine(reader) }
+	}
+
+	fmt.Fprint(os.Stderr, "Password: ")
+	password, err := read()
+	if err != nil {
+		return "", "", fmt.Errorf("reading the password: %w", err)
+	}
+
+	fmt.Fprint(os.Stderr, "Confirm password: ")
+	confirmation, err := read()
+	if err != nil {
+		return "", "", fmt.Errorf("reading the password confirmation: %w", err)
+	}
+
+	return password, confirmation, nil
+}
+
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// asError formats an application error with the fields it reports
+func asError(err *errs.AppError) error {
+	fields := make([]string, 0, len(err.Errors))
+	for field := range err.Errors {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var b strings.Builder
+	b.WriteString(err.Message)
+	for _, field := range fields {
+		fmt.Fprintf(&b, "\n  %s: %s", field, strings.Join(err.Errors[field], ", "))
+	}
+
+	return errors.New(b.String())
+}
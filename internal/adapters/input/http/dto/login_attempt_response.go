@@ -0,0 +1,11 @@
+package dto
+
+type LoginAttemptResponse struct {
+	Id         uint64  `json:"id"`
+	UserId     *uint64 `json:"user_id"`
+	Email      string  `json:"email"`
+	IpAddress  string  `json:"ip_address"`
+	UserAgent  string  `json:"user_agent"`
+	Successful bool    `json:"successful"`
+	CreatedAt  string  `json:"created_at"`
+}
@@ -9,8 +9,11 @@ type LoginValidator interface {
 }
 
 type NewLoginRequest struct {
-	Email    string `json:"email" validate:"required,email,min=3,max=250"`
-	Password string `json:"password" validate:"required,min=3,max=250"`
+	Email      string `json:"email" validate:"required,email,min=3,max=250"`
+	Password   string `json:"password" validate:"required,min=3,max=250"`
+	DeviceName string `json:"device_name" validate:"omitempty,max=250"`
+	UserAgent  string `json:"-"`
+	IpAddress  string `json:"-"`
 }
 
 func (req *NewLoginRequest) Validate() *helpers.ValidationResponse {
@@ -0,0 +1,54 @@
+package dto
+
+import (
+	"github.com/go-ms-project-store/internal/pkg/helpers"
+)
+
+type MeValidator interface {
+	Validate() *helpers.ValidationResponse
+}
+
+type UpdateMeRequest struct {
+	Name  string `json:"name" validate:"omitempty,min=3,max=250"`
+	Email string `json:"email" validate:"omitempty,email,max=250"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword      string `json:"current_password" validate:"required,max=250"`
+	Password             string `json:"password" validate:"required,min=8,max=250"`
+	PasswordConfirmation string `json:"password_confirmation" validate:"required,min=8,max=250"`
+}
+
+type DeleteMeRequest struct {
+	Password string `json:"password" validate:"required,max=250"`
+}
+
+func (req *UpdateMeRequest) Validate() *helpers.ValidationResponse {
+	return helpers.ValidateRequests(req)
+}
+
+func (req *ChangePasswordRequest) Validate() *helpers.ValidationResponse {
+	if err := helpers.ValidateRequests(req); err != nil {
+		return err
+	}
+
+	if req.Password != req.PasswordConfirmation {
+		msg := make(map[string][]string)
+		msg["password_confirmation"] = append(msg["password_confirmation"], "Password confirmation must match password")
+		return &helpers.ValidationResponse{
+			Message: "Password confirmation does not match",
+			Errors:  msg,
+		}
+	}
+
+	return nil
+}
+
+func (req *DeleteMeRequest) Validate() *helpers.ValidationResponse {
+	return helpers.ValidateRequests(req)
+}
+
+// ValidateMe is a generic function that can handle any MeValidator
+func ValidateMe(me MeValidator) *helpers.ValidationResponse {
+	return me.Validate()
+}
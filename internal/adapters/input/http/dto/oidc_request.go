@@ -0,0 +1,27 @@
+package dto
+
+import (
+	"github.com/go-ms-project-store/internal/pkg/helpers"
+)
+
+type OIDCValidator interface {
+	Validate() *helpers.ValidationResponse
+}
+
+type OIDCCallbackRequest struct {
+	Code       string `json:"code" validate:"required,max=2000"`
+	State      string `json:"state" validate:"required,max=2000"`
+	DeviceName string `json:"device_name" validate:"omitempty,max=250"`
+	Binding    string `json:"-"`
+	UserAgent  string `json:"-"`
+	IpAddress  string `json:"-"`
+}
+
+func (req *OIDCCallbackRequest) Validate() *helpers.ValidationResponse {
+	return helpers.ValidateRequests(req)
+}
+
+// ValidateOIDC is a generic function that can handle any OIDCValidator
+func ValidateOIDC(req OIDCValidator) *helpers.ValidationResponse {
+	return req.Validate()
+}
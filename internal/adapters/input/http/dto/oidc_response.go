@@ -0,0 +1,10 @@
+package dto
+
+// OIDCAuthorizationResponse tells the client where to send the user to sign
+// in. The state must be sent back with the code the provider returns.
+// The binding is kept in a cookie and never sent in the body.
+type OIDCAuthorizationResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+	State            string `json:"state"`
+	Binding          string `json:"-"`
+}
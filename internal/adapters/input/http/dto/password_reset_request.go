@@ -0,0 +1,69 @@
+package dto
+
+import (
+	"github.com/go-ms-project-store/internal/pkg/helpers"
+)
+
+type PasswordResetValidator interface {
+	Validate() *helpers.ValidationResponse
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email,max=250"`
+}
+
+type ResetPasswordRequest struct {
+	Token                string `json:"token" validate:"required,max=250"`
+	Password             string `json:"password" validate:"required,min=8,max=250"`
+	PasswordConfirmation string `json:"password_confirmation" validate:"required,min=8,max=250"`
+}
+
+// SetPasswordRequest replaces a password without a token or the current
+// password, for operators
+type SetPasswordRequest struct {
+	Password             string `json:"password" validate:"required,min=8,max=250"`
+	PasswordConfirmation string `json:"password_confirmation" validate:"required,min=8,max=250"`
+}
+
+func (req *ForgotPasswordRequest) Validate() *helpers.ValidationResponse {
+	return helpers.ValidateRequests(req)
+}
+
+func (req *ResetPasswordRequest) Validate() *helpers.ValidationResponse {
+	if err := helpers.ValidateRequests(req); err != nil {
+		return err
+	}
+
+	if req.Password != req.PasswordConfirmation {
+		msg := make(map[string][]string)
+		msg["password_confirmation"] = append(msg["password_confirmation"], "Password confirmation must match password")
+		return &helpers.ValidationResponse{
+			Message: "Password confirmation does not match",
+			Errors:  msg,
+		}
+	}
+
+	return nil
+}
+
+func (req *SetPasswordRequest) Validate() *helpers.ValidationResponse {
+	if err := helpers.ValidateRequests(req); err != nil {
+		return err
+	}
+
+	if req.Password != req.PasswordConfirmation {
+		msg := make(map[string][]string)
+		msg["password_confirmation"] = append(msg["password_confirmation"], "Password confirmation must match password")
+		return &helpers.ValidationResponse{
+			Message: "Password confirmation does not match",
+			Errors:  msg,
+		}
+	}
+
+	return nil
+}
+
+// ValidatePasswordReset is a generic function that can handle any PasswordResetValidator
+func ValidatePasswordReset(req PasswordResetValidator) *helpers.ValidationResponse {
+	return req.Validate()
+}
@@ -0,0 +1,30 @@
+package dto
+
+import (
+	"github.com/go-ms-project-store/internal/pkg/helpers"
+)
+
+type PermissionValidator interface {
+	Validate() *helpers.ValidationResponse
+}
+
+type NewPermissionRequest struct {
+	Name string `json:"name" validate:"required,lowercase,min=3,max=250"`
+}
+
+type UpdatePermissionRequest struct {
+	Name string `json:"name" validate:"required,lowercase,min=3,max=250"`
+}
+
+func (req *NewPermissionRequest) Validate() *helpers.ValidationResponse {
+	return helpers.ValidateRequests(req)
+}
+
+func (req *UpdatePermissionRequest) Validate() *helpers.ValidationResponse {
+	return helpers.ValidateRequests(req)
+}
+
+// ValidatePermission is a generic function that can handle any PermissionValidator
+func ValidatePermission(permission PermissionValidator) *helpers.ValidationResponse {
+	return permission.Validate()
+}
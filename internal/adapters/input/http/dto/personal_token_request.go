@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/go-ms-project-store/internal/pkg/helpers"
+)
+
+type PersonalTokenValidator interface {
+	Validate() *helpers.ValidationResponse
+}
+
+type NewPersonalTokenRequest struct {
+	Name      string     `json:"name" validate:"required,min=3,max=250"`
+	Abilities []string   `json:"abilities" validate:"required,min=1,dive,required,max=100"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func (req *NewPersonalTokenRequest) Validate() *helpers.ValidationResponse {
+	if err := helpers.ValidateRequests(req); err != nil {
+		return err
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		msg := make(map[string][]string)
+		msg["expires_at"] = append(msg["expires_at"], "The expiry must be a date in the future")
+		return &helpers.ValidationResponse{
+			Message: "The expiry must be a date in the future",
+			Errors:  msg,
+		}
+	}
+
+	return nil
+}
+
+// ValidatePersonalToken is a generic function that can handle any PersonalTokenValidator
+func ValidatePersonalToken(token PersonalTokenValidator) *helpers.ValidationResponse {
+	return token.Validate()
+}
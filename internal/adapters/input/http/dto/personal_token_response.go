@@ -0,0 +1,17 @@
+package dto
+
+type PersonalTokenResponse struct {
+	Id         uint64   `json:"id"`
+	Name       string   `json:"name"`
+	Abilities  []string `json:"abilities"`
+	LastUsedAt string   `json:"last_used_at,omitempty"`
+	ExpiresAt  string   `json:"expires_at,omitempty"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+// NewPersonalTokenResponse carries the plaintext token, which is only ever
+// shown when the token is created
+type NewPersonalTokenResponse struct {
+	PersonalTokenResponse
+	Token string `json:"token"`
+}
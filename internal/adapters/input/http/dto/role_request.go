@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"github.com/go-ms-project-store/internal/pkg/helpers"
+)
+
+type RoleValidator interface {
+	Validate() *helpers.ValidationResponse
+}
+
+type NewRoleRequest struct {
+	Name              string `json:"name" validate:"required,lowercase,min=3,max=250"`
+	RequiresTwoFactor bool   `json:"requires_two_factor"`
+}
+
+type UpdateRoleRequest struct {
+	Name              string `json:"name" validate:"required,lowercase,min=3,max=250"`
+	RequiresTwoFactor bool   `json:"requires_two_factor"`
+}
+
+type RolePermissionsRequest struct {
+	Permissions []int64 `json:"permissions" validate:"required,min=1,dive,gt=0"`
+}
+
+func (req *NewRoleRequest) Validate() *helpers.ValidationResponse {
+	return helpers.ValidateRequests(req)
+}
+
+func (req *UpdateRoleRequest) Validate() *helpers.ValidationResponse {
+	return helpers.ValidateRequests(req)
+}
+
+func (req *RolePermissionsRequest) Validate() *helpers.ValidationResponse {
+	return helpers.ValidateRequests(req)
+}
+
+// ValidateRole is a generic function that can handle any RoleValidator
+func ValidateRole(role RoleValidator) *helpers.ValidationResponse {
+	return role.Validate()
+}
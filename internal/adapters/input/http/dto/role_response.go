@@ -1,9 +1,10 @@
 package dto
 
 type RoleResponse struct {
-	Id          int64                `json:"id"`
-	Name        string               `json:"name"`
-	CreatedAt   string               `json:"created_at,omitempty"`
-	UpdatedAt   string               `json:"updated_at,omitempty"`
-	Permissions []PermissionResponse `json:"permissions,omitempty"`
+	Id                int64                `json:"id"`
+	Name              string               `json:"name"`
+	RequiresTwoFactor bool                 `json:"requires_two_factor"`
+	CreatedAt         string               `json:"created_at,omitempty"`
+	UpdatedAt         string               `json:"updated_at,omitempty"`
+	Permissions       []PermissionResponse `json:"permissions,omitempty"`
 }
@@ -0,0 +1,11 @@
+package dto
+
+type SessionResponse struct {
+	Id         uint64 `json:"id"`
+	DeviceName string `json:"device_name"`
+	UserAgent  string `json:"user_agent"`
+	IpAddress  string `json:"ip_address"`
+	LastUsedAt string `json:"last_used_at,omitempty"`
+	CreatedAt  string `json:"created_at"`
+	Current    bool   `json:"current"`
+}
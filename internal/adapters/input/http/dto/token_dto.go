@@ -4,6 +4,8 @@ import "time"
 
 type NewTokenDTO struct {
 	UserID    uint64
+	SessionID uint64
+	ParentID  uint64
 	Name      string
 	ExpiresAt time.Time
 	Abilities []string
@@ -1,8 +1,12 @@
 package dto
 
 type TokenResponse struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token,omitempty"`
-	ExpiresIn    int    `json:"expires_in"`
-	TokenType    string `json:"token_type"`
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token,omitempty"`
+	ExpiresIn        int    `json:"expires_in"`
+	RefreshExpiresIn int    `json:"refresh_expires_in,omitempty"`
+	TokenType        string `json:"token_type"`
+	// TwoFactorRequired marks a challenge token that must be exchanged at
+	// /auth/2fa/verify for the access and refresh tokens
+	TwoFactorRequired bool `json:"two_factor_required,omitempty"`
 }
@@ -0,0 +1,43 @@
+package dto
+
+import (
+	"github.com/go-ms-project-store/internal/pkg/helpers"
+)
+
+type TwoFactorValidator interface {
+	Validate() *helpers.ValidationResponse
+}
+
+type ConfirmTwoFactorRequest struct {
+	Code string `json:"code" validate:"required,numeric,len=6"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" validate:"required,max=250"`
+	Code     string `json:"code" validate:"required,numeric,len=6"`
+}
+
+type VerifyTwoFactorRequest struct {
+	Code         string `json:"code" validate:"required_without=RecoveryCode,omitempty,numeric,len=6"`
+	RecoveryCode string `json:"recovery_code" validate:"required_without=Code,omitempty,max=50"`
+	DeviceName   string `json:"device_name" validate:"omitempty,max=250"`
+	UserAgent    string `json:"-"`
+	IpAddress    string `json:"-"`
+}
+
+func (req *ConfirmTwoFactorRequest) Validate() *helpers.ValidationResponse {
+	return helpers.ValidateRequests(req)
+}
+
+func (req *DisableTwoFactorRequest) Validate() *helpers.ValidationResponse {
+	return helpers.ValidateRequests(req)
+}
+
+func (req *VerifyTwoFactorRequest) Validate() *helpers.ValidationResponse {
+	return helpers.ValidateRequests(req)
+}
+
+// ValidateTwoFactor is a generic function that can handle any TwoFactorValidator
+func ValidateTwoFactor(req TwoFactorValidator) *helpers.ValidationResponse {
+	return req.Validate()
+}
@@ -5,8 +5,9 @@ import (
 )
 
 type UserMeResponse struct {
-	ID        uuid.UUID `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	CreatedAt string    `json:"created_at"`
+	ID              uuid.UUID `json:"id"`
+	Name            string    `json:"name"`
+	Email           string    `json:"email"`
+	EmailVerifiedAt *string   `json:"email_verified_at,omitempty"`
+	CreatedAt       string    `json:"created_at"`
 }
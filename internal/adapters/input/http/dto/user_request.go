@@ -0,0 +1,57 @@
+package dto
+
+import (
+	"github.com/go-ms-project-store/internal/pkg/helpers"
+)
+
+type UserValidator interface {
+	Validate() *helpers.ValidationResponse
+}
+
+type NewUserRequest struct {
+	Name                 string `json:"name" validate:"required,min=3,max=250"`
+	Email                string `json:"email" validate:"required,email,max=250"`
+	Password             string `json:"password" validate:"required,min=8,max=250"`
+	PasswordConfirmation string `json:"password_confirmation" validate:"required,min=8,max=250"`
+	RoleId               int64  `json:"role_id" validate:"required,gt=0"`
+}
+
+type UpdateUserRequest struct {
+	Name   string `json:"name" validate:"required,min=3,max=250"`
+	Email  string `json:"email" validate:"required,email,max=250"`
+	RoleId int64  `json:"role_id" validate:"required,gt=0"`
+}
+
+type UpdateUserRoleRequest struct {
+	RoleId int64 `json:"role_id" validate:"required,gt=0"`
+}
+
+func (req *NewUserRequest) Validate() *helpers.ValidationResponse {
+	if err := helpers.ValidateRequests(req); err != nil {
+		return err
+	}
+
+	if req.Password != req.PasswordConfirmation {
+		msg := make(map[string][]string)
+		msg["password_confirmation"] = append(msg["password_confirmation"], "Password confirmation must match password")
+		return &helpers.ValidationResponse{
+			Message: "Password confirmation does not match",
+			Errors:  msg,
+		}
+	}
+
+	return nil
+}
+
+func (req *UpdateUserRequest) Validate() *helpers.ValidationResponse {
+	return helpers.ValidateRequests(req)
+}
+
+func (req *UpdateUserRoleRequest) Validate() *helpers.ValidationResponse {
+	return helpers.ValidateRequests(req)
+}
+
+// ValidateUser is a generic function that can handle any UserValidator
+func ValidateUser(user UserValidator) *helpers.ValidationResponse {
+	return user.Validate()
+}
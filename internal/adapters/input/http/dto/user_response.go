@@ -10,7 +10,8 @@ type UserResponse struct {
 	Name            string       `json:"name"`
 	Email           string       `json:"email"`
 	RoleId          int64        `json:"role_id"`
-	EmailVerifiedAt string       `json:"email_verified_at"`
+	EmailVerifiedAt *string      `json:"email_verified_at"`
+	LockedAt        string       `json:"locked_at,omitempty"`
 	CreatedAt       string       `json:"created_at"`
 	UpdatedAt       string       `json:"updated_at"`
 	Role            RoleResponse `json:"role,omitempty"`
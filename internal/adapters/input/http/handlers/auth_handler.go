@@ -3,10 +3,13 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/go-ms-project-store/internal/adapters/input/http/dto"
 	"github.com/go-ms-project-store/internal/adapters/input/http/middlewares"
 	"github.com/go-ms-project-store/internal/core/ports"
+	"github.com/go-ms-project-store/internal/pkg/errs"
 	"github.com/go-ms-project-store/internal/pkg/helpers"
 )
 
@@ -14,23 +17,49 @@ type AuthHandlers struct {
 	Service ports.AuthService
 }
 
+func (ah *AuthHandlers) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var forgotRequest dto.ForgotPasswordRequest
+
+	err := json.NewDecoder(r.Body).Decode(&forgotRequest)
+	if err != nil {
+		helpers.WriteError(w, r, errs.NewBadRequestError(err.Error()))
+		return
+	}
+
+	if err := dto.ValidatePasswordReset(&forgotRequest); err != nil {
+		helpers.WriteError(w, r, err.AsAppError())
+		return
+	}
+
+	// The outcome is deliberately not reported so accounts can't be enumerated
+	ah.Service.ForgotPassword(r.Context(), forgotRequest)
+
+	msg := map[string]string{
+		"message": "If the email address is registered, a password reset link has been sent",
+	}
+	helpers.WriteResponse(w, http.StatusOK, msg)
+}
+
 func (ah *AuthHandlers) Login(w http.ResponseWriter, r *http.Request) {
 	var loginRequest dto.NewLoginRequest
 
 	err := json.NewDecoder(r.Body).Decode(&loginRequest)
 	if err != nil {
-		helpers.WriteResponse(w, http.StatusBadRequest, err.Error())
+		helpers.WriteError(w, r, errs.NewBadRequestError(err.Error()))
 		return
 	}
 
 	if err := dto.ValidateLogin(&loginRequest); err != nil {
-		helpers.WriteResponse(w, http.StatusUnprocessableEntity, err)
+		helpers.WriteError(w, r, err.AsAppError())
 		return
 	}
 
-	tokenRes, errT := ah.Service.Login(loginRequest)
+	loginRequest.UserAgent = r.UserAgent()
+	loginRequest.IpAddress = helpers.GetClientIP(r)
+
+	tokenRes, errT := ah.Service.Login(r.Context(), loginRequest)
 	if errT != nil {
-		helpers.WriteResponse(w, errT.Code, errT.AsMessage())
+		helpers.WriteError(w, r, errT)
 	} else {
 		helpers.WriteResponse(w, http.StatusOK, tokenRes)
 	}
@@ -39,13 +68,15 @@ func (ah *AuthHandlers) Login(w http.ResponseWriter, r *http.Request) {
 func (ch *AuthHandlers) Logout(w http.ResponseWriter, r *http.Request) {
 	user_id, ok := middlewares.GetUserID(r.Context())
 	if !ok {
-		helpers.WriteResponse(w, http.StatusUnauthorized, "Unauthorized")
+		helpers.WriteError(w, r, errs.NewUnauthorizedError("Unauthorized"))
 		return
 	}
 
-	err := ch.Service.Logout(user_id)
+	session_id, _ := middlewares.GetSessionID(r.Context())
+
+	err := ch.Service.Logout(r.Context(), user_id, session_id)
 	if err != nil {
-		helpers.WriteResponse(w, err.Code, err.AsMessage())
+		helpers.WriteError(w, r, err)
 	} else {
 		msg := map[string]string{
 			"message": "Successfully logged out",
@@ -57,55 +88,365 @@ func (ch *AuthHandlers) Logout(w http.ResponseWriter, r *http.Request) {
 func (ch *AuthHandlers) Me(w http.ResponseWriter, r *http.Request) {
 	user_id, ok := middlewares.GetUserID(r.Context())
 	if !ok {
-		helpers.WriteResponse(w, http.StatusUnauthorized, "Unauthorized")
+		helpers.WriteError(w, r, errs.NewUnauthorizedError("Unauthorized"))
 		return
 	}
 
-	user, err := ch.Service.Me(user_id)
+	user, err := ch.Service.Me(r.Context(), user_id)
 	if err != nil {
-		helpers.WriteResponse(w, err.Code, err.AsMessage())
+		helpers.WriteError(w, r, err)
 	} else {
 		helpers.WriteResponse(w, http.StatusOK, user.ToMeDTO())
 	}
 }
 
+func (ch *AuthHandlers) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	user_id, ok := middlewares.GetUserID(r.Context())
+	if !ok {
+		helpers.WriteError(w, r, errs.NewUnauthorizedError("Unauthorized"))
+		return
+	}
+
+	var meRequest dto.UpdateMeRequest
+
+	err := json.NewDecoder(r.Body).Decode(&meRequest)
+	if err != nil {
+		helpers.WriteError(w, r, errs.NewBadRequestError(err.Error()))
+		return
+	}
+
+	if err := dto.ValidateMe(&meRequest); err != nil {
+		helpers.WriteError(w, r, err.AsAppError())
+		return
+	}
+
+	user, errMe := ch.Service.UpdateMe(r.Context(), user_id, meRequest)
+	if errMe != nil {
+		helpers.WriteError(w, r, errMe)
+	} else {
+		helpers.WriteResponse(w, http.StatusOK, user.ToMeDTO())
+	}
+}
+
+func (ch *AuthHandlers) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	user_id, ok := middlewares.GetUserID(r.Context())
+	if !ok {
+		helpers.WriteError(w, r, errs.NewUnauthorizedError("Unauthorized"))
+		return
+	}
+	session_id, _ := middlewares.GetSessionID(r.Context())
+
+	var passwordRequest dto.ChangePasswordRequest
+
+	err := json.NewDecoder(r.Body).Decode(&passwordRequest)
+	if err != nil {
+		helpers.WriteError(w, r, errs.NewBadRequestError(err.Error()))
+		return
+	}
+
+	if err := dto.ValidateMe(&passwordRequest); err != nil {
+		helpers.WriteError(w, r, err.AsAppError())
+		return
+	}
+
+	errMe := ch.Service.ChangePassword(r.Context(), user_id, session_id, passwordRequest)
+	if errMe != nil {
+		helpers.WriteError(w, r, errMe)
+	} else {
+		msg := map[string]string{
+			"message": "Password successfully changed",
+		}
+		helpers.WriteResponse(w, http.StatusOK, msg)
+	}
+}
+
+func (ch *AuthHandlers) DeleteMe(w http.ResponseWriter, r *http.Request) {
+	user_id, ok := middlewares.GetUserID(r.Context())
+	if !ok {
+		helpers.WriteError(w, r, errs.NewUnauthorizedError("Unauthorized"))
+		return
+	}
+
+	var deleteRequest dto.DeleteMeRequest
+
+	err := json.NewDecoder(r.Body).Decode(&deleteRequest)
+	if err != nil {
+		helpers.WriteError(w, r, errs.NewBadRequestError(err.Error()))
+		return
+	}
+
+	if err := dto.ValidateMe(&deleteRequest); err != nil {
+		helpers.WriteError(w, r, err.AsAppError())
+		return
+	}
+
+	errMe := ch.Service.DeleteMe(r.Context(), user_id, deleteRequest)
+	if errMe != nil {
+		helpers.WriteError(w, r, errMe)
+	} else {
+		helpers.WriteResponse(w, http.StatusNoContent, "")
+	}
+}
+
 func (ch *AuthHandlers) Refresh(w http.ResponseWriter, r *http.Request) {
 	user_id, ok := middlewares.GetUserID(r.Context())
 	if !ok {
-		helpers.WriteResponse(w, http.StatusUnauthorized, "Unauthorized")
+		helpers.WriteError(w, r, errs.NewUnauthorizedError("Unauthorized"))
 		return
 	}
 
-	res, err := ch.Service.RefreshToken(uint64(user_id))
+	session_id, _ := middlewares.GetSessionID(r.Context())
+	token_id, _ := middlewares.GetTokenID(r.Context())
+
+	res, err := ch.Service.RefreshToken(r.Context(), uint64(user_id), session_id, token_id)
 	if err != nil {
-		helpers.WriteResponse(w, err.Code, err.AsMessage())
+		helpers.WriteError(w, r, err)
 	} else {
 		helpers.WriteResponse(w, http.StatusOK, res)
 	}
 }
 
+func (ch *AuthHandlers) ListSessions(w http.ResponseWriter, r *http.Request) {
+	user_id, ok := middlewares.GetUserID(r.Context())
+	if !ok {
+		helpers.WriteError(w, r, errs.NewUnauthorizedError("Unauthorized"))
+		return
+	}
+	session_id, _ := middlewares.GetSessionID(r.Context())
+
+	sessions, err := ch.Service.GetSessions(r.Context(), user_id)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+	} else {
+		helpers.WriteResponse(w, http.StatusOK, sessions.ToDTO(session_id))
+	}
+}
+
+func (ch *AuthHandlers) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	user_id, ok := middlewares.GetUserID(r.Context())
+	if !ok {
+		helpers.WriteError(w, r, errs.NewUnauthorizedError("Unauthorized"))
+		return
+	}
+
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.WriteError(w, r, errs.NewBadRequestError("Invalid ID format"))
+		return
+	}
+
+	appErr := ch.Service.RevokeSession(r.Context(), user_id, id)
+	if appErr != nil {
+		helpers.WriteError(w, r, appErr)
+	} else {
+		helpers.WriteResponse(w, http.StatusNoContent, "")
+	}
+}
+
+func (ch *AuthHandlers) LogoutOtherSessions(w http.ResponseWriter, r *http.Request) {
+	user_id, ok := middlewares.GetUserID(r.Context())
+	if !ok {
+		helpers.WriteError(w, r, errs.NewUnauthorizedError("Unauthorized"))
+		return
+	}
+	session_id, _ := middlewares.GetSessionID(r.Context())
+
+	err := ch.Service.RevokeOtherSessions(r.Context(), user_id, session_id)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+	} else {
+		msg := map[string]string{
+			"message": "Successfully logged out of other sessions",
+		}
+		helpers.WriteResponse(w, http.StatusOK, msg)
+	}
+}
+
+func (ch *AuthHandlers) CreatePersonalToken(w http.ResponseWriter, r *http.Request) {
+	user_id, ok := middlewares.GetUserID(r.Context())
+	if !ok {
+		helpers.WriteError(w, r, errs.NewUnauthorizedError("Unauthorized"))
+		return
+	}
+
+	var tokenRequest dto.NewPersonalTokenRequest
+
+	err := json.NewDecoder(r.Body).Decode(&tokenRequest)
+	if err != nil {
+		helpers.WriteError(w, r, errs.NewBadRequestError(err.Error()))
+		return
+	}
+
+	if err := dto.ValidatePersonalToken(&tokenRequest); err != nil {
+		helpers.WriteError(w, r, err.AsAppError())
+		return
+	}
+
+	token, appErr := ch.Service.CreatePersonalToken(r.Context(), user_id, tokenRequest)
+	if appErr != nil {
+		helpers.WriteError(w, r, appErr)
+	} else {
+		helpers.WriteResponse(w, http.StatusCreated, token.ToNewPersonalTokenDTO())
+	}
+}
+
+func (ch *AuthHandlers) ListPersonalTokens(w http.ResponseWriter, r *http.Request) {
+	user_id, ok := middlewares.GetUserID(r.Context())
+	if !ok {
+		helpers.WriteError(w, r, errs.NewUnauthorizedError("Unauthorized"))
+		return
+	}
+
+	tokens, err := ch.Service.GetPersonalTokens(r.Context(), user_id)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+	} else {
+		helpers.WriteResponse(w, http.StatusOK, tokens.ToDTO())
+	}
+}
+
+func (ch *AuthHandlers) RevokePersonalToken(w http.ResponseWriter, r *http.Request) {
+	user_id, ok := middlewares.GetUserID(r.Context())
+	if !ok {
+		helpers.WriteError(w, r, errs.NewUnauthorizedError("Unauthorized"))
+		return
+	}
+
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.WriteError(w, r, errs.NewBadRequestError("Invalid ID format"))
+		return
+	}
+
+	appErr := ch.Service.RevokePersonalToken(r.Context(), user_id, id)
+	if appErr != nil {
+		helpers.WriteError(w, r, appErr)
+	} else {
+		helpers.WriteResponse(w, http.StatusNoContent, "")
+	}
+}
+
 func (ah *AuthHandlers) Register(w http.ResponseWriter, r *http.Request) {
 	var nUserRequest dto.NewUserRegisterRequest
 
 	err := json.NewDecoder(r.Body).Decode(&nUserRequest)
 	if err != nil {
-		helpers.WriteResponse(w, http.StatusBadRequest, err.Error())
+		helpers.WriteError(w, r, errs.NewBadRequestError(err.Error()))
 		return
 	}
 
 	if err := dto.ValidateUserRegister(&nUserRequest); err != nil {
-		helpers.WriteResponse(w, http.StatusUnprocessableEntity, err)
+		helpers.WriteError(w, r, err.AsAppError())
 		return
 	}
 
-	user, appErr := ah.Service.Register(nUserRequest)
+	user, appErr := ah.Service.Register(r.Context(), nUserRequest)
 	if appErr != nil {
-		helpers.WriteResponse(w, appErr.Code, appErr.AsMessage())
+		helpers.WriteError(w, r, appErr)
 	} else {
 		helpers.WriteResponse(w, http.StatusOK, user.ToMeDTO())
 	}
 }
 
+func (ah *AuthHandlers) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var resetRequest dto.ResetPasswordRequest
+
+	err := json.NewDecoder(r.Body).Decode(&resetRequest)
+	if err != nil {
+		helpers.WriteError(w, r, errs.NewBadRequestError(err.Error()))
+		return
+	}
+
+	if err := dto.ValidatePasswordReset(&resetRequest); err != nil {
+		helpers.WriteError(w, r, err.AsAppError())
+		return
+	}
+
+	errReset := ah.Service.ResetPassword(r.Context(), resetRequest)
+	if errReset != nil {
+		helpers.WriteError(w, r, errReset)
+	} else {
+		msg := map[string]string{
+			"message": "Password successfully reset",
+		}
+		helpers.WriteResponse(w, http.StatusOK, msg)
+	}
+}
+
+func (ah *AuthHandlers) ResendVerificationEmail(w http.ResponseWriter, r *http.Request) {
+	user_id, ok := middlewares.GetUserID(r.Context())
+	if !ok {
+		helpers.WriteError(w, r, errs.NewUnauthorizedError("Unauthorized"))
+		return
+	}
+
+	err := ah.Service.ResendVerificationEmail(r.Context(), user_id)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+	} else {
+		msg := map[string]string{
+			"message": "Verification email sent",
+		}
+		helpers.WriteResponse(w, http.StatusAccepted, msg)
+	}
+}
+
+func (ah *AuthHandlers) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		helpers.WriteError(w, r, errs.NewValidationError("token", "The token field is required."))
+		return
+	}
+
+	err := ah.Service.VerifyEmail(r.Context(), token)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+	} else {
+		msg := map[string]string{
+			"message": "Email successfully verified",
+		}
+		helpers.WriteResponse(w, http.StatusOK, msg)
+	}
+}
+
+// VerifyTwoFactor completes a login that returned a two-factor challenge
+func (ah *AuthHandlers) VerifyTwoFactor(w http.ResponseWriter, r *http.Request) {
+	user_id, ok := middlewares.GetUserID(r.Context())
+	if !ok {
+		helpers.WriteError(w, r, errs.NewUnauthorizedError("Unauthorized"))
+		return
+	}
+
+	token_id, ok := middlewares.GetTokenID(r.Context())
+	if !ok {
+		helpers.WriteError(w, r, errs.NewUnauthorizedError("Unauthorized"))
+		return
+	}
+
+	var verifyRequest dto.VerifyTwoFactorRequest
+
+	err := json.NewDecoder(r.Body).Decode(&verifyRequest)
+	if err != nil {
+		helpers.WriteError(w, r, errs.NewBadRequestError(err.Error()))
+		return
+	}
+
+	if err := dto.ValidateTwoFactor(&verifyRequest); err != nil {
+		helpers.WriteError(w, r, err.AsAppError())
+		return
+	}
+
+	verifyRequest.UserAgent = r.UserAgent()
+	verifyRequest.IpAddress = helpers.GetClientIP(r)
+
+	tokenRes, appErr := ah.Service.VerifyTwoFactor(r.Context(), user_id, token_id, verifyRequest)
+	if appErr != nil {
+		helpers.WriteError(w, r, appErr)
+	} else {
+		helpers.WriteResponse(w, http.StatusOK, tokenRes)
+	}
+}
+
 func NewAuthHandlers(service ports.AuthService) *AuthHandlers {
 	return &AuthHandlers{
 		Service: service,
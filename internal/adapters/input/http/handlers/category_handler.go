@@ -8,6 +8,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-ms-project-store/internal/adapters/input/http/dto"
 	"github.com/go-ms-project-store/internal/core/ports"
+	"github.com/go-ms-project-store/internal/pkg/errs"
 	"github.com/go-ms-project-store/internal/pkg/helpers"
 	"github.com/go-ms-project-store/internal/pkg/pagination"
 )
@@ -19,9 +20,9 @@ type CategoryHandlers struct {
 func (ch *CategoryHandlers) DeleteCategory(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
 
-	_, err := ch.Service.DeleteCategory(id)
+	_, err := ch.Service.DeleteCategory(r.Context(), id)
 	if err != nil {
-		helpers.WriteResponse(w, err.Code, err.AsMessage())
+		helpers.WriteError(w, r, err)
 	} else {
 		helpers.WriteResponse(w, http.StatusNoContent, "")
 	}
@@ -32,18 +33,18 @@ func (ch *CategoryHandlers) CreateCategory(w http.ResponseWriter, r *http.Reques
 
 	err := json.NewDecoder(r.Body).Decode(&categoryRequest)
 	if err != nil {
-		helpers.WriteResponse(w, http.StatusBadRequest, err.Error())
+		helpers.WriteError(w, r, errs.NewBadRequestError(err.Error()))
 		return
 	}
 
 	if err := dto.ValidateCategory(&categoryRequest); err != nil {
-		helpers.WriteResponse(w, http.StatusUnprocessableEntity, err)
+		helpers.WriteError(w, r, err.AsAppError())
 		return
 	}
 
-	category, errCat := ch.Service.CreateCategory(categoryRequest)
+	category, errCat := ch.Service.CreateCategory(r.Context(), categoryRequest)
 	if errCat != nil {
-		helpers.WriteResponse(w, errCat.Code, errCat)
+		helpers.WriteError(w, r, errCat)
 	} else {
 		helpers.WriteResponse(w, http.StatusCreated, category.ToCategoryDTO())
 	}
@@ -56,7 +57,7 @@ func (ch *CategoryHandlers) GetAllCategories(w http.ResponseWriter, r *http.Requ
 
 	paginatedResponse := pagination.NewPaginatedResponse(categories.ToDTO(), filter.Page, filter.PerPage, int(totalRows), baseURL)
 	if err != nil {
-		helpers.WriteResponse(w, err.Code, err.AsMessage())
+		helpers.WriteError(w, r, err)
 	} else {
 		helpers.WriteResponse(w, http.StatusOK, paginatedResponse)
 	}
@@ -65,9 +66,9 @@ func (ch *CategoryHandlers) GetAllCategories(w http.ResponseWriter, r *http.Requ
 func (ch *CategoryHandlers) GetCategory(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
 
-	category, err := ch.Service.FindCategoryById(id)
+	category, err := ch.Service.FindCategoryById(r.Context(), id)
 	if err != nil {
-		helpers.WriteResponse(w, err.Code, err.AsMessage())
+		helpers.WriteError(w, r, err)
 	} else {
 		helpers.WriteResponse(w, http.StatusOK, category.ToCategoryDTO())
 	}
@@ -77,24 +78,24 @@ func (ch *CategoryHandlers) UpdateCategory(w http.ResponseWriter, r *http.Reques
 	var categoryRequest dto.UpdateCategoryRequest
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		helpers.WriteResponse(w, http.StatusBadRequest, "Invalid ID format")
+		helpers.WriteError(w, r, errs.NewBadRequestError("Invalid ID format"))
 		return
 	}
 
 	err = json.NewDecoder(r.Body).Decode(&categoryRequest)
 	if err != nil {
-		helpers.WriteResponse(w, http.StatusBadRequest, err.Error())
+		helpers.WriteError(w, r, errs.NewBadRequestError(err.Error()))
 		return
 	}
 
 	if err := dto.ValidateCategory(&categoryRequest); err != nil {
-		helpers.WriteResponse(w, http.StatusUnprocessableEntity, err)
+		helpers.WriteError(w, r, err.AsAppError())
 		return
 	}
 
-	category, errCat := ch.Service.UpdateCategory(id, categoryRequest)
+	category, errCat := ch.Service.UpdateCategory(r.Context(), id, categoryRequest)
 	if errCat != nil {
-		helpers.WriteResponse(w, errCat.Code, errCat)
+		helpers.WriteError(w, r, errCat)
 	} else {
 		helpers.WriteResponse(w, http.StatusOK, category.ToCategoryDTO())
 	}
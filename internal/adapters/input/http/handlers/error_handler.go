@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-ms-project-store/internal/pkg/errs"
+	"github.com/go-ms-project-store/internal/pkg/helpers"
+)
+
+// NotFound replaces the plain text 404 of the router with a problem
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	helpers.WriteError(w, r, errs.NewNotFoundError("Route not found"))
+}
+
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	helpers.WriteError(w, r, &errs.AppError{
+		Code:      http.StatusMethodNotAllowed,
+		Message:   "Method not allowed",
+		ErrorCode: errs.CodeMethodNotAllowed,
+	})
+}
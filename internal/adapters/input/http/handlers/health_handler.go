@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-ms-project-store/internal/pkg/health"
+	"github.com/go-ms-project-store/internal/pkg/helpers"
+)
+
+type HealthHandlers struct {
+	Registry *health.Registry
+}
+
+// Liveness only reports that the process serves requests. Dependencies are
+// left to Readiness so an outage doesn't get healthy instances restarted.
+func (hh *HealthHandlers) Liveness(w http.ResponseWriter, r *http.Request) {
+	helpers.WriteResponse(w, http.StatusOK, health.Report{Status: health.StatusUp, Checks: []health.CheckResult{}})
+}
+
+func (hh *HealthHandlers) Readiness(w http.ResponseWriter, r *http.Request) {
+	report := hh.Registry.Ready(r.Context())
+
+	code := http.StatusOK
+	if report.Status != health.StatusUp {
+		code = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	helpers.WriteResponse(w, code, report)
+}
+
+func NewHealthHandlers(registry *health.Registry) *HealthHandlers {
+	return &HealthHandlers{
+		Registry: registry,
+	}
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-ms-project-store/internal/core/ports"
+	"github.com/go-ms-project-store/internal/pkg/helpers"
+	"github.com/go-ms-project-store/internal/pkg/pagination"
+)
+
+type LoginAttemptHandlers struct {
+	Service ports.LoginAttemptService
+}
+
+func (lh *LoginAttemptHandlers) GetAllLoginAttempts(w http.ResponseWriter, r *http.Request) {
+	attempts, totalRows, filter, err := lh.Service.GetAllLoginAttempts(r)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	baseURL := helpers.GetFullRouteUrl(r)
+
+	paginatedResponse := pagination.NewPaginatedResponse(attempts.ToDTO(), filter.Page, filter.PerPage, int(totalRows), baseURL)
+	helpers.WriteResponse(w, http.StatusOK, paginatedResponse)
+}
+
+func NewLoginAttemptHandlers(service ports.LoginAttemptService) *LoginAttemptHandlers {
+	return &LoginAttemptHandlers{
+		Service: service,
+	}
+}
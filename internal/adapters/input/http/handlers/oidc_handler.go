@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-ms-project-store/internal/adapters/input/http/dto"
+	"github.com/go-ms-project-store/internal/core/ports"
+	"github.com/go-ms-project-store/internal/pkg/errs"
+	"github.com/go-ms-project-store/internal/pkg/helpers"
+)
+
+// The cookie binding an OIDC state to the browser that started sign in. It
+// lives for the browser session, the state itself expires sooner.
+const oidcBindingCookie = "oidc_binding"
+
+type OIDCHandlers struct {
+	Service ports.OIDCService
+}
+
+func (oh *OIDCHandlers) Authorize(w http.ResponseWriter, r *http.Request) {
+	authorization, err := oh.Service.Authorize(r.Context(), chi.URLParam(r, "provider"))
+	if err != nil {
+		helpers.WriteError(w, r, err)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcBindingCookie,
+		Value:    authorization.Binding,
+		Path:     "/api/v1/auth/oidc",
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	helpers.WriteResponse(w, http.StatusOK, authorization)
+}
+
+func (oh *OIDCHandlers) Callback(w http.ResponseWriter, r *http.Request) {
+	var callbackRequest dto.OIDCCallbackRequest
+
+	err := json.NewDecoder(r.Body).Decode(&callbackRequest)
+	if err != nil {
+		helpers.WriteError(w, r, errs.NewBadRequestError(err.Error()))
+		return
+	}
+
+	if err := dto.ValidateOIDC(&callbackRequest); err != nil {
+		helpers.WriteError(w, r, err.AsAppError())
+		return
+	}
+
+	if cookie, err := r.Cookie(oidcBindingCookie); err == nil {
+		callbackRequest.Binding = cookie.Value
+	}
+	callbackRequest.UserAgent = r.UserAgent()
+	callbackRequest.IpAddress = helpers.GetClientIP(r)
+
+	tokenRes, appErr := oh.Service.Callback(r.Context(), chi.URLParam(r, "provider"), callbackRequest)
+
+	// The state can only be used once from this browser
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcBindingCookie,
+		Path:     "/api/v1/auth/oidc",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	if appErr != nil {
+		helpers.WriteError(w, r, appErr)
+	} else {
+		helpers.WriteResponse(w, http.StatusOK, tokenRes)
+	}
+}
+
+func NewOIDCHandlers(service ports.OIDCService) *OIDCHandlers {
+	return &OIDCHandlers{
+		Service: service,
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/go-ms-project-store/internal/adapters/input/http/dto"
 	"github.com/go-ms-project-store/internal/adapters/input/http/middlewares"
 	"github.com/go-ms-project-store/internal/core/ports"
+	"github.com/go-ms-project-store/internal/pkg/errs"
 	"github.com/go-ms-project-store/internal/pkg/helpers"
 )
 
@@ -17,7 +18,7 @@ type OrderHandlers struct {
 func (oh *OrderHandlers) CreateOrder(w http.ResponseWriter, r *http.Request) {
 	user_id, ok := middlewares.GetUserID(r.Context())
 	if !ok {
-		helpers.WriteResponse(w, http.StatusUnauthorized, "Unauthorized")
+		helpers.WriteError(w, r, errs.NewUnauthorizedError("Unauthorized"))
 		return
 	}
 
@@ -25,18 +26,18 @@ func (oh *OrderHandlers) CreateOrder(w http.ResponseWriter, r *http.Request) {
 
 	err := json.NewDecoder(r.Body).Decode(&orderRequest)
 	if err != nil {
-		helpers.WriteResponse(w, http.StatusBadRequest, err.Error())
+		helpers.WriteError(w, r, errs.NewBadRequestError(err.Error()))
 		return
 	}
 
 	if err := dto.ValidateOrder(&orderRequest); err != nil {
-		helpers.WriteResponse(w, http.StatusUnprocessableEntity, err)
+		helpers.WriteError(w, r, err.AsAppError())
 		return
 	}
 
-	order, errCat := oh.Service.CreateOrder(orderRequest, user_id)
+	order, errCat := oh.Service.CreateOrder(r.Context(), orderRequest, user_id)
 	if errCat != nil {
-		helpers.WriteResponse(w, errCat.Code, errCat)
+		helpers.WriteError(w, r, errCat)
 	} else {
 		helpers.WriteResponse(w, http.StatusCreated, order.ToOrderDTO())
 	}
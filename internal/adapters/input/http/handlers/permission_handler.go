@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-ms-project-store/internal/adapters/input/http/dto"
+	"github.com/go-ms-project-store/internal/core/ports"
+	"github.com/go-ms-project-store/internal/pkg/errs"
+	"github.com/go-ms-project-store/internal/pkg/helpers"
+	"github.com/go-ms-project-store/internal/pkg/pagination"
+)
+
+type PermissionHandlers struct {
+	Service ports.PermissionService
+}
+
+func (ph *PermissionHandlers) CreatePermission(w http.ResponseWriter, r *http.Request) {
+	var permissionRequest dto.NewPermissionRequest
+
+	err := json.NewDecoder(r.Body).Decode(&permissionRequest)
+	if err != nil {
+		helpers.WriteError(w, r, errs.NewBadRequestError(err.Error()))
+		return
+	}
+
+	if err := dto.ValidatePermission(&permissionRequest); err != nil {
+		helpers.WriteError(w, r, err.AsAppError())
+		return
+	}
+
+	permission, errPerm := ph.Service.CreatePermission(r.Context(), permissionRequest)
+	if errPerm != nil {
+		helpers.WriteError(w, r, errPerm)
+	} else {
+		helpers.WriteResponse(w, http.StatusCreated, permission.ToPermissionDTO())
+	}
+}
+
+func (ph *PermissionHandlers) DeletePermission(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+
+	_, err := ph.Service.DeletePermission(r.Context(), id)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+	} else {
+		helpers.WriteResponse(w, http.StatusNoContent, "")
+	}
+}
+
+func (ph *PermissionHandlers) GetAllPermissions(w http.ResponseWriter, r *http.Request) {
+	permissions, totalRows, filter, err := ph.Service.GetAllPermissions(r)
+
+	baseURL := helpers.GetFullRouteUrl(r)
+
+	paginatedResponse := pagination.NewPaginatedResponse(permissions.ToDTO(), filter.Page, filter.PerPage, int(totalRows), baseURL)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+	} else {
+		helpers.WriteResponse(w, http.StatusOK, paginatedResponse)
+	}
+}
+
+func (ph *PermissionHandlers) GetPermission(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+
+	permission, err := ph.Service.FindPermissionById(r.Context(), id)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+	} else {
+		helpers.WriteResponse(w, http.StatusOK, permission.ToPermissionDTO())
+	}
+}
+
+func (ph *PermissionHandlers) UpdatePermission(w http.ResponseWriter, r *http.Request) {
+	var permissionRequest dto.UpdatePermissionRequest
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.WriteError(w, r, errs.NewBadRequestError("Invalid ID format"))
+		return
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&permissionRequest)
+	if err != nil {
+		helpers.WriteError(w, r, errs.NewBadRequestError(err.Error()))
+		return
+	}
+
+	if err := dto.ValidatePermission(&permissionRequest); err != nil {
+		helpers.WriteError(w, r, err.AsAppError())
+		return
+	}
+
+	permission, errPerm := ph.Service.UpdatePermission(r.Context(), id, permissionRequest)
+	if errPerm != nil {
+		helpers.WriteError(w, r, errPerm)
+	} else {
+		helpers.WriteResponse(w, http.StatusOK, permission.ToPermissionDTO())
+	}
+}
+
+func NewPermissionHandlers(service ports.PermissionService) *PermissionHandlers {
+	return &PermissionHandlers{
+		Service: service,
+	}
+}
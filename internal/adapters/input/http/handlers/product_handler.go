@@ -8,6 +8,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-ms-project-store/internal/adapters/input/http/dto"
 	"github.com/go-ms-project-store/internal/core/ports"
+	"github.com/go-ms-project-store/internal/pkg/errs"
 	"github.com/go-ms-project-store/internal/pkg/helpers"
 	"github.com/go-ms-project-store/internal/pkg/pagination"
 )
@@ -19,9 +20,9 @@ type ProductHandlers struct {
 func (ch *ProductHandlers) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
 
-	_, err := ch.Service.DeleteProduct(id)
+	_, err := ch.Service.DeleteProduct(r.Context(), id)
 	if err != nil {
-		helpers.WriteResponse(w, err.Code, err.AsMessage())
+		helpers.WriteError(w, r, err)
 	} else {
 		helpers.WriteResponse(w, http.StatusNoContent, "")
 	}
@@ -32,18 +33,18 @@ func (ch *ProductHandlers) CreateProduct(w http.ResponseWriter, r *http.Request)
 
 	err := json.NewDecoder(r.Body).Decode(&productRequest)
 	if err != nil {
-		helpers.WriteResponse(w, http.StatusBadRequest, err.Error())
+		helpers.WriteError(w, r, errs.NewBadRequestError(err.Error()))
 		return
 	}
 
 	if err := dto.ValidateProduct(&productRequest); err != nil {
-		helpers.WriteResponse(w, http.StatusUnprocessableEntity, err)
+		helpers.WriteError(w, r, err.AsAppError())
 		return
 	}
 
-	product, errCat := ch.Service.CreateProduct(productRequest)
+	product, errCat := ch.Service.CreateProduct(r.Context(), productRequest)
 	if errCat != nil {
-		helpers.WriteResponse(w, errCat.Code, errCat)
+		helpers.WriteError(w, r, errCat)
 	} else {
 		helpers.WriteResponse(w, http.StatusCreated, product.ToProductDTO())
 	}
@@ -56,7 +57,7 @@ func (ch *ProductHandlers) GetAllProducts(w http.ResponseWriter, r *http.Request
 
 	paginatedResponse := pagination.NewPaginatedResponse(products.ToDTO(), filter.Page, filter.PerPage, int(totalRows), baseURL)
 	if err != nil {
-		helpers.WriteResponse(w, err.Code, err.AsMessage())
+		helpers.WriteError(w, r, err)
 	} else {
 		helpers.WriteResponse(w, http.StatusOK, paginatedResponse)
 	}
@@ -69,7 +70,7 @@ func (ch *ProductHandlers) GetAllPublicProducts(w http.ResponseWriter, r *http.R
 
 	paginatedResponse := pagination.NewPaginatedResponse(products.ToPublicDTO(), filter.Page, filter.PerPage, int(totalRows), baseURL)
 	if err != nil {
-		helpers.WriteResponse(w, err.Code, err.AsMessage())
+		helpers.WriteError(w, r, err)
 	} else {
 		helpers.WriteResponse(w, http.StatusOK, paginatedResponse)
 	}
@@ -78,9 +79,9 @@ func (ch *ProductHandlers) GetAllPublicProducts(w http.ResponseWriter, r *http.R
 func (ch *ProductHandlers) GetProduct(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
 
-	product, err := ch.Service.FindProductById(id)
+	product, err := ch.Service.FindProductById(r.Context(), id)
 	if err != nil {
-		helpers.WriteResponse(w, err.Code, err.AsMessage())
+		helpers.WriteError(w, r, err)
 	} else {
 		helpers.WriteResponse(w, http.StatusOK, product.ToProductDTO())
 	}
@@ -89,9 +90,9 @@ func (ch *ProductHandlers) GetProduct(w http.ResponseWriter, r *http.Request) {
 func (ch *ProductHandlers) GetPublicProduct(w http.ResponseWriter, r *http.Request) {
 	slug := chi.URLParam(r, "slug")
 
-	product, err := ch.Service.FindProductBySlug(slug)
+	product, err := ch.Service.FindProductBySlug(r.Context(), slug)
 	if err != nil {
-		helpers.WriteResponse(w, err.Code, err.AsMessage())
+		helpers.WriteError(w, r, err)
 	} else {
 		helpers.WriteResponse(w, http.StatusOK, product.ToPublicProductDTO())
 	}
@@ -101,24 +102,24 @@ func (ch *ProductHandlers) UpdateProduct(w http.ResponseWriter, r *http.Request)
 	var productRequest dto.UpdateProductRequest
 	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err != nil {
-		helpers.WriteResponse(w, http.StatusBadRequest, "Invalid ID format")
+		helpers.WriteError(w, r, errs.NewBadRequestError("Invalid ID format"))
 		return
 	}
 
 	err = json.NewDecoder(r.Body).Decode(&productRequest)
 	if err != nil {
-		helpers.WriteResponse(w, http.StatusBadRequest, err.Error())
+		helpers.WriteError(w, r, errs.NewBadRequestError(err.Error()))
 		return
 	}
 
 	if err := dto.ValidateProduct(&productRequest); err != nil {
-		helpers.WriteResponse(w, http.StatusUnprocessableEntity, err)
+		helpers.WriteError(w, r, err.AsAppError())
 		return
 	}
 
-	product, errCat := ch.Service.UpdateProduct(id, productRequest)
+	product, errCat := ch.Service.UpdateProduct(r.Context(), id, productRequest)
 	if errCat != nil {
-		helpers.WriteResponse(w, errCat.Code, errCat)
+		helpers.WriteError(w, r, errCat)
 	} else {
 		helpers.WriteResponse(w, http.StatusOK, product.ToProductDTO())
 	}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-ms-project-store/internal/adapters/input/http/dto"
+	"github.com/go-ms-project-store/internal/core/ports"
+	"github.com/go-ms-project-store/internal/pkg/errs"
+	"github.com/go-ms-project-store/internal/pkg/helpers"
+	"github.com/go-ms-project-store/internal/pkg/pagination"
+)
+
+type RoleHandlers struct {
+	Service ports.RoleService
+}
+
+func (rh *RoleHandlers) AttachPermissions(w http.ResponseWriter, r *http.Request) {
+	var permissionsRequest dto.RolePermissionsRequest
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.WriteError(w, r, errs.NewBadRequestError("Invalid ID format"))
+		return
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&permissionsRequest)
+	if err != nil {
+		helpers.WriteError(w, r, errs.NewBadRequestError(err.Error()))
+		return
+	}
+
+	if err := dto.ValidateRole(&permissionsRequest); err != nil {
+		helpers.WriteError(w, r, err.AsAppError())
+		return
+	}
+
+	role, errRole := rh.Service.AttachPermissions(r.Context(), id, permissionsRequest)
+	if errRole != nil {
+		helpers.WriteError(w, r, errRole)
+	} else {
+		helpers.WriteResponse(w, http.StatusOK, role.ToRoleDTO())
+	}
+}
+
+func (rh *RoleHandlers) CreateRole(w http.ResponseWriter, r *http.Request) {
+	var roleRequest dto.NewRoleRequest
+
+	err := json.NewDecoder(r.Body).Decode(&roleRequest)
+	if err != nil {
+		helpers.WriteError(w, r, errs.NewBadRequestError(err.Error()))
+		return
+	}
+
+	if err := dto.ValidateRole(&roleRequest); err != nil {
+		helpers.WriteError(w, r, err.AsAppError())
+		return
+	}
+
+	role, errRole := rh.Service.CreateRole(r.Context(), roleRequest)
+	if errRole != nil {
+		helpers.WriteError(w, r, errRole)
+	} else {
+		helpers.WriteResponse(w, http.StatusCreated, role.ToRoleDTO())
+	}
+}
+
+func (rh *RoleHandlers) DeleteRole(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+
+	_, err := rh.Service.DeleteRole(r.Context(), id)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+	} else {
+		helpers.WriteResponse(w, http.StatusNoContent, "")
+	}
+}
+
+func (rh *RoleHandlers) DetachPermission(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.WriteError(w, r, errs.NewBadRequestError("Invalid ID format"))
+		return
+	}
+
+	permissionId, err := strconv.ParseInt(chi.URLParam(r, "permissionId"), 10, 64)
+	if err != nil {
+		helpers.WriteError(w, r, errs.NewBadRequestError("Invalid ID format"))
+		return
+	}
+
+	role, errRole := rh.Service.DetachPermission(r.Context(), id, permissionId)
+	if errRole != nil {
+		helpers.WriteError(w, r, errRole)
+	} else {
+		helpers.WriteResponse(w, http.StatusOK, role.ToRoleDTO())
+	}
+}
+
+func (rh *RoleHandlers) GetAllRoles(w http.ResponseWriter, r *http.Request) {
+	roles, totalRows, filter, err := rh.Service.GetAllRoles(r)
+
+	baseURL := helpers.GetFullRouteUrl(r)
+
+	paginatedResponse := pagination.NewPaginatedResponse(roles.ToDTO(), filter.Page, filter.PerPage, int(totalRows), baseURL)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+	} else {
+		helpers.WriteResponse(w, http.StatusOK, paginatedResponse)
+	}
+}
+
+func (rh *RoleHandlers) GetRole(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+
+	role, err := rh.Service.FindRoleById(r.Context(), id)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+	} else {
+		helpers.WriteResponse(w, http.StatusOK, role.ToRoleDTO())
+	}
+}
+
+func (rh *RoleHandlers) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	var roleRequest dto.UpdateRoleRequest
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		helpers.WriteError(w, r, errs.NewBadRequestError("Invalid ID format"))
+		return
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&roleRequest)
+	if err != nil {
+		helpers.WriteError(w, r, errs.NewBadRequestError(err.Error()))
+		return
+	}
+
+	if err := dto.ValidateRole(&roleRequest); err != nil {
+		helpers.WriteError(w, r, err.AsAppError())
+		return
+	}
+
+	role, errRole := rh.Service.UpdateRole(r.Context(), id, roleRequest)
+	if errRole != nil {
+		helpers.WriteError(w, r, errRole)
+	} else {
+		helpers.WriteResponse(w, http.StatusOK, role.ToRoleDTO())
+	}
+}
+
+func NewRoleHandlers(service ports.RoleService) *RoleHandlers {
+	return &RoleHandlers{
+		Service: service,
+	}
+}
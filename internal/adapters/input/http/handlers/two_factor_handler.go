@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-ms-project-store/internal/adapters/input/http/dto"
+	"github.com/go-ms-project-store/internal/adapters/input/http/middlewares"
+	"github.com/go-ms-project-store/internal/core/ports"
+	"github.com/go-ms-project-store/internal/pkg/errs"
+	"github.com/go-ms-project-store/internal/pkg/helpers"
+)
+
+type TwoFactorHandlers struct {
+	Service ports.TwoFactorService
+}
+
+func (th *TwoFactorHandlers) Enable(w http.ResponseWriter, r *http.Request) {
+	user_id, ok := middlewares.GetUserID(r.Context())
+	if !ok {
+		helpers.WriteError(w, r, errs.NewUnauthorizedError("Unauthorized"))
+		return
+	}
+
+	setup, err := th.Service.EnableTwoFactor(r.Context(), user_id)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+	} else {
+		helpers.WriteResponse(w, http.StatusOK, setup)
+	}
+}
+
+func (th *TwoFactorHandlers) Confirm(w http.ResponseWriter, r *http.Request) {
+	user_id, ok := middlewares.GetUserID(r.Context())
+	if !ok {
+		helpers.WriteError(w, r, errs.NewUnauthorizedError("Unauthorized"))
+		return
+	}
+
+	var confirmRequest dto.ConfirmTwoFactorRequest
+
+	err := json.NewDecoder(r.Body).Decode(&confirmRequest)
+	if err != nil {
+		helpers.WriteError(w, r, errs.NewBadRequestError(err.Error()))
+		return
+	}
+
+	if err := dto.ValidateTwoFactor(&confirmRequest); err != nil {
+		helpers.WriteError(w, r, err.AsAppError())
+		return
+	}
+
+	codes, appErr := th.Service.ConfirmTwoFactor(r.Context(), user_id, confirmRequest)
+	if appErr != nil {
+		helpers.WriteError(w, r, appErr)
+	} else {
+		helpers.WriteResponse(w, http.StatusOK, dto.RecoveryCodesResponse{RecoveryCodes: codes})
+	}
+}
+
+func (th *TwoFactorHandlers) Disable(w http.ResponseWriter, r *http.Request) {
+	user_id, ok := middlewares.GetUserID(r.Context())
+	if !ok {
+		helpers.WriteError(w, r, errs.NewUnauthorizedError("Unauthorized"))
+		return
+	}
+
+	var disableRequest dto.DisableTwoFactorRequest
+
+	err := json.NewDecoder(r.Body).Decode(&disableRequest)
+	if err != nil {
+		helpers.WriteError(w, r, errs.NewBadRequestError(err.Error()))
+		return
+	}
+
+	if err := dto.ValidateTwoFactor(&disableRequest); err != nil {
+		helpers.WriteError(w, r, err.AsAppError())
+		return
+	}
+
+	appErr := th.Service.DisableTwoFactor(r.Context(), user_id, disableRequest)
+	if appErr != nil {
+		helpers.WriteError(w, r, appErr)
+	} else {
+		msg := map[string]string{
+			"message": "Two-factor authentication disabled",
+		}
+		helpers.WriteResponse(w, http.StatusOK, msg)
+	}
+}
+
+func (th *TwoFactorHandlers) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	user_id, ok := middlewares.GetUserID(r.Context())
+	if !ok {
+		helpers.WriteError(w, r, errs.NewUnauthorizedError("Unauthorized"))
+		return
+	}
+
+	var codeRequest dto.ConfirmTwoFactorRequest
+
+	err := json.NewDecoder(r.Body).Decode(&codeRequest)
+	if err != nil {
+		helpers.WriteError(w, r, errs.NewBadRequestError(err.Error()))
+		return
+	}
+
+	if err := dto.ValidateTwoFactor(&codeRequest); err != nil {
+		helpers.WriteError(w, r, err.AsAppError())
+		return
+	}
+
+	codes, appErr := th.Service.RegenerateRecoveryCodes(r.Context(), user_id, codeRequest)
+	if appErr != nil {
+		helpers.WriteError(w, r, appErr)
+	} else {
+		helpers.WriteResponse(w, http.StatusOK, dto.RecoveryCodesResponse{RecoveryCodes: codes})
+	}
+}
+
+func NewTwoFactorHandlers(service ports.TwoFactorService) *TwoFactorHandlers {
+	return &TwoFactorHandlers{
+		Service: service,
+	}
+}
@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"encoding/json"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/go-ms-project-store/internal/adapters/input/http/dto"
 	"github.com/go-ms-project-store/internal/core/ports"
+	"github.com/go-ms-project-store/internal/pkg/errs"
 	"github.com/go-ms-project-store/internal/pkg/helpers"
 	"github.com/go-ms-project-store/internal/pkg/pagination"
 )
@@ -13,12 +16,34 @@ type UserHandlers struct {
 	Service ports.UserService
 }
 
+func (ch *UserHandlers) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var userRequest dto.NewUserRequest
+
+	err := json.NewDecoder(r.Body).Decode(&userRequest)
+	if err != nil {
+		helpers.WriteError(w, r, errs.NewBadRequestError(err.Error()))
+		return
+	}
+
+	if err := dto.ValidateUser(&userRequest); err != nil {
+		helpers.WriteError(w, r, err.AsAppError())
+		return
+	}
+
+	user, errUser := ch.Service.CreateUser(r.Context(), userRequest)
+	if errUser != nil {
+		helpers.WriteError(w, r, errUser)
+	} else {
+		helpers.WriteResponse(w, http.StatusCreated, user.ToUserDTO())
+	}
+}
+
 func (ch *UserHandlers) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
-	_, err := ch.Service.DeleteUser(id)
+	_, err := ch.Service.DeleteUser(r.Context(), id)
 	if err != nil {
-		helpers.WriteResponse(w, err.Code, err.AsMessage())
+		helpers.WriteError(w, r, err)
 	} else {
 		helpers.WriteResponse(w, http.StatusNoContent, "")
 	}
@@ -31,7 +56,7 @@ func (ch *UserHandlers) GetAllUserAdmins(w http.ResponseWriter, r *http.Request)
 
 	paginatedResponse := pagination.NewPaginatedResponse(users.ToDTO(), filter.Page, filter.PerPage, int(totalRows), baseURL)
 	if err != nil {
-		helpers.WriteResponse(w, err.Code, err.AsMessage())
+		helpers.WriteError(w, r, err)
 	} else {
 		helpers.WriteResponse(w, http.StatusOK, paginatedResponse)
 	}
@@ -44,7 +69,7 @@ func (ch *UserHandlers) GetAllUserCustomers(w http.ResponseWriter, r *http.Reque
 
 	paginatedResponse := pagination.NewPaginatedResponse(users.ToDTO(), filter.Page, filter.PerPage, int(totalRows), baseURL)
 	if err != nil {
-		helpers.WriteResponse(w, err.Code, err.AsMessage())
+		helpers.WriteError(w, r, err)
 	} else {
 		helpers.WriteResponse(w, http.StatusOK, paginatedResponse)
 	}
@@ -53,9 +78,77 @@ func (ch *UserHandlers) GetAllUserCustomers(w http.ResponseWriter, r *http.Reque
 func (ch *UserHandlers) GetUser(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
-	user, err := ch.Service.FindUserById(id)
+	user, err := ch.Service.FindUserById(r.Context(), id)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+	} else {
+		helpers.WriteResponse(w, http.StatusOK, user.ToUserDTO())
+	}
+}
+
+func (ch *UserHandlers) LockUser(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	user, err := ch.Service.LockUser(r.Context(), id)
+	if err != nil {
+		helpers.WriteError(w, r, err)
+	} else {
+		helpers.WriteResponse(w, http.StatusOK, user.ToUserDTO())
+	}
+}
+
+func (ch *UserHandlers) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	user, err := ch.Service.UnlockUser(r.Context(), id)
 	if err != nil {
-		helpers.WriteResponse(w, err.Code, err.AsMessage())
+		helpers.WriteError(w, r, err)
+	} else {
+		helpers.WriteResponse(w, http.StatusOK, user.ToUserDTO())
+	}
+}
+
+func (ch *UserHandlers) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	var userRequest dto.UpdateUserRequest
+	id := chi.URLParam(r, "id")
+
+	err := json.NewDecoder(r.Body).Decode(&userRequest)
+	if err != nil {
+		helpers.WriteError(w, r, errs.NewBadRequestError(err.Error()))
+		return
+	}
+
+	if err := dto.ValidateUser(&userRequest); err != nil {
+		helpers.WriteError(w, r, err.AsAppError())
+		return
+	}
+
+	user, errUser := ch.Service.UpdateUser(r.Context(), id, userRequest)
+	if errUser != nil {
+		helpers.WriteError(w, r, errUser)
+	} else {
+		helpers.WriteResponse(w, http.StatusOK, user.ToUserDTO())
+	}
+}
+
+func (ch *UserHandlers) UpdateUserRole(w http.ResponseWriter, r *http.Request) {
+	var roleRequest dto.UpdateUserRoleRequest
+	id := chi.URLParam(r, "id")
+
+	err := json.NewDecoder(r.Body).Decode(&roleRequest)
+	if err != nil {
+		helpers.WriteError(w, r, errs.NewBadRequestError(err.Error()))
+		return
+	}
+
+	if err := dto.ValidateUser(&roleRequest); err != nil {
+		helpers.WriteError(w, r, err.AsAppError())
+		return
+	}
+
+	user, errUser := ch.Service.UpdateUserRole(r.Context(), id, roleRequest)
+	if errUser != nil {
+		helpers.WriteError(w, r, errUser)
 	} else {
 		helpers.WriteResponse(w, http.StatusOK, user.ToUserDTO())
 	}
@@ -1,13 +1,14 @@
 package middlewares
 
 import (
-	"fmt"
 	"net/http"
 	"strings"
 
+	"github.com/go-ms-project-store/internal/core/enums"
 	"github.com/go-ms-project-store/internal/pkg/errs"
 	"github.com/go-ms-project-store/internal/pkg/helpers"
 	"github.com/go-ms-project-store/internal/pkg/logger"
+	"go.uber.org/zap"
 )
 
 type AbilityMiddleware struct {
@@ -26,14 +27,15 @@ func (am *AbilityMiddleware) RequireAbilities(abilities ...string) func(http.Han
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			token := r.Header.Get("Authorization")
 			if token == "" {
+				helpers.WriteError(w, r, errs.NewUnauthorizedError("Unauthorized"))
 				return
 			}
 
 			token = strings.TrimPrefix(token, "Bearer ")
 
-			tokenAbilities, err := am.authRepo.GetTokenAbilities(token)
+			tokenAbilities, err := am.authRepo.GetTokenAbilities(r.Context(), token)
 			if err != nil {
-				helpers.WriteResponse(w, http.StatusUnauthorized, err.AsMessage())
+				helpers.WriteError(w, r, errs.NewUnauthorizedError(err.Message))
 				return
 			}
 
@@ -41,14 +43,14 @@ func (am *AbilityMiddleware) RequireAbilities(abilities ...string) func(http.Han
 			for _, requiredAbility := range abilities {
 				found := false
 				for _, tokenAbility := range tokenAbilities {
-					if tokenAbility == requiredAbility {
+					if enums.AbilityGrants(tokenAbility, requiredAbility) {
 						found = true
 						break
 					}
 				}
 				if !found {
-					logger.Error(fmt.Sprintf("missing required ability: %s", requiredAbility))
-					helpers.WriteResponse(w, http.StatusForbidden, errs.NewUnauthorizedError("Invalid Token"))
+					logger.FromContext(r.Context()).Warn("Missing required ability", zap.String("ability", requiredAbility))
+					helpers.WriteError(w, r, errs.NewForbiddenError("Invalid Token").WithCode(errs.CodeMissingAbility))
 					return
 				}
 			}
@@ -57,3 +59,31 @@ func (am *AbilityMiddleware) RequireAbilities(abilities ...string) func(http.Han
 		})
 	}
 }
+
+// RequireAnyAbility checks if the token has at least one of the abilities.
+// It lets routes accept both login tokens and scoped personal tokens.
+func (am *AbilityMiddleware) RequireAnyAbility(abilities ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+			tokenAbilities, err := am.authRepo.GetTokenAbilities(r.Context(), token)
+			if err != nil {
+				helpers.WriteError(w, r, errs.NewUnauthorizedError(err.Message))
+				return
+			}
+
+			for _, allowedAbility := range abilities {
+				for _, tokenAbility := range tokenAbilities {
+					if enums.AbilityGrants(tokenAbility, allowedAbility) {
+						next.ServeHTTP(w, r)
+						return
+					}
+				}
+			}
+
+			logger.FromContext(r.Context()).Warn("Missing one of the abilities", zap.Strings("abilities", abilities))
+			helpers.WriteError(w, r, errs.NewForbiddenError("Invalid Token").WithCode(errs.CodeMissingAbility))
+		})
+	}
+}
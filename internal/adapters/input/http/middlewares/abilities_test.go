@@ -0,0 +1,57 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-ms-project-store/internal/core/domain"
+	"github.com/go-ms-project-store/internal/pkg/errs"
+)
+
+type fakeTokenValidator struct {
+	abilities []string
+}
+
+func (v fakeTokenValidator) GetTokenAbilities(context.Context, string) ([]string, *errs.AppError) {
+	return v.abilities, nil
+}
+
+func (v fakeTokenValidator) ValidateToken(context.Context, string) (*domain.Token, *errs.AppError) {
+	return nil, errs.NewUnauthorizedError("Unauthorized")
+}
+
+func TestRequireAbilities(t *testing.T) {
+	tests := []struct {
+		name          string
+		authorization string
+		abilities     []string
+		want          int
+	}{
+		{"missing header", "", []string{"access-token"}, http.StatusUnauthorized},
+		{"missing ability", "Bearer 1|token", []string{"products:read"}, http.StatusForbidden},
+		{"granted by a wildcard", "Bearer 1|token", []string{"products:*"}, http.StatusNoContent},
+		{"granted", "Bearer 1|token", []string{"products:write"}, http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			middleware := NewAbilityMiddleware(fakeTokenValidator{abilities: tt.abilities})
+			handler := middleware.RequireAbilities("products:write")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
@@ -5,15 +5,18 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/go-ms-project-store/internal/core/domain"
 	"github.com/go-ms-project-store/internal/pkg/errs"
 	"github.com/go-ms-project-store/internal/pkg/helpers"
 )
 
 const USER_ID_CONTEXT_KEY = "user_id"
+const TOKEN_ID_CONTEXT_KEY = "token_id"
+const SESSION_ID_CONTEXT_KEY = "session_id"
 
 type TokenValidator interface {
-	GetTokenAbilities(fullToken string) ([]string, *errs.AppError)
-	ValidateToken(token string) (uint64, *errs.AppError)
+	GetTokenAbilities(ctx context.Context, fullToken string) ([]string, *errs.AppError)
+	ValidateToken(ctx context.Context, token string) (*domain.Token, *errs.AppError)
 }
 
 type AuthMiddleware struct {
@@ -28,44 +31,60 @@ func NewAuthMiddleware(validator TokenValidator) *AuthMiddleware {
 
 func (am *AuthMiddleware) Auth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		userID, err := am.validateBearerToken(r)
+		token, err := am.validateBearerToken(r)
 		if err != nil {
-			helpers.WriteResponse(w, http.StatusOK, err.AsMessage())
+			helpers.WriteError(w, r, err)
 			return
 		}
 
-		ctx := context.WithValue(r.Context(), USER_ID_CONTEXT_KEY, userID)
+		setRequestUser(r.Context(), token.UserID)
+
+		ctx := context.WithValue(r.Context(), USER_ID_CONTEXT_KEY, token.UserID)
+		ctx = context.WithValue(ctx, TOKEN_ID_CONTEXT_KEY, token.ID)
+		ctx = context.WithValue(ctx, SESSION_ID_CONTEXT_KEY, token.SessionID)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
-func (am *AuthMiddleware) validateBearerToken(r *http.Request) (uint64, *errs.AppError) {
+func (am *AuthMiddleware) validateBearerToken(r *http.Request) (*domain.Token, *errs.AppError) {
 	authHeader := r.Header.Get("Authorization")
 	if authHeader == "" {
-		return 0, errs.NewUnauthorizedError("unauthorized: no token provided")
+		return nil, errs.NewUnauthorizedError("unauthorized: no token provided")
 	}
 
 	// Check Bearer scheme
 	if !strings.HasPrefix(authHeader, "Bearer ") {
-		return 0, errs.NewUnauthorizedError("unauthorized: invalid token format")
+		return nil, errs.NewUnauthorizedError("unauthorized: invalid token format")
 	}
 
 	// Extract token
 	token := strings.TrimPrefix(authHeader, "Bearer ")
 	if token == "" {
-		return 0, errs.NewUnauthorizedError("unauthorized: token is empty")
+		return nil, errs.NewUnauthorizedError("unauthorized: token is empty")
 	}
 
-	// Validate token and get user ID
-	userID, appErr := am.tokenValidator.ValidateToken(token)
+	// Validate token and get its user and session
+	validToken, appErr := am.tokenValidator.ValidateToken(r.Context(), token)
 	if appErr != nil {
-		return 0, errs.NewUnauthorizedError("unauthorized: " + appErr.Message)
+		return nil, errs.NewUnauthorizedError("unauthorized: " + appErr.Message)
 	}
 
-	return userID, nil
+	return validToken, nil
 }
 
 func GetUserID(ctx context.Context) (uint64, bool) {
 	userID, ok := ctx.Value(USER_ID_CONTEXT_KEY).(uint64)
 	return userID, ok
 }
+
+func GetTokenID(ctx context.Context) (uint64, bool) {
+	tokenID, ok := ctx.Value(TOKEN_ID_CONTEXT_KEY).(uint64)
+	return tokenID, ok
+}
+
+// GetSessionID returns the session of the authenticated token. It is zero for
+// tokens issued outside of a login session.
+func GetSessionID(ctx context.Context) (uint64, bool) {
+	sessionID, ok := ctx.Value(SESSION_ID_CONTEXT_KEY).(uint64)
+	return sessionID, ok
+}
@@ -0,0 +1,27 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/go-ms-project-store/internal/pkg/helpers"
+)
+
+// ClientIPMiddleware resolves the client address once per request, so rate
+// limits, login throttling and logs all key on the same, unspoofable value
+type ClientIPMiddleware struct {
+	proxies helpers.TrustedProxies
+}
+
+func NewClientIPMiddleware(proxies helpers.TrustedProxies) *ClientIPMiddleware {
+	return &ClientIPMiddleware{
+		proxies: proxies,
+	}
+}
+
+func (m *ClientIPMiddleware) ClientIP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := helpers.WithClientIP(r.Context(), m.proxies.ClientIP(r))
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
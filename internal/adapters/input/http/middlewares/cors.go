@@ -1,25 +1,257 @@
 package middlewares
 
 import (
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-ms-project-store/internal/pkg/errs"
+	"github.com/go-ms-project-store/internal/pkg/helpers"
+	"github.com/go-ms-project-store/internal/pkg/logger"
+	"go.uber.org/zap"
 )
 
-func Cors(next http.Handler) http.Handler {
+// CorsRules are the methods and headers a route group accepts from
+// cross-origin requests
+type CorsRules struct {
+	Methods []string
+	Headers []string
+}
+
+// CorsPolicy configures which origins may call the API. Origins are exact,
+// like "https://shop.example.com", or match subdomains, like
+// "https://*.example.com". Groups override the default rules for paths
+// starting with their prefix; the longest matching prefix wins.
+type CorsPolicy struct {
+	AllowedOrigins   []string
+	AllowCredentials bool
+	ExposedHeaders   []string
+	MaxAge           time.Duration
+	Default          CorsRules
+	Groups           map[string]CorsRules
+}
+
+type CorsMiddleware struct {
+	policy    CorsPolicy
+	anyOrigin bool
+	origins   []originPattern
+}
+
+type originPattern struct {
+	scheme string
+	host   string
+	port   string
+	// wildcard matches any subdomain of host
+	wildcard bool
+}
+
+func NewCorsMiddleware(policy CorsPolicy) (*CorsMiddleware, error) {
+	cm := &CorsMiddleware{policy: policy}
+
+	for _, origin := range policy.AllowedOrigins {
+		origin = strings.TrimSpace(origin)
+		if origin == "" {
+			continue
+		}
+
+		if origin == "*" {
+			// Browsers reject credentialed responses for any origin
+			if policy.AllowCredentials {
+				return nil, fmt.Errorf("CORS origin * can't be combined with credentials")
+			}
+			cm.anyOrigin = true
+			continue
+		}
+
+		pattern, ok := parseOriginPattern(origin)
+		if !ok {
+			return nil, fmt.Errorf("invalid CORS origin %q", origin)
+		}
+		cm.origins = append(cm.origins, pattern)
+	}
+
+	return cm, nil
+}
+
+// Cors answers preflight requests and adds the CORS headers to actual
+// requests from allowed origins. Disallowed actual requests are passed on
+// without CORS headers, so the browser withholds the response.
+func (cm *CorsMiddleware) Cors(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Set CORS headers
-		w.Header().Set("Access-Control-Allow-Origin", "*") // In production, replace * with your specific domain
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Accept, Authorization, Content-Type, X-CSRF-Token")
-		w.Header().Set("Access-Control-Expose-Headers", "Authorization")
-		w.Header().Set("Access-Control-Allow-Credentials", "true")
-		w.Header().Set("Access-Control-Max-Age", "300") // 5 minutes
+		w.Header().Add("Vary", "Origin")
+
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
 
-		// Handle preflight requests
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			cm.preflight(w, r, origin)
 			return
 		}
 
+		if cm.allowsOrigin(origin) {
+			cm.writeOrigin(w, origin)
+			if len(cm.policy.ExposedHeaders) > 0 {
+				w.Header().Set("Access-Control-Expose-Headers", strings.Join(cm.policy.ExposedHeaders, ", "))
+			}
+		}
+
 		next.ServeHTTP(w, r)
 	})
 }
+
+func (cm *CorsMiddleware) preflight(w http.ResponseWriter, r *http.Request, origin string) {
+	w.Header().Add("Vary", "Access-Control-Request-Method")
+	w.Header().Add("Vary", "Access-Control-Request-Headers")
+
+	rules := cm.rulesFor(r.URL.Path)
+	method := strings.ToUpper(r.Header.Get("Access-Control-Request-Method"))
+	requested := parseHeaderList(r.Header.Get("Access-Control-Request-Headers"))
+
+	reason := ""
+	switch {
+	case !cm.allowsOrigin(origin):
+		reason = "origin not allowed"
+	case !containsFold(rules.Methods, method):
+		reason = "method not allowed"
+	default:
+		for _, header := range requested {
+			if !containsFold(rules.Headers, header) {
+				reason = "header not allowed: " + header
+				break
+			}
+		}
+	}
+
+	if reason != "" {
+		logger.FromContext(r.Context()).Warn("CORS preflight rejected",
+			zap.String("origin", origin),
+			zap.String("path", r.URL.Path),
+			zap.String("method", method),
+			zap.String("reason", reason),
+		)
+		helpers.WriteError(w, r, errs.NewForbiddenError("CORS preflight rejected"))
+		return
+	}
+
+	logger.FromContext(r.Context()).Debug("CORS preflight allowed",
+		zap.String("origin", origin),
+		zap.String("path", r.URL.Path),
+		zap.String("method", method),
+	)
+
+	cm.writeOrigin(w, origin)
+	w.Header().Set("Access-Control-Allow-Methods", strings.Join(rules.Methods, ", "))
+	if len(requested) > 0 {
+		w.Header().Set("Access-Control-Allow-Headers", strings.Join(requested, ", "))
+	}
+	if cm.policy.MaxAge > 0 {
+		w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(cm.policy.MaxAge.Seconds())))
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cm *CorsMiddleware) writeOrigin(w http.ResponseWriter, origin string) {
+	if cm.anyOrigin && !cm.matchesPattern(origin) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		return
+	}
+
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	if cm.policy.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+func (cm *CorsMiddleware) allowsOrigin(origin string) bool {
+	return cm.anyOrigin || cm.matchesPattern(origin)
+}
+
+func (cm *CorsMiddleware) matchesPattern(origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	for _, pattern := range cm.origins {
+		if pattern.scheme != strings.ToLower(u.Scheme) || pattern.port != u.Port() {
+			continue
+		}
+
+		if pattern.wildcard {
+			if strings.HasSuffix(host, "."+pattern.host) {
+				return true
+			}
+		} else if host == pattern.host {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (cm *CorsMiddleware) rulesFor(path string) CorsRules {
+	rules := cm.policy.Default
+	longest := -1
+
+	for prefix, groupRules := range cm.policy.Groups {
+		if strings.HasPrefix(path, prefix) && len(prefix) > longest {
+			rules = groupRules
+			longest = len(prefix)
+		}
+	}
+
+	return rules
+}
+
+func parseOriginPattern(origin string) (originPattern, bool) {
+	u, err := url.Parse(strings.ToLower(origin))
+	if err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") {
+		return originPattern{}, false
+	}
+
+	pattern := originPattern{
+		scheme: u.Scheme,
+		host:   u.Hostname(),
+		port:   u.Port(),
+	}
+
+	if strings.HasPrefix(pattern.host, "*.") {
+		pattern.wildcard = true
+		pattern.host = strings.TrimPrefix(pattern.host, "*.")
+	}
+
+	if pattern.host == "" || strings.Contains(pattern.host, "*") {
+		return originPattern{}, false
+	}
+
+	return pattern, true
+}
+
+func parseHeaderList(value string) []string {
+	var headers []string
+	for _, header := range strings.Split(value, ",") {
+		if header = strings.TrimSpace(header); header != "" {
+			headers = append(headers, http.CanonicalHeaderKey(header))
+		}
+	}
+
+	return headers
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
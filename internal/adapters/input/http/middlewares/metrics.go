@@ -0,0 +1,39 @@
+package middlewares
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-ms-project-store/internal/pkg/metrics"
+)
+
+// Metrics records the count and latency of each request. Requests are
+// labelled with the chi route pattern, which is only known once routing is
+// done, so paths with IDs don't create a series each.
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r)
+
+		metrics.ObserveRequest(r.Method, routePattern(r), ww.Status(), time.Since(start))
+	})
+}
+
+// routePattern returns the matched route pattern, e.g. /api/v1/products/{slug}
+func routePattern(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil {
+		return "unmatched"
+	}
+
+	pattern := rctx.RoutePattern()
+	if pattern == "" {
+		return "unmatched"
+	}
+
+	return pattern
+}
@@ -0,0 +1,55 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-ms-project-store/internal/core/domain"
+	"github.com/go-ms-project-store/internal/pkg/errs"
+	"github.com/go-ms-project-store/internal/pkg/helpers"
+	"github.com/go-ms-project-store/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+type RoleResolver interface {
+	GetUserRole(ctx context.Context, userId uint64) (*domain.Role, *errs.AppError)
+}
+
+type PermissionMiddleware struct {
+	roleResolver RoleResolver
+}
+
+func NewPermissionMiddleware(resolver RoleResolver) *PermissionMiddleware {
+	return &PermissionMiddleware{
+		roleResolver: resolver,
+	}
+}
+
+// RequirePermissions checks if the authenticated user's role grants all the required permissions
+func (pm *PermissionMiddleware) RequirePermissions(permissions ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := GetUserID(r.Context())
+			if !ok {
+				helpers.WriteError(w, r, errs.NewUnauthorizedError("Unauthorized"))
+				return
+			}
+
+			role, err := pm.roleResolver.GetUserRole(r.Context(), userID)
+			if err != nil {
+				helpers.WriteError(w, r, err)
+				return
+			}
+
+			for _, permission := range permissions {
+				if !role.HasPermission(permission) {
+					logger.FromContext(r.Context()).Warn("Missing required permission", zap.String("permission", permission))
+					helpers.WriteError(w, r, errs.NewForbiddenError("Forbidden").WithCode(errs.CodeMissingPermission))
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-ms-project-store/internal/pkg/ratelimit"
+)
+
+func TestRateLimitByTokenGivesEachTokenItsOwnBudget(t *testing.T) {
+	middleware := NewRateLimitMiddleware(ratelimit.NewMemoryStore())
+	handler := middleware.Limit(RateLimitPolicy{Name: "token", Limit: 2, Window: time.Minute, KeyBy: RateLimitByToken})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	send := func(authorization string) int {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/payment/checkout", nil)
+		r.RemoteAddr = "203.0.113.7:1234"
+		if authorization != "" {
+			r.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		return w.Code
+	}
+
+	for i := 0; i < 2; i++ {
+		if code := send("Bearer 1|orders-token"); code != http.StatusNoContent {
+			t.Fatalf("request %d = %d, want it allowed", i+1, code)
+		}
+	}
+	if code := send("Bearer 1|orders-token"); code != http.StatusTooManyRequests {
+		t.Fatalf("a request over the limit = %d, want a 429", code)
+	}
+
+	// Another token of the same user, from the same address, isn't affected
+	if code := send("Bearer 2|products-token"); code != http.StatusNoContent {
+		t.Errorf("another token = %d, want it allowed", code)
+	}
+	// Without a token the client address is the key
+	if code := send(""); code != http.StatusNoContent {
+		t.Errorf("a request without a token = %d, want it allowed", code)
+	}
+}
+
+func TestRateLimitByTokenDoesNotUseTheRawToken(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer 1|secret")
+
+	if key := RateLimitByToken(r); !strings.HasPrefix(key, "token:") || strings.Contains(key, "secret") {
+		t.Errorf("key = %q, want a hash of the token", key)
+	}
+}
@@ -0,0 +1,36 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/go-ms-project-store/internal/pkg/errs"
+	"github.com/go-ms-project-store/internal/pkg/helpers"
+	"github.com/go-ms-project-store/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Recoverer turns a panic in a handler into a 500 problem and logs it with
+// its stack. http.ErrAbortHandler is passed on, as it is meant to abort the
+// response.
+func Recoverer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rvr := recover()
+			if rvr == nil {
+				return
+			}
+			if rvr == http.ErrAbortHandler {
+				panic(rvr)
+			}
+
+			logger.FromContext(r.Context()).Error("Recovered from panic",
+				zap.Any("panic", rvr),
+				zap.Stack("stack"),
+			)
+
+			helpers.WriteError(w, r, errs.NewUnexpectedError("Internal server error"))
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
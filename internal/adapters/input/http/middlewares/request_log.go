@@ -0,0 +1,105 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-ms-project-store/internal/pkg/helpers"
+	"github.com/go-ms-project-store/internal/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const REQUEST_ID_HEADER = "X-Request-ID"
+
+// Longer request IDs are replaced, so clients can't flood the logs
+const maxRequestIDLength = 128
+
+type requestLogKey struct{}
+
+// requestLog holds what is learned about a request while it is served, such
+// as its route and the authenticated user
+type requestLog struct {
+	r      *http.Request
+	userID uint64
+}
+
+// requestCore adds the route and user to each line when it is written, as
+// neither is known yet when the request logger is created
+type requestCore struct {
+	zapcore.Core
+	info *requestLog
+}
+
+func (c requestCore) With(fields []zapcore.Field) zapcore.Core {
+	return requestCore{Core: c.Core.With(fields), info: c.info}
+}
+
+func (c requestCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+
+	return checked
+}
+
+func (c requestCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	fields = append(fields, zap.String("route", routePattern(c.info.r)))
+	if c.info.userID != 0 {
+		fields = append(fields, zap.Uint64("user_id", c.info.userID))
+	}
+
+	return c.Core.Write(entry, fields)
+}
+
+// RequestLog accepts or generates an X-Request-ID, stores a logger tagged
+// with it in the request context and writes one access log line per request
+func RequestLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		requestID := r.Header.Get(REQUEST_ID_HEADER)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = uuid.NewString()
+		}
+		w.Header().Set(REQUEST_ID_HEADER, requestID)
+
+		info := &requestLog{}
+		ctx := context.WithValue(r.Context(), requestLogKey{}, info)
+		ctx = logger.WithOptions(ctx, zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return requestCore{Core: core, info: info}
+		}))
+		ctx = logger.With(ctx, zap.String("request_id", requestID), zap.String("method", r.Method))
+		r = r.WithContext(ctx)
+		info.r = r
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r)
+
+		fields := []zap.Field{
+			zap.String("path", r.URL.Path),
+			zap.Int("status", ww.Status()),
+			zap.Int("bytes", ww.BytesWritten()),
+			zap.Duration("duration", time.Since(start)),
+			zap.String("ip", helpers.GetClientIP(r)),
+			zap.String("user_agent", r.UserAgent()),
+		}
+
+		if ww.Status() >= http.StatusInternalServerError {
+			logger.FromContext(ctx).Error("Request completed", fields...)
+		} else {
+			logger.FromContext(ctx).Info("Request completed", fields...)
+		}
+	})
+}
+
+// setRequestUser tags the request's log lines with the authenticated user
+func setRequestUser(ctx context.Context, userID uint64) {
+	if info, ok := ctx.Value(requestLogKey{}).(*requestLog); ok {
+		info.userID = userID
+	}
+}
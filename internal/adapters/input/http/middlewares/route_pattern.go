@@ -7,6 +7,10 @@ import (
 	"github.com/go-ms-project-store/internal/core/enums"
 )
 
+// StoreRoutePattern stores the request path under enums.RoutePatternKey,
+// which helpers.GetCurrentUri reads to build pagination links. Despite the
+// key's name it is the concrete path: a pattern such as /products/{id}
+// can't be linked to. Metrics are labelled with the chi route pattern.
 func StoreRoutePattern(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		fullPath := r.URL.Path
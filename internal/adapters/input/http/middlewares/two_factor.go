@@ -0,0 +1,58 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-ms-project-store/internal/pkg/errs"
+	"github.com/go-ms-project-store/internal/pkg/helpers"
+)
+
+type TwoFactorStatus interface {
+	IsTwoFactorEnabled(ctx context.Context, userId uint64) (bool, *errs.AppError)
+}
+
+type TwoFactorMiddleware struct {
+	roleResolver    RoleResolver
+	twoFactorStatus TwoFactorStatus
+}
+
+func NewTwoFactorMiddleware(resolver RoleResolver, status TwoFactorStatus) *TwoFactorMiddleware {
+	return &TwoFactorMiddleware{
+		roleResolver:    resolver,
+		twoFactorStatus: status,
+	}
+}
+
+// RequireTwoFactor rejects users whose role requires two-factor
+// authentication until they have enabled it
+func (tm *TwoFactorMiddleware) RequireTwoFactor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := GetUserID(r.Context())
+		if !ok {
+			helpers.WriteError(w, r, errs.NewUnauthorizedError("Unauthorized"))
+			return
+		}
+
+		role, err := tm.roleResolver.GetUserRole(r.Context(), userID)
+		if err != nil {
+			helpers.WriteError(w, r, err)
+			return
+		}
+
+		if role.RequiresTwoFactor {
+			enabled, err := tm.twoFactorStatus.IsTwoFactorEnabled(r.Context(), userID)
+			if err != nil {
+				helpers.WriteError(w, r, err)
+				return
+			}
+
+			if !enabled {
+				helpers.WriteError(w, r, errs.NewForbiddenError("Your role requires two-factor authentication").WithCode(errs.CodeTwoFactorRequired))
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
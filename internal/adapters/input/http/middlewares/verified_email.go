@@ -0,0 +1,48 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-ms-project-store/internal/core/domain"
+	"github.com/go-ms-project-store/internal/pkg/errs"
+	"github.com/go-ms-project-store/internal/pkg/helpers"
+)
+
+type UserFinder interface {
+	FindById(ctx context.Context, id uint64) (*domain.User, *errs.AppError)
+}
+
+type VerifiedEmailMiddleware struct {
+	userFinder UserFinder
+}
+
+func NewVerifiedEmailMiddleware(finder UserFinder) *VerifiedEmailMiddleware {
+	return &VerifiedEmailMiddleware{
+		userFinder: finder,
+	}
+}
+
+// RequireVerifiedEmail rejects authenticated users who haven't verified their email address
+func (vm *VerifiedEmailMiddleware) RequireVerifiedEmail(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := GetUserID(r.Context())
+		if !ok {
+			helpers.WriteError(w, r, errs.NewUnauthorizedError("Unauthorized"))
+			return
+		}
+
+		user, err := vm.userFinder.FindById(r.Context(), userID)
+		if err != nil {
+			helpers.WriteError(w, r, err)
+			return
+		}
+
+		if !user.HasVerifiedEmail() {
+			helpers.WriteError(w, r, errs.NewForbiddenError("Your email address is not verified").WithCode(errs.CodeEmailNotVerified))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,46 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/go-ms-project-store/internal/adapters/input/http/middlewares"
+	"github.com/go-ms-project-store/internal/pkg/config"
+)
+
+// newCorsPolicy applies the configured origins, such as
+// "https://shop.example.com" or "https://*.example.com", to the route groups
+func newCorsPolicy(cfg config.CORS) middlewares.CorsPolicy {
+	headers := []string{"Accept", "Authorization", "Content-Type", "X-Csrf-Token"}
+
+	return middlewares.CorsPolicy{
+		AllowedOrigins:   cfg.AllowedOrigins,
+		AllowCredentials: cfg.AllowCredentials,
+		ExposedHeaders: []string{
+			"Retry-After",
+			"RateLimit-Limit",
+			"RateLimit-Policy",
+			"RateLimit-Remaining",
+			"RateLimit-Reset",
+		},
+		MaxAge: cfg.MaxAge,
+		// The public storefront is read only
+		Default: middlewares.CorsRules{
+			Methods: []string{http.MethodGet, http.MethodHead},
+			Headers: headers,
+		},
+		Groups: map[string]middlewares.CorsRules{
+			"/api/v1/auth": {
+				Methods: []string{http.MethodGet, http.MethodPost, http.MethodPatch, http.MethodDelete},
+				Headers: headers,
+			},
+			"/api/v1/payment": {
+				Methods: []string{http.MethodPost},
+				Headers: headers,
+			},
+			"/api/v1/admin": {
+				Methods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete},
+				Headers: headers,
+			},
+		},
+	}
+}
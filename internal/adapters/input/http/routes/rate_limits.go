@@ -0,0 +1,66 @@
+package routes
+
+import (
+	"fmt"
+
+	"github.com/go-ms-project-store/internal/adapters/input/http/middlewares"
+	"github.com/go-ms-project-store/internal/pkg/config"
+	"github.com/go-ms-project-store/internal/pkg/ratelimit"
+)
+
+type rateLimitPolicies struct {
+	public   middlewares.RateLimitPolicy
+	auth     middlewares.RateLimitPolicy
+	account  middlewares.RateLimitPolicy
+	admin    middlewares.RateLimitPolicy
+	checkout middlewares.RateLimitPolicy
+	token    middlewares.RateLimitPolicy
+}
+
+// newRateLimitStore selects the counter store, either "memory" or "redis"
+func newRateLimitStore(cfg *config.Config) (ratelimit.Store, error) {
+	switch cfg.RateLimit.Store {
+	case "memory":
+		return ratelimit.NewMemoryStore(), nil
+	case "redis":
+		return ratelimit.NewRedisStore(cfg.Redis.Addr, cfg.Redis.Password, cfg.Redis.DB), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit store %q", cfg.RateLimit.Store)
+	}
+}
+
+// newRateLimitPolicies builds the policy of each route group from its
+// configured rate, e.g. "120/1m"
+func newRateLimitPolicies(cfg config.RateLimit) (rateLimitPolicies, error) {
+	var policies rateLimitPolicies
+
+	groups := []struct {
+		policy *middlewares.RateLimitPolicy
+		name   string
+		rate   string
+		keyBy  middlewares.RateLimitKeyFunc
+	}{
+		{&policies.public, "public", cfg.Public, middlewares.RateLimitByIP},
+		{&policies.auth, "auth", cfg.Auth, middlewares.RateLimitByIP},
+		{&policies.account, "account", cfg.Account, middlewares.RateLimitByUser},
+		{&policies.admin, "admin", cfg.Admin, middlewares.RateLimitByUser},
+		{&policies.checkout, "checkout", cfg.Checkout, middlewares.RateLimitByUser},
+		{&policies.token, "token", cfg.Token, middlewares.RateLimitByToken},
+	}
+
+	for _, group := range groups {
+		limit, window, err := ratelimit.ParseRate(group.rate)
+		if err != nil {
+			return policies, fmt.Errorf("%s: %w", group.name, err)
+		}
+
+		*group.policy = middlewares.RateLimitPolicy{
+			Name:   group.name,
+			Limit:  limit,
+			Window: window,
+			KeyBy:  group.keyBy,
+		}
+	}
+
+	return policies, nil
+}
@@ -1,76 +1,222 @@
 package routes
 
 import (
+	"net/http"
+
 	"github.com/go-chi/chi/v5"
 	"github.com/go-ms-project-store/internal/adapters/input/http/handlers"
 	"github.com/go-ms-project-store/internal/adapters/input/http/middlewares"
+	"github.com/go-ms-project-store/internal/adapters/output/mailer"
 	"github.com/go-ms-project-store/internal/core/enums"
 	"github.com/go-ms-project-store/internal/core/repositories"
 	"github.com/go-ms-project-store/internal/core/services"
-	"github.com/go-ms-project-store/internal/pkg/db"
+	"github.com/go-ms-project-store/internal/pkg/config"
+	"github.com/go-ms-project-store/internal/pkg/health"
+	"github.com/go-ms-project-store/internal/pkg/helpers"
+	"github.com/go-ms-project-store/internal/pkg/logger"
+	"github.com/go-ms-project-store/internal/pkg/metrics"
+	"github.com/jmoiron/sqlx"
 )
 
-func Routes() *chi.Mux {
+func Routes(cfg *config.Config, dbClient *sqlx.DB, healthRegistry *health.Registry) *chi.Mux {
 	mux := chi.NewRouter()
-
-	dbClient := db.GetDBClient()
+	mux.NotFound(handlers.NotFound)
+	mux.MethodNotAllowed(handlers.MethodNotAllowed)
 
 	authRepositoryDB := repositories.NewAuthRepositoryDB(dbClient)
 
-	mux.Use(middlewares.Cors)
+	tokenDriver, err := services.NewTokenDriver(authRepositoryDB, cfg)
+	if err != nil {
+		logger.Fatal("Error while configuring token driver " + err.Error())
+	}
+
+	rateLimitStore, err := newRateLimitStore(cfg)
+	if err != nil {
+		logger.Fatal("Error while configuring rate limit store " + err.Error())
+	}
+
+	rateLimits, err := newRateLimitPolicies(cfg.RateLimit)
+	if err != nil {
+		logger.Fatal("Error while configuring rate limits " + err.Error())
+	}
+
+	corsMiddleware, err := middlewares.NewCorsMiddleware(newCorsPolicy(cfg.CORS))
+	if err != nil {
+		logger.Fatal("Error while configuring CORS " + err.Error())
+	}
+
+	trustedProxies, err := helpers.ParseTrustedProxies(cfg.Server.TrustedProxies)
+	if err != nil {
+		logger.Fatal("Error while configuring trusted proxies " + err.Error())
+	}
+
+	mux.Use(middlewares.NewClientIPMiddleware(trustedProxies).ClientIP)
+	mux.Use(middlewares.Tracing)
+	mux.Use(middlewares.RequestLog)
+	mux.Use(middlewares.Metrics)
+	// Inside the logger and metrics so a panic is logged with the request
+	// and counted as the 500 it is turned into
+	mux.Use(middlewares.Recoverer)
+	mux.Use(corsMiddleware.Cors)
 	mux.Use(middlewares.StoreRoutePattern)
-	authMiddleware := middlewares.NewAuthMiddleware(authRepositoryDB)
-	abilityMiddleware := middlewares.NewAbilityMiddleware(authRepositoryDB)
+	authMiddleware := middlewares.NewAuthMiddleware(tokenDriver)
+	abilityMiddleware := middlewares.NewAbilityMiddleware(tokenDriver)
+	rateLimitMiddleware := middlewares.NewRateLimitMiddleware(rateLimitStore)
 
 	categoryRepositoryDB := repositories.NewCategoryRepositoryDB(dbClient)
+	loginAttemptRepositoryDB := repositories.NewLoginAttemptRepositoryDB(dbClient)
 	orderRepositoryDB := repositories.NewOrderRepositoryDB(dbClient)
+	permissionRepositoryDB := repositories.NewPermissionRepositoryDB(dbClient)
 	productRepositoryDB := repositories.NewProductRepositoryDB(dbClient)
+	roleRepositoryDB := repositories.NewRoleRepositoryDB(dbClient)
 	userRepositoryDB := repositories.NewUserRepositoryDB(dbClient)
 
-	ah := handlers.NewAuthHandlers(services.NewAuthService(authRepositoryDB))
+	permissionCache := services.NewPermissionCache()
+	roleService := services.NewRoleService(roleRepositoryDB, permissionCache)
+	permissionMiddleware := middlewares.NewPermissionMiddleware(roleService)
+	verifiedEmailMiddleware := middlewares.NewVerifiedEmailMiddleware(userRepositoryDB)
+	twoFactorService := services.NewTwoFactorService(authRepositoryDB, cfg.App)
+	twoFactorMiddleware := middlewares.NewTwoFactorMiddleware(roleService, twoFactorService)
+
+	// Checkout only requires a verified email when explicitly enabled
+	checkoutMiddlewares := []func(http.Handler) http.Handler{
+		authMiddleware.Auth,
+		rateLimitMiddleware.Limit(rateLimits.checkout),
+		rateLimitMiddleware.Limit(rateLimits.token),
+		abilityMiddleware.RequireAnyAbility(string(enums.AccessTokenAbility), string(enums.OrdersCreateAbility)),
+	}
+	if cfg.Auth.RequireVerifiedEmail {
+		checkoutMiddlewares = append(checkoutMiddlewares, verifiedEmailMiddleware.RequireVerifiedEmail)
+	}
+
+	authService := services.NewAuthService(authRepositoryDB, tokenDriver, mailer.NewMailer(cfg.Mail), services.NewMemoryAttemptStore(), cfg)
+	ah := handlers.NewAuthHandlers(authService)
 	ch := handlers.NewCategoryHandlers(services.NewCategoryService(categoryRepositoryDB))
+	lah := handlers.NewLoginAttemptHandlers(services.NewLoginAttemptService(loginAttemptRepositoryDB))
+	oih := handlers.NewOIDCHandlers(services.NewOIDCService(authService, services.LoadOIDCProviders(cfg.OIDC)))
 	oh := handlers.NewOrderHandlers(services.NewOrderService(orderRepositoryDB))
+	peh := handlers.NewPermissionHandlers(services.NewPermissionService(permissionRepositoryDB, permissionCache))
 	ph := handlers.NewProductHandlers(services.NewProductService(productRepositoryDB))
-	uh := handlers.NewUserHandlers(services.NewUserService(userRepositoryDB))
+	rh := handlers.NewRoleHandlers(roleService)
+	tfh := handlers.NewTwoFactorHandlers(twoFactorService)
+	hh := handlers.NewHealthHandlers(healthRegistry)
+	uh := handlers.NewUserHandlers(services.NewUserService(userRepositoryDB, permissionCache, tokenDriver))
+
+	// Probes are polled by the orchestrator, so they skip rate limiting
+	mux.Get("/healthz", hh.Liveness)
+	mux.Get("/readyz", hh.Readiness)
+	if cfg.Metrics.Enabled {
+		mux.Handle(cfg.Metrics.Path, metrics.Handler())
+	}
 
 	mux.Route("/api/v1", func(mux chi.Router) {
-		mux.Get("/home", handlers.Home)
-		mux.Get("/products", ph.GetAllPublicProducts)
-		mux.Get("/products/{slug}", ph.GetPublicProduct)
-		mux.With(authMiddleware.Auth, abilityMiddleware.RequireAbilities(string(enums.AccessTokenAbility))).Post("/payment/checkout", oh.CreateOrder)
+		mux.Group(func(mux chi.Router) {
+			mux.Use(rateLimitMiddleware.Limit(rateLimits.public))
+			mux.Get("/home", handlers.Home)
+			mux.Get("/products", ph.GetAllPublicProducts)
+			mux.Get("/products/{slug}", ph.GetPublicProduct)
+		})
+		mux.With(checkoutMiddlewares...).Post("/payment/checkout", oh.CreateOrder)
 
 		mux.Route("/auth", func(mux chi.Router) {
-			mux.Post("/login", ah.Login)
-			mux.Post("/register", ah.Register)
+			mux.Group(func(mux chi.Router) {
+				mux.Use(rateLimitMiddleware.Limit(rateLimits.auth))
+				mux.Post("/login", ah.Login)
+				mux.Post("/register", ah.Register)
+				mux.Get("/verify-email", ah.VerifyEmail)
+				mux.Post("/forgot-password", ah.ForgotPassword)
+				mux.Post("/reset-password", ah.ResetPassword)
+				mux.Get("/oidc/{provider}/authorize", oih.Authorize)
+				mux.Post("/oidc/{provider}/callback", oih.Callback)
+			})
 			mux.Group(func(mux chi.Router) {
 				mux.Use(authMiddleware.Auth)
+				mux.Use(rateLimitMiddleware.Limit(rateLimits.account))
 				mux.With(abilityMiddleware.RequireAbilities(string(enums.RefreshTokenAbility))).Post("/refresh-token", ah.Refresh)
-				mux.Post("/logout", ah.Logout)
-				mux.Get("/me", ah.Me)
+				// Personal tokens are revoked through /tokens, not by logging out
+				mux.With(abilityMiddleware.RequireAnyAbility(string(enums.AccessTokenAbility), string(enums.RefreshTokenAbility))).Post("/logout", ah.Logout)
+				mux.With(abilityMiddleware.RequireAbilities(string(enums.TwoFactorPendingAbility))).Post("/2fa/verify", ah.VerifyTwoFactor)
+				mux.Group(func(mux chi.Router) {
+					mux.Use(abilityMiddleware.RequireAbilities(string(enums.AccessTokenAbility)))
+					mux.Get("/me", ah.Me)
+					mux.Patch("/me", ah.UpdateMe)
+					mux.Post("/me/password", ah.ChangePassword)
+					mux.Delete("/me", ah.DeleteMe)
+					mux.Post("/verify-email/resend", ah.ResendVerificationEmail)
+					mux.Get("/sessions", ah.ListSessions)
+					mux.Delete("/sessions/{id}", ah.RevokeSession)
+					mux.Post("/sessions/logout-others", ah.LogoutOtherSessions)
+					mux.Get("/tokens", ah.ListPersonalTokens)
+					mux.Post("/tokens", ah.CreatePersonalToken)
+					mux.Delete("/tokens/{id}", ah.RevokePersonalToken)
+					mux.Post("/2fa/enable", tfh.Enable)
+					mux.Post("/2fa/confirm", tfh.Confirm)
+					mux.Post("/2fa/disable", tfh.Disable)
+					mux.Post("/2fa/recovery-codes", tfh.RegenerateRecoveryCodes)
+				})
 			})
 		})
 		mux.Route("/admin", func(mux chi.Router) {
 			mux.Use(authMiddleware.Auth)
-			mux.Use(abilityMiddleware.RequireAbilities(string(enums.AccessTokenAbility)))
+			mux.Use(rateLimitMiddleware.Limit(rateLimits.admin))
+			mux.Use(twoFactorMiddleware.RequireTwoFactor)
+			// Catalogue routes also accept personal tokens with a matching
+			// ability, and each token gets its own budget within the user's
 			mux.Route("/categories", func(mux chi.Router) {
-				mux.Get("/", ch.GetAllCategories)
-				mux.Get("/{id}", ch.GetCategory)
-				mux.Post("/", ch.CreateCategory)
-				mux.Put("/{id}", ch.UpdateCategory)
-				mux.Delete("/{id}", ch.DeleteCategory)
+				mux.Use(rateLimitMiddleware.Limit(rateLimits.token))
+				mux.Use(permissionMiddleware.RequirePermissions(string(enums.ManageCatalogPermission)))
+				readCategories := abilityMiddleware.RequireAnyAbility(string(enums.AccessTokenAbility), string(enums.CategoriesReadAbility))
+				writeCategories := abilityMiddleware.RequireAnyAbility(string(enums.AccessTokenAbility), string(enums.CategoriesWriteAbility))
+				mux.With(readCategories).Get("/", ch.GetAllCategories)
+				mux.With(readCategories).Get("/{id}", ch.GetCategory)
+				mux.With(writeCategories).Post("/", ch.CreateCategory)
+				mux.With(writeCategories).Put("/{id}", ch.UpdateCategory)
+				mux.With(writeCategories).Delete("/{id}", ch.DeleteCategory)
 			})
 			mux.Route("/products", func(mux chi.Router) {
-				mux.Get("/", ph.GetAllProducts)
-				mux.Get("/{id}", ph.GetProduct)
-				mux.Post("/", ph.CreateProduct)
-				mux.Put("/{id}", ph.UpdateProduct)
-				mux.Delete("/{id}", ph.DeleteProduct)
+				mux.Use(rateLimitMiddleware.Limit(rateLimits.token))
+				mux.Use(permissionMiddleware.RequirePermissions(string(enums.ManageCatalogPermission)))
+				readProducts := abilityMiddleware.RequireAnyAbility(string(enums.AccessTokenAbility), string(enums.ProductsReadAbility))
+				writeProducts := abilityMiddleware.RequireAnyAbility(string(enums.AccessTokenAbility), string(enums.ProductsWriteAbility))
+				mux.With(readProducts).Get("/", ph.GetAllProducts)
+				mux.With(readProducts).Get("/{id}", ph.GetProduct)
+				mux.With(writeProducts).Post("/", ph.CreateProduct)
+				mux.With(writeProducts).Put("/{id}", ph.UpdateProduct)
+				mux.With(writeProducts).Delete("/{id}", ph.DeleteProduct)
 			})
-			mux.Route("/users", func(mux chi.Router) {
-				mux.Get("/user-admins", uh.GetAllUserAdmins)
-				mux.Get("/user-customers", uh.GetAllUserCustomers)
-				mux.Get("/{id}", uh.GetUser)
-				mux.Delete("/{id}", uh.DeleteUser)
+			mux.Group(func(mux chi.Router) {
+				mux.Use(abilityMiddleware.RequireAbilities(string(enums.AccessTokenAbility)))
+				mux.Route("/users", func(mux chi.Router) {
+					mux.Use(permissionMiddleware.RequirePermissions(string(enums.ManageUsersPermission)))
+					mux.Get("/user-admins", uh.GetAllUserAdmins)
+					mux.Get("/user-customers", uh.GetAllUserCustomers)
+					mux.Get("/{id}", uh.GetUser)
+					mux.Delete("/{id}", uh.DeleteUser)
+					mux.Post("/", uh.CreateUser)
+					mux.Put("/{id}", uh.UpdateUser)
+					mux.Put("/{id}/role", uh.UpdateUserRole)
+					mux.Post("/{id}/lock", uh.LockUser)
+					mux.Post("/{id}/unlock", uh.UnlockUser)
+				})
+				mux.With(permissionMiddleware.RequirePermissions(string(enums.ManageUsersPermission))).Get("/login-attempts", lah.GetAllLoginAttempts)
+				mux.Route("/roles", func(mux chi.Router) {
+					mux.Use(permissionMiddleware.RequirePermissions(string(enums.ManageRolesPermission)))
+					mux.Get("/", rh.GetAllRoles)
+					mux.Get("/{id}", rh.GetRole)
+					mux.Post("/", rh.CreateRole)
+					mux.Put("/{id}", rh.UpdateRole)
+					mux.Delete("/{id}", rh.DeleteRole)
+					mux.Post("/{id}/permissions", rh.AttachPermissions)
+					mux.Delete("/{id}/permissions/{permissionId}", rh.DetachPermission)
+				})
+				mux.Route("/permissions", func(mux chi.Router) {
+					mux.Use(permissionMiddleware.RequirePermissions(string(enums.ManageRolesPermission)))
+					mux.Get("/", peh.GetAllPermissions)
+					mux.Get("/{id}", peh.GetPermission)
+					mux.Post("/", peh.CreatePermission)
+					mux.Put("/{id}", peh.UpdatePermission)
+					mux.Delete("/{id}", peh.DeletePermission)
+				})
 			})
 		})
 	})
@@ -0,0 +1,48 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-ms-project-store/internal/core/domain"
+	"github.com/go-ms-project-store/internal/pkg/errs"
+	"github.com/go-ms-project-store/internal/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// FileMailer writes every message as an .eml file so it can be opened
+// locally instead of being delivered
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+func (m FileMailer) Send(ctx context.Context, mail domain.Mail) *errs.AppError {
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		logger.FromContext(ctx).Error("Error while creating mail directory", zap.String("dir", m.dir), zap.Error(err))
+		return errs.NewUnexpectedError("unexpected error sending mail")
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405"), uuid.New().String())
+	path := filepath.Join(m.dir, name)
+
+	if err := os.WriteFile(path, buildMessage(m.from, mail), 0o644); err != nil {
+		logger.FromContext(ctx).Error("Error while writing mail file", zap.String("path", path), zap.Error(err))
+		return errs.NewUnexpectedError("unexpected error sending mail")
+	}
+
+	logger.FromContext(ctx).Info("Mail written", zap.String("path", path), zap.String("subject", mail.Subject))
+
+	return nil
+}
+
+func NewFileMailer(dir, from string) FileMailer {
+	return FileMailer{
+		dir:  dir,
+		from: from,
+	}
+}
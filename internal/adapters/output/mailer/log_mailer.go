@@ -0,0 +1,32 @@
+package mailer
+
+import (
+	"context"
+
+	"github.com/go-ms-project-store/internal/core/domain"
+	"github.com/go-ms-project-store/internal/pkg/errs"
+	"github.com/go-ms-project-store/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// LogMailer writes messages to the application log instead of sending them
+type LogMailer struct {
+	from string
+}
+
+func (m LogMailer) Send(ctx context.Context, mail domain.Mail) *errs.AppError {
+	logger.FromContext(ctx).Info("Mail sent",
+		zap.String("from", m.from),
+		zap.String("to", mail.To),
+		zap.String("subject", mail.Subject),
+		zap.String("body", mail.Body),
+	)
+
+	return nil
+}
+
+func NewLogMailer(from string) LogMailer {
+	return LogMailer{
+		from: from,
+	}
+}
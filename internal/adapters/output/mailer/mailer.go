@@ -0,0 +1,27 @@
+package mailer
+
+import (
+	"strconv"
+
+	"github.com/go-ms-project-store/internal/core/ports"
+	"github.com/go-ms-project-store/internal/pkg/config"
+)
+
+// NewMailer builds the mailer selected by the mail driver (smtp, file or log).
+// The log driver is the default so development setups never send real mail.
+func NewMailer(cfg config.Mail) ports.Mailer {
+	switch cfg.Driver {
+	case "smtp":
+		return NewSMTPMailer(
+			cfg.Host,
+			strconv.Itoa(cfg.Port),
+			cfg.Username,
+			cfg.Password,
+			cfg.From,
+		)
+	case "file":
+		return NewFileMailer(cfg.FilePath, cfg.From)
+	default:
+		return NewLogMailer(cfg.From)
+	}
+}
@@ -0,0 +1,68 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+
+	"github.com/go-ms-project-store/internal/core/domain"
+	"github.com/go-ms-project-store/internal/pkg/errs"
+	"github.com/go-ms-project-store/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+type SMTPMailer struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+func (m SMTPMailer) Send(ctx context.Context, mail domain.Mail) *errs.AppError {
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+
+	err := smtp.SendMail(
+		net.JoinHostPort(m.host, m.port),
+		auth,
+		m.from,
+		[]string{mail.To},
+		buildMessage(m.from, mail),
+	)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while sending mail through SMTP", zap.String("host", m.host), zap.String("subject", mail.Subject), zap.Error(err))
+		return errs.NewUnexpectedError("unexpected error sending mail")
+	}
+
+	return nil
+}
+
+func NewSMTPMailer(host, port, username, password, from string) SMTPMailer {
+	return SMTPMailer{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+// buildMessage renders a plain text RFC 5322 message
+func buildMessage(from string, mail domain.Mail) []byte {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", mail.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mail.Subject)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(mail.Body)
+
+	return []byte(b.String())
+}
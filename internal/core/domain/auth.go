@@ -1,15 +1,19 @@
 package domain
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/go-ms-project-store/internal/adapters/input/http/dto"
+	"github.com/go-ms-project-store/internal/pkg/helpers"
 	"github.com/google/uuid"
 )
 
 type Token struct {
 	ID          uint64    `db:"id"`
 	UserID      uint64    `db:"tokenable_id"`
+	SessionID   uint64    `db:"session_id"`
+	ParentID    uint64    `db:"parent_id"`
 	Name        string    `db:"name"`
 	Token       string    `db:"token"`
 	Abilities   []string  `db:"abilities"`
@@ -17,9 +21,12 @@ type Token struct {
 	UpdatedAt   time.Time `db:"updated_at"`
 	ExpiresAt   time.Time `db:"expires_at"`
 	LastUsedAt  time.Time `db:"last_used_at"`
+	RotatedAt   time.Time `db:"rotated_at"`
 	HashedToken string
 }
 
+type Tokens []Token
+
 type AuthUser struct {
 	Email    string
 	Password string
@@ -35,9 +42,52 @@ type UserRegister struct {
 	UpdatedAt time.Time
 }
 
+// IsRotated reports whether a refresh token has already been exchanged
+func (t Token) IsRotated() bool {
+	return !t.RotatedAt.IsZero()
+}
+
+func (t Token) ToPersonalTokenDTO() dto.PersonalTokenResponse {
+	var lastUsedAt, expiresAt string
+	if !t.LastUsedAt.IsZero() {
+		lastUsedAt = helpers.DatetimeToString(t.LastUsedAt)
+	}
+	if !t.ExpiresAt.IsZero() {
+		expiresAt = helpers.DatetimeToString(t.ExpiresAt)
+	}
+
+	return dto.PersonalTokenResponse{
+		Id:         t.ID,
+		Name:       t.Name,
+		Abilities:  t.Abilities,
+		LastUsedAt: lastUsedAt,
+		ExpiresAt:  expiresAt,
+		CreatedAt:  helpers.DatetimeToString(t.CreatedAt),
+	}
+}
+
+// ToNewPersonalTokenDTO includes the plaintext token, so it must only be
+// used for the response to the request that created it
+func (t Token) ToNewPersonalTokenDTO() dto.NewPersonalTokenResponse {
+	return dto.NewPersonalTokenResponse{
+		PersonalTokenResponse: t.ToPersonalTokenDTO(),
+		Token:                 fmt.Sprintf("%d|%s", t.ID, t.Token),
+	}
+}
+
+func (t Tokens) ToDTO() []dto.PersonalTokenResponse {
+	dtos := make([]dto.PersonalTokenResponse, len(t))
+	for i, token := range t {
+		dtos[i] = token.ToPersonalTokenDTO()
+	}
+	return dtos
+}
+
 func NewToken(dto dto.NewTokenDTO) Token {
 	return Token{
 		UserID:    dto.UserID,
+		SessionID: dto.SessionID,
+		ParentID:  dto.ParentID,
 		Name:      dto.Name,
 		Abilities: dto.Abilities,
 		CreatedAt: time.Now(),
@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// Identity links a user to an account at an external OpenID Connect
+// provider, identified by the provider's subject
+type Identity struct {
+	Id        uint64    `db:"id"`
+	UserId    uint64    `db:"user_id"`
+	Provider  string    `db:"provider"`
+	Subject   string    `db:"subject"`
+	Email     string    `db:"email"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func NewIdentity(userId uint64, provider string, subject string, email string) Identity {
+	return Identity{
+		UserId:    userId,
+		Provider:  provider,
+		Subject:   subject,
+		Email:     email,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
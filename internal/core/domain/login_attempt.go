@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/go-ms-project-store/internal/adapters/input/http/dto"
+	"github.com/go-ms-project-store/internal/pkg/helpers"
+)
+
+// LoginAttempt records the outcome of a login so admins can review
+// suspicious activity
+type LoginAttempt struct {
+	Id         uint64    `db:"id"`
+	UserId     *uint64   `db:"user_id"`
+	Email      string    `db:"email"`
+	IpAddress  string    `db:"ip_address"`
+	UserAgent  string    `db:"user_agent"`
+	Successful bool      `db:"successful"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+type LoginAttempts []LoginAttempt
+
+func NewLoginAttempt(req dto.NewLoginRequest, userId *uint64, successful bool) LoginAttempt {
+	return LoginAttempt{
+		UserId:     userId,
+		Email:      req.Email,
+		IpAddress:  req.IpAddress,
+		UserAgent:  req.UserAgent,
+		Successful: successful,
+		CreatedAt:  time.Now(),
+	}
+}
+
+func (a LoginAttempt) ToLoginAttemptDTO() dto.LoginAttemptResponse {
+	return dto.LoginAttemptResponse{
+		Id:         a.Id,
+		UserId:     a.UserId,
+		Email:      a.Email,
+		IpAddress:  a.IpAddress,
+		UserAgent:  a.UserAgent,
+		Successful: a.Successful,
+		CreatedAt:  helpers.DatetimeToString(a.CreatedAt),
+	}
+}
+
+func (a LoginAttempts) ToDTO() []dto.LoginAttemptResponse {
+	dtos := make([]dto.LoginAttemptResponse, len(a))
+	for i, attempt := range a {
+		dtos[i] = attempt.ToLoginAttemptDTO()
+	}
+	return dtos
+}
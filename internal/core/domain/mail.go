@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+func NewVerificationMail(user User, link string) Mail {
+	return Mail{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Hello %s,\r\n\r\nPlease confirm your email address by opening the link below:\r\n\r\n%s\r\n\r\nIf you did not create an account, no further action is required.\r\n",
+			user.Name,
+			link,
+		),
+	}
+}
+
+func NewPasswordResetMail(user User, link string, lifetime time.Duration) Mail {
+	return Mail{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hello %s,\r\n\r\nWe received a request to reset your password. Open the link below to choose a new one:\r\n\r\n%s\r\n\r\nThe link expires in %s and can only be used once. If you did not request a password reset, no further action is required.\r\n",
+			user.Name,
+			link,
+			formatLifetime(lifetime),
+		),
+	}
+}
+
+// formatLifetime writes a duration the way it is read in an email, e.g.
+// "30 minutes" or "1 hour"
+func formatLifetime(d time.Duration) string {
+	value, unit := int64(d/time.Minute), "minute"
+	if d >= time.Hour && d%time.Hour == 0 {
+		value, unit = int64(d/time.Hour), "hour"
+	}
+
+	if value == 1 {
+		return "1 " + unit
+	}
+
+	return fmt.Sprintf("%d %ss", value, unit)
+}
@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatLifetime(t *testing.T) {
+	tests := map[time.Duration]string{
+		time.Minute:      "1 minute",
+		30 * time.Minute: "30 minutes",
+		time.Hour:        "1 hour",
+		90 * time.Minute: "90 minutes",
+		24 * time.Hour:   "24 hours",
+	}
+
+	for lifetime, want := range tests {
+		if got := formatLifetime(lifetime); got != want {
+			t.Errorf("formatLifetime(%s) = %q, want %q", lifetime, got, want)
+		}
+	}
+}
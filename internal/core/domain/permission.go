@@ -20,6 +20,14 @@ type Permissions []Permission
 type PermissionRepository interface {
 }
 
+func NewPermission(req dto.NewPermissionRequest) Permission {
+	return Permission{
+		Name:      req.Name,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
 func (u Permission) ToPermissionDTO() dto.PermissionResponse {
 	return dto.PermissionResponse{
 		Id:        u.Id,
@@ -4,15 +4,19 @@ import (
 	"time"
 
 	"github.com/go-ms-project-store/internal/adapters/input/http/dto"
+	"github.com/go-ms-project-store/internal/core/enums"
 	"github.com/go-ms-project-store/internal/pkg/helpers"
 )
 
 type Role struct {
-	Id          int64     `db:"id"`
-	Name        string    `db:"name"`
-	CreatedAt   time.Time `db:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at"`
-	Permissions []Permission
+	Id   int64  `db:"id"`
+	Name string `db:"name"`
+	// RequiresTwoFactor blocks admin routes for users of the role until
+	// they have enabled two-factor authentication
+	RequiresTwoFactor bool      `db:"requires_two_factor"`
+	CreatedAt         time.Time `db:"created_at"`
+	UpdatedAt         time.Time `db:"updated_at"`
+	Permissions       []Permission
 }
 
 type Roles []Role
@@ -20,6 +24,36 @@ type Roles []Role
 type RoleRepository interface {
 }
 
+func NewRole(req dto.NewRoleRequest) Role {
+	return Role{
+		Name:              req.Name,
+		RequiresTwoFactor: req.RequiresTwoFactor,
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
+	}
+}
+
+// IsBuiltIn reports whether the role is one the application depends on
+// and therefore cannot be renamed or deleted
+func (u Role) IsBuiltIn() bool {
+	return u.Name == string(enums.AdminRole) || u.Name == string(enums.CustomerRole)
+}
+
+// HasPermission reports whether the role grants the given permission.
+// The admin role is granted every permission.
+func (u Role) HasPermission(name string) bool {
+	if u.Name == string(enums.AdminRole) {
+		return true
+	}
+
+	for _, permission := range u.Permissions {
+		if permission.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 func (u Role) ToRoleDTO() dto.RoleResponse {
 	permissions := make([]dto.PermissionResponse, len(u.Permissions))
 	for i, permission := range u.Permissions {
@@ -27,11 +61,12 @@ func (u Role) ToRoleDTO() dto.RoleResponse {
 	}
 
 	return dto.RoleResponse{
-		Id:          u.Id,
-		Name:        u.Name,
-		CreatedAt:   helpers.DatetimeToString(u.CreatedAt),
-		UpdatedAt:   helpers.DatetimeToString(u.UpdatedAt),
-		Permissions: permissions,
+		Id:                u.Id,
+		Name:              u.Name,
+		RequiresTwoFactor: u.RequiresTwoFactor,
+		CreatedAt:         helpers.DatetimeToString(u.CreatedAt),
+		UpdatedAt:         helpers.DatetimeToString(u.UpdatedAt),
+		Permissions:       permissions,
 	}
 }
 
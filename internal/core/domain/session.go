@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/go-ms-project-store/internal/adapters/input/http/dto"
+	"github.com/go-ms-project-store/internal/pkg/helpers"
+)
+
+// Session groups the access and refresh tokens issued by a single login,
+// so each device can be listed and signed out on its own
+type Session struct {
+	Id         uint64     `db:"id"`
+	UserId     uint64     `db:"user_id"`
+	DeviceName string     `db:"device_name"`
+	UserAgent  string     `db:"user_agent"`
+	IpAddress  string     `db:"ip_address"`
+	LastUsedAt *time.Time `db:"last_used_at"`
+	CreatedAt  time.Time  `db:"created_at"`
+	UpdatedAt  time.Time  `db:"updated_at"`
+}
+
+type Sessions []Session
+
+func NewSession(userId uint64, req dto.NewLoginRequest) Session {
+	deviceName := req.DeviceName
+	if deviceName == "" {
+		deviceName = "Unknown device"
+	}
+
+	return Session{
+		UserId:     userId,
+		DeviceName: deviceName,
+		UserAgent:  req.UserAgent,
+		IpAddress:  req.IpAddress,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+}
+
+func (s Session) ToSessionDTO(currentId uint64) dto.SessionResponse {
+	var lastUsedAt string
+	if s.LastUsedAt != nil {
+		lastUsedAt = helpers.DatetimeToString(*s.LastUsedAt)
+	}
+
+	return dto.SessionResponse{
+		Id:         s.Id,
+		DeviceName: s.DeviceName,
+		UserAgent:  s.UserAgent,
+		IpAddress:  s.IpAddress,
+		LastUsedAt: lastUsedAt,
+		CreatedAt:  helpers.DatetimeToString(s.CreatedAt),
+		Current:    s.Id == currentId,
+	}
+}
+
+func (s Sessions) ToDTO(currentId uint64) []dto.SessionResponse {
+	dtos := make([]dto.SessionResponse, len(s))
+	for i, session := range s {
+		dtos[i] = session.ToSessionDTO(currentId)
+	}
+	return dtos
+}
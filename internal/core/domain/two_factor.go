@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"time"
+)
+
+// TwoFactor holds a user's TOTP enrollment. The secret is stored encrypted
+// and enrollment only takes effect once it has been confirmed with a code.
+type TwoFactor struct {
+	UserId       uint64     `db:"user_id"`
+	Secret       string     `db:"secret"`
+	LastUsedStep *int64     `db:"last_used_step"`
+	ConfirmedAt  *time.Time `db:"confirmed_at"`
+	CreatedAt    time.Time  `db:"created_at"`
+	UpdatedAt    time.Time  `db:"updated_at"`
+}
+
+func NewTwoFactor(userId uint64, encryptedSecret string) TwoFactor {
+	return TwoFactor{
+		UserId:    userId,
+		Secret:    encryptedSecret,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+func (t TwoFactor) IsConfirmed() bool {
+	return t.ConfirmedAt != nil
+}
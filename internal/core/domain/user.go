@@ -9,28 +9,64 @@ import (
 )
 
 type User struct {
-	Id              int64     `db:"id"`
-	UUID            uuid.UUID `db:"uuid"`
-	Name            string    `db:"name"`
-	Email           string    `db:"email"`
-	Password        string    `db:"password"`
-	RoleId          int64     `db:"role_id"`
-	EmailVerifiedAt time.Time `db:"email_verified_at"`
-	CreatedAt       time.Time `db:"created_at"`
-	UpdatedAt       time.Time `db:"updated_at"`
+	Id              int64      `db:"id"`
+	UUID            uuid.UUID  `db:"uuid"`
+	Name            string     `db:"name"`
+	Email           string     `db:"email"`
+	Password        string     `db:"password"`
+	RoleId          int64      `db:"role_id"`
+	EmailVerifiedAt *time.Time `db:"email_verified_at"`
+	LockedAt        *time.Time `db:"locked_at"`
+	CreatedAt       time.Time  `db:"created_at"`
+	UpdatedAt       time.Time  `db:"updated_at"`
 	Role            Role
 }
 
 type Users []User
 
+func NewUser(req dto.NewUserRequest) UserRegister {
+	return UserRegister{
+		Email:     req.Email,
+		Password:  req.Password,
+		Name:      req.Name,
+		RoleId:    uint64(req.RoleId),
+		UUID:      uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+func (u User) IsLocked() bool {
+	return u.LockedAt != nil
+}
+
+func (u User) HasVerifiedEmail() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+func (u User) emailVerifiedAt() *string {
+	if u.EmailVerifiedAt == nil {
+		return nil
+	}
+
+	verifiedAt := helpers.DatetimeToString(*u.EmailVerifiedAt)
+	return &verifiedAt
+}
+
 func (u User) ToUserDTO() dto.UserResponse {
+	var lockedAt string
+	if u.LockedAt != nil {
+		lockedAt = helpers.DatetimeToString(*u.LockedAt)
+	}
+
 	return dto.UserResponse{
 		Id:              u.Id,
 		UUID:            u.UUID,
 		Name:            u.Name,
 		Email:           u.Email,
 		RoleId:          u.RoleId,
-		EmailVerifiedAt: helpers.DatetimeToString(u.EmailVerifiedAt),
+		EmailVerifiedAt: u.emailVerifiedAt(),
+		LockedAt:        lockedAt,
 		CreatedAt:       helpers.DatetimeToString(u.CreatedAt),
 		UpdatedAt:       helpers.DatetimeToString(u.UpdatedAt),
 		Role: dto.RoleResponse{
@@ -50,9 +86,10 @@ func (u Users) ToDTO() []dto.UserResponse {
 
 func (u User) ToMeDTO() dto.UserMeResponse {
 	return dto.UserMeResponse{
-		ID:        u.UUID,
-		Name:      u.Name,
-		Email:     u.Email,
-		CreatedAt: helpers.DatetimeToString(u.CreatedAt),
+		ID:              u.UUID,
+		Name:            u.Name,
+		Email:           u.Email,
+		EmailVerifiedAt: u.emailVerifiedAt(),
+		CreatedAt:       helpers.DatetimeToString(u.CreatedAt),
 	}
 }
@@ -0,0 +1,9 @@
+package enums
+
+type Permission string
+
+const (
+	ManageCatalogPermission Permission = "manage-catalog"
+	ManageRolesPermission   Permission = "manage-roles"
+	ManageUsersPermission   Permission = "manage-users"
+)
@@ -2,4 +2,6 @@ package enums
 
 type RouteContextKey string
 
+// RoutePatternKey holds the request path stored by the StoreRoutePattern
+// middleware
 const RoutePatternKey RouteContextKey = "routePattern"
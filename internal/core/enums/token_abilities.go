@@ -1,6 +1,97 @@
 package enums
 
+import (
+	"slices"
+	"strings"
+)
+
 const (
 	AccessTokenAbility  TokenName = "access-token"
 	RefreshTokenAbility TokenName = "issue-access-token"
+	// TwoFactorPendingAbility only allows completing a two-factor login
+	TwoFactorPendingAbility TokenName = "2fa-pending"
+
+	CategoriesReadAbility  TokenName = "categories:read"
+	CategoriesWriteAbility TokenName = "categories:write"
+	OrdersCreateAbility    TokenName = "orders:create"
+	ProductsReadAbility    TokenName = "products:read"
+	ProductsWriteAbility   TokenName = "products:write"
 )
+
+// WildcardAbility grants every ability of a personal API token
+const WildcardAbility TokenName = "*"
+
+// PersonalTokenAbilities is the registry of abilities users can grant to
+// personal API tokens. Login tokens are never issued with these.
+var PersonalTokenAbilities = []TokenName{
+	CategoriesReadAbility,
+	CategoriesWriteAbility,
+	OrdersCreateAbility,
+	ProductsReadAbility,
+	ProductsWriteAbility,
+}
+
+// abilityPermissions lists the role permission each ability needs. A token
+// can't do more than its owner, so abilities without the permission aren't
+// granted.
+var abilityPermissions = map[TokenName]Permission{
+	CategoriesReadAbility:  ManageCatalogPermission,
+	CategoriesWriteAbility: ManageCatalogPermission,
+	ProductsReadAbility:    ManageCatalogPermission,
+	ProductsWriteAbility:   ManageCatalogPermission,
+}
+
+// RequiredPermissions returns the permissions needed for every registered
+// ability the given one grants, which may be a wildcard
+func RequiredPermissions(ability string) []Permission {
+	var permissions []Permission
+
+	for _, registered := range PersonalTokenAbilities {
+		permission, ok := abilityPermissions[registered]
+		if ok && AbilityGrants(ability, string(registered)) && !slices.Contains(permissions, permission) {
+			permissions = append(permissions, permission)
+		}
+	}
+
+	return permissions
+}
+
+// IsPersonalTokenAbility reports whether the ability, which may be a wildcard
+// such as "products:*", grants at least one registered ability
+func IsPersonalTokenAbility(ability string) bool {
+	for _, registered := range PersonalTokenAbilities {
+		if AbilityGrants(ability, string(registered)) {
+			return true
+		}
+	}
+	return false
+}
+
+// AbilityGrants reports whether a granted ability covers the required one.
+// "*" covers every registered ability and "products:*" every "products:"
+// ability; login token abilities only ever match themselves.
+func AbilityGrants(granted string, required string) bool {
+	if granted == required {
+		return true
+	}
+
+	if !isRegistered(required) {
+		return false
+	}
+
+	if granted == string(WildcardAbility) {
+		return true
+	}
+
+	prefix, found := strings.CutSuffix(granted, "*")
+	return found && strings.HasSuffix(prefix, ":") && strings.HasPrefix(required, prefix)
+}
+
+func isRegistered(ability string) bool {
+	for _, registered := range PersonalTokenAbilities {
+		if string(registered) == ability {
+			return true
+		}
+	}
+	return false
+}
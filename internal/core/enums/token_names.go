@@ -5,4 +5,6 @@ type TokenName string
 const (
 	AccessToken  TokenName = "access_token"
 	RefreshToken TokenName = "refresh_token"
+
+	TwoFactorChallengeToken TokenName = "two_factor_challenge"
 )
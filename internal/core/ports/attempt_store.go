@@ -0,0 +1,21 @@
+package ports
+
+import (
+	"time"
+
+	"github.com/go-ms-project-store/internal/pkg/errs"
+)
+
+// AttemptStore keeps failed login attempts and lockouts per key, such as an
+// email address or an IP. Shared implementations let several instances
+// enforce the same limits.
+type AttemptStore interface {
+	// BlockedFor returns how long the key stays blocked, zero when it isn't
+	BlockedFor(key string) (time.Duration, *errs.AppError)
+	// Block rejects attempts for the key for the given duration
+	Block(key string, duration time.Duration) *errs.AppError
+	// Fail records a failed attempt, remembered for ttl after the latest
+	// one, and returns the number of failures so far
+	Fail(key string, ttl time.Duration) (int, *errs.AppError)
+	Reset(key string) *errs.AppError
+}
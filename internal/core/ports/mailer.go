@@ -0,0 +1,12 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/go-ms-project-store/internal/core/domain"
+	"github.com/go-ms-project-store/internal/pkg/errs"
+)
+
+type Mailer interface {
+	Send(context.Context, domain.Mail) *errs.AppError
+}
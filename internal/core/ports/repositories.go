@@ -1,61 +1,135 @@
 package ports
 
 import (
+	"context"
+	"time"
+
 	"github.com/go-ms-project-store/internal/core/domain"
 	"github.com/go-ms-project-store/internal/pkg/errs"
 	"github.com/go-ms-project-store/internal/pkg/pagination"
 )
 
 type AuthRepository interface {
-	CreateAccessToken(domain.Token) (*domain.Token, *errs.AppError)
-	CreateRefreshToken(domain.Token) (*domain.Token, *errs.AppError)
-	ValidateToken(string) (uint64, *errs.AppError)
-	Login(domain.AuthUser) (*domain.User, *errs.AppError)
-	Logout(uint64) *errs.AppError
+	ConsumePasswordResetToken(context.Context, string) (uint64, *errs.AppError)
+	CreateAccessToken(context.Context, domain.Token) (*domain.Token, *errs.AppError)
+	CreateChallengeToken(context.Context, domain.Token) (*domain.Token, *errs.AppError)
+	CreatePasswordResetToken(context.Context, uint64, time.Time) (string, *errs.AppError)
+	CreatePersonalAccessToken(context.Context, domain.Token) (*domain.Token, *errs.AppError)
+	CreateRefreshToken(context.Context, domain.Token) (*domain.Token, *errs.AppError)
+	DeleteChallengeToken(context.Context, uint64, uint64) *errs.AppError
+	DeleteExpiredTokens(context.Context, time.Time, int) (int64, *errs.AppError)
+	DeletePersonalAccessToken(context.Context, uint64, uint64) *errs.AppError
+	FindPersonalAccessTokens(context.Context, uint64) (domain.Tokens, *errs.AppError)
+	GetTokenAbilities(context.Context, string) ([]string, *errs.AppError)
+	IdentityRepo() IdentityRepository
+	ValidateToken(context.Context, string) (*domain.Token, *errs.AppError)
+	Login(context.Context, domain.AuthUser) (*domain.User, *errs.AppError)
+	LoginAttemptRepo() LoginAttemptRepository
 	UserRepo() UserRepository
 	RoleRepo() RoleRepository
-	Register(domain.UserRegister) (*domain.User, *errs.AppError)
-	RevokeAccessToken(uint64) *errs.AppError
+	Register(context.Context, domain.UserRegister) (*domain.User, *errs.AppError)
+	RevokeAccessToken(context.Context, uint64, uint64) *errs.AppError
+	RevokeRefreshToken(context.Context, uint64, uint64) *errs.AppError
+	RotateRefreshToken(context.Context, uint64, domain.Token) (*domain.Token, *errs.AppError)
+	SessionRepo() SessionRepository
+	TwoFactorRepo() TwoFactorRepository
+	VerifyPassword(context.Context, uint64, string) *errs.AppError
 }
 
 type CategoryRepository interface {
-	Create(domain.Category) (*domain.Category, *errs.AppError)
-	Delete(int) *errs.AppError
-	FindAll(pagination.DataDBFilter) (domain.Categories, int64, *errs.AppError)
-	FindById(int) (*domain.Category, *errs.AppError)
-	Update(domain.Category) (*domain.Category, *errs.AppError)
+	Create(context.Context, domain.Category) (*domain.Category, *errs.AppError)
+	Delete(context.Context, int) *errs.AppError
+	FindAll(context.Context, pagination.DataDBFilter) (domain.Categories, int64, *errs.AppError)
+	FindById(context.Context, int) (*domain.Category, *errs.AppError)
+	Update(context.Context, domain.Category) (*domain.Category, *errs.AppError)
+}
+
+type IdentityRepository interface {
+	Create(context.Context, domain.Identity) (*domain.Identity, *errs.AppError)
+	FindByProviderSubject(context.Context, string, string) (*domain.Identity, *errs.AppError)
+}
+
+type LoginAttemptRepository interface {
+	Create(context.Context, domain.LoginAttempt) *errs.AppError
+	FindAll(context.Context, pagination.DataDBFilter, string) (domain.LoginAttempts, int64, *errs.AppError)
 }
 
 type OrderRepository interface {
-	Create(domain.Order) (*domain.Order, *errs.AppError)
-	FindById(uint64) (*domain.Order, *errs.AppError)
+	Create(context.Context, domain.Order) (*domain.Order, *errs.AppError)
+	FindById(context.Context, uint64) (*domain.Order, *errs.AppError)
+	RecomputeTotals(context.Context) (int64, *errs.AppError)
 	ProductRepo() ProductRepository
 	OrderItemRepo() OrderItemRepository
 }
 
 type OrderItemRepository interface {
-	Create(domain.OrderItem) (*domain.OrderItem, *errs.AppError)
+	Create(context.Context, domain.OrderItem) (*domain.OrderItem, *errs.AppError)
 }
 
 type ProductRepository interface {
-	Create(domain.Product) (*domain.Product, *errs.AppError)
-	Delete(int) *errs.AppError
-	FindAll(pagination.DataDBFilter) (domain.Products, int64, *errs.AppError)
-	FindById(int) (*domain.Product, *errs.AppError)
-	FindBySlug(string) (*domain.Product, *errs.AppError)
-	Update(domain.Product) (*domain.Product, *errs.AppError)
-	WhereIn([]string) ([]domain.Product, *errs.AppError)
+	Create(context.Context, domain.Product) (*domain.Product, *errs.AppError)
+	Delete(context.Context, int) *errs.AppError
+	FindAll(context.Context, pagination.DataDBFilter) (domain.Products, int64, *errs.AppError)
+	FindById(context.Context, int) (*domain.Product, *errs.AppError)
+	FindBySlug(context.Context, string) (*domain.Product, *errs.AppError)
+	Update(context.Context, domain.Product) (*domain.Product, *errs.AppError)
+	WhereIn(context.Context, []string) ([]domain.Product, *errs.AppError)
+}
+
+type PermissionRepository interface {
+	Create(context.Context, domain.Permission) (*domain.Permission, *errs.AppError)
+	Delete(context.Context, int) *errs.AppError
+	FindAll(context.Context, pagination.DataDBFilter) (domain.Permissions, int64, *errs.AppError)
+	FindById(context.Context, int) (*domain.Permission, *errs.AppError)
+	Update(context.Context, domain.Permission) (*domain.Permission, *errs.AppError)
 }
 
 type RoleRepository interface {
-	FindByName(string) (*domain.Role, *errs.AppError)
+	AttachPermissions(context.Context, int64, []int64) *errs.AppError
+	CountUsers(context.Context, int64) (int64, *errs.AppError)
+	Create(context.Context, domain.Role) (*domain.Role, *errs.AppError)
+	Delete(context.Context, int) *errs.AppError
+	DetachPermission(context.Context, int64, int64) *errs.AppError
+	FindAll(context.Context, pagination.DataDBFilter) (domain.Roles, int64, *errs.AppError)
+	FindById(context.Context, int) (*domain.Role, *errs.AppError)
+	FindByName(context.Context, string) (*domain.Role, *errs.AppError)
+	FindByUserId(context.Context, uint64) (*domain.Role, *errs.AppError)
+	Update(context.Context, domain.Role) (*domain.Role, *errs.AppError)
+}
+
+type SessionRepository interface {
+	Create(context.Context, domain.Session) (*domain.Session, *errs.AppError)
+	Delete(context.Context, uint64, uint64) *errs.AppError
+	DeleteAll(context.Context, uint64) *errs.AppError
+	DeleteOthers(context.Context, uint64, uint64) *errs.AppError
+	FindAllByUser(context.Context, uint64) (domain.Sessions, *errs.AppError)
+}
+
+type TwoFactorRepository interface {
+	Confirm(context.Context, uint64, int64, []string) *errs.AppError
+	Delete(context.Context, uint64) *errs.AppError
+	FindByUserId(context.Context, uint64) (*domain.TwoFactor, *errs.AppError)
+	MarkStepUsed(context.Context, uint64, int64) *errs.AppError
+	ReplaceRecoveryCodes(context.Context, uint64, []string) *errs.AppError
+	Save(context.Context, domain.TwoFactor) *errs.AppError
+	UseRecoveryCode(context.Context, uint64, string) *errs.AppError
 }
 
 type UserRepository interface {
-	Delete(string) *errs.AppError
-	FindAll(pagination.DataDBFilter, string) (domain.Users, int64, *errs.AppError)
-	FindAllAdmins(pagination.DataDBFilter) (domain.Users, int64, *errs.AppError)
-	FindAllCustomers(pagination.DataDBFilter) (domain.Users, int64, *errs.AppError)
-	FindById(uint64) (*domain.User, *errs.AppError)
-	FindByUuid(string) (*domain.User, *errs.AppError)
+	Anonymize(context.Context, uint64) *errs.AppError
+	CountUnlockedByRole(context.Context, string) (int64, *errs.AppError)
+	Create(context.Context, domain.UserRegister) (*domain.User, *errs.AppError)
+	Delete(context.Context, string) *errs.AppError
+	FindAll(context.Context, pagination.DataDBFilter, string) (domain.Users, int64, *errs.AppError)
+	FindAllAdmins(context.Context, pagination.DataDBFilter) (domain.Users, int64, *errs.AppError)
+	FindAllCustomers(context.Context, pagination.DataDBFilter) (domain.Users, int64, *errs.AppError)
+	FindByEmail(context.Context, string) (*domain.User, *errs.AppError)
+	FindById(context.Context, uint64) (*domain.User, *errs.AppError)
+	FindByUuid(context.Context, string) (*domain.User, *errs.AppError)
+	MarkEmailVerified(context.Context, uint64, string) *errs.AppError
+	RoleRepo() RoleRepository
+	SetLocked(context.Context, string, bool) *errs.AppError
+	Update(context.Context, domain.User) (*domain.User, *errs.AppError)
+	UpdatePassword(context.Context, uint64, string) *errs.AppError
+	UpdateRole(context.Context, string, int64) *errs.AppError
 }
@@ -1,6 +1,7 @@
 package ports
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/go-ms-project-store/internal/adapters/input/http/dto"
@@ -10,38 +11,97 @@ import (
 )
 
 type AuthService interface {
-	Login(dto.NewLoginRequest) (*dto.TokenResponse, *errs.AppError)
-	Logout(uint64) *errs.AppError
-	Me(uint64) (*domain.User, *errs.AppError)
-	RefreshToken(uint64) (*dto.TokenResponse, *errs.AppError)
-	Register(dto.NewUserRegisterRequest) (*domain.User, *errs.AppError)
+	ChangePassword(context.Context, uint64, uint64, dto.ChangePasswordRequest) *errs.AppError
+	CreatePersonalToken(context.Context, uint64, dto.NewPersonalTokenRequest) (*domain.Token, *errs.AppError)
+	DeleteMe(context.Context, uint64, dto.DeleteMeRequest) *errs.AppError
+	ForgotPassword(context.Context, dto.ForgotPasswordRequest) *errs.AppError
+	PruneExpiredTokens(context.Context, int) (int64, *errs.AppError)
+	GetPersonalTokens(context.Context, uint64) (domain.Tokens, *errs.AppError)
+	GetSessions(context.Context, uint64) (domain.Sessions, *errs.AppError)
+	Login(context.Context, dto.NewLoginRequest) (*dto.TokenResponse, *errs.AppError)
+	Logout(context.Context, uint64, uint64) *errs.AppError
+	Me(context.Context, uint64) (*domain.User, *errs.AppError)
+	RefreshToken(context.Context, uint64, uint64, uint64) (*dto.TokenResponse, *errs.AppError)
+	Register(context.Context, dto.NewUserRegisterRequest) (*domain.User, *errs.AppError)
+	ResendVerificationEmail(context.Context, uint64) *errs.AppError
+	ResetPassword(context.Context, dto.ResetPasswordRequest) *errs.AppError
+	RevokeAllTokens(context.Context, uint64) *errs.AppError
+	RevokeOtherSessions(context.Context, uint64, uint64) *errs.AppError
+	RevokePersonalToken(context.Context, uint64, uint64) *errs.AppError
+	RevokeSession(context.Context, uint64, uint64) *errs.AppError
+	SetPassword(context.Context, uint64, dto.SetPasswordRequest) *errs.AppError
+	UpdateMe(context.Context, uint64, dto.UpdateMeRequest) (*domain.User, *errs.AppError)
+	VerifyEmail(context.Context, string) *errs.AppError
+	VerifyTwoFactor(context.Context, uint64, uint64, dto.VerifyTwoFactorRequest) (*dto.TokenResponse, *errs.AppError)
 }
 
 type CategoryService interface {
 	GetAllCategories(*http.Request) (domain.Categories, int64, pagination.DataDBFilter, *errs.AppError)
-	CreateCategory(dto.NewCategoryRequest) (*domain.Category, *errs.AppError)
-	FindCategoryById(int) (*domain.Category, *errs.AppError)
-	DeleteCategory(int) (bool, *errs.AppError)
-	UpdateCategory(int64, dto.UpdateCategoryRequest) (*domain.Category, *errs.AppError)
+	CreateCategory(context.Context, dto.NewCategoryRequest) (*domain.Category, *errs.AppError)
+	FindCategoryById(context.Context, int) (*domain.Category, *errs.AppError)
+	DeleteCategory(context.Context, int) (bool, *errs.AppError)
+	UpdateCategory(context.Context, int64, dto.UpdateCategoryRequest) (*domain.Category, *errs.AppError)
+}
+
+type LoginAttemptService interface {
+	GetAllLoginAttempts(*http.Request) (domain.LoginAttempts, int64, pagination.DataDBFilter, *errs.AppError)
+}
+
+type OIDCService interface {
+	Authorize(context.Context, string) (*dto.OIDCAuthorizationResponse, *errs.AppError)
+	Callback(context.Context, string, dto.OIDCCallbackRequest) (*dto.TokenResponse, *errs.AppError)
 }
 
 type OrderService interface {
-	CreateOrder(dto.NewOrderRequest, uint64) (*domain.Order, *errs.AppError)
+	CreateOrder(context.Context, dto.NewOrderRequest, uint64) (*domain.Order, *errs.AppError)
+	RecomputeTotals(context.Context) (int64, *errs.AppError)
+}
+
+type PermissionService interface {
+	GetAllPermissions(*http.Request) (domain.Permissions, int64, pagination.DataDBFilter, *errs.AppError)
+	CreatePermission(context.Context, dto.NewPermissionRequest) (*domain.Permission, *errs.AppError)
+	FindPermissionById(context.Context, int) (*domain.Permission, *errs.AppError)
+	DeletePermission(context.Context, int) (bool, *errs.AppError)
+	UpdatePermission(context.Context, int64, dto.UpdatePermissionRequest) (*domain.Permission, *errs.AppError)
 }
 
 type ProductService interface {
 	GetAllProducts(*http.Request) (domain.Products, int64, pagination.DataDBFilter, *errs.AppError)
-	CreateProduct(dto.NewProductRequest) (*domain.Product, *errs.AppError)
-	FindProductById(int) (*domain.Product, *errs.AppError)
-	FindProductBySlug(string) (*domain.Product, *errs.AppError)
-	DeleteProduct(int) (bool, *errs.AppError)
-	UpdateProduct(int64, dto.UpdateProductRequest) (*domain.Product, *errs.AppError)
+	CreateProduct(context.Context, dto.NewProductRequest) (*domain.Product, *errs.AppError)
+	FindProductById(context.Context, int) (*domain.Product, *errs.AppError)
+	FindProductBySlug(context.Context, string) (*domain.Product, *errs.AppError)
+	DeleteProduct(context.Context, int) (bool, *errs.AppError)
+	UpdateProduct(context.Context, int64, dto.UpdateProductRequest) (*domain.Product, *errs.AppError)
+}
+
+type RoleService interface {
+	GetAllRoles(*http.Request) (domain.Roles, int64, pagination.DataDBFilter, *errs.AppError)
+	CreateRole(context.Context, dto.NewRoleRequest) (*domain.Role, *errs.AppError)
+	FindRoleById(context.Context, int) (*domain.Role, *errs.AppError)
+	DeleteRole(context.Context, int) (bool, *errs.AppError)
+	UpdateRole(context.Context, int64, dto.UpdateRoleRequest) (*domain.Role, *errs.AppError)
+	AttachPermissions(context.Context, int64, dto.RolePermissionsRequest) (*domain.Role, *errs.AppError)
+	DetachPermission(context.Context, int64, int64) (*domain.Role, *errs.AppError)
+	GetUserRole(context.Context, uint64) (*domain.Role, *errs.AppError)
+}
+
+type TwoFactorService interface {
+	ConfirmTwoFactor(context.Context, uint64, dto.ConfirmTwoFactorRequest) ([]string, *errs.AppError)
+	DisableTwoFactor(context.Context, uint64, dto.DisableTwoFactorRequest) *errs.AppError
+	EnableTwoFactor(context.Context, uint64) (*dto.TwoFactorSetupResponse, *errs.AppError)
+	IsTwoFactorEnabled(context.Context, uint64) (bool, *errs.AppError)
+	RegenerateRecoveryCodes(context.Context, uint64, dto.ConfirmTwoFactorRequest) ([]string, *errs.AppError)
 }
 
 type UserService interface {
 	GetAllUserCustomers(*http.Request) (domain.Users, int64, pagination.DataDBFilter, *errs.AppError)
 	GetAllUserAdmins(*http.Request) (domain.Users, int64, pagination.DataDBFilter, *errs.AppError)
 	// GetAllUsers(*http.Request) (domain.Users, int64, pagination.DataDBFilter, *errs.AppError)
-	FindUserById(string) (*domain.User, *errs.AppError)
-	DeleteUser(string) (bool, *errs.AppError)
+	FindUserById(context.Context, string) (*domain.User, *errs.AppError)
+	DeleteUser(context.Context, string) (bool, *errs.AppError)
+	CreateUser(context.Context, dto.NewUserRequest) (*domain.User, *errs.AppError)
+	UpdateUser(context.Context, string, dto.UpdateUserRequest) (*domain.User, *errs.AppError)
+	LockUser(context.Context, string) (*domain.User, *errs.AppError)
+	UnlockUser(context.Context, string) (*domain.User, *errs.AppError)
+	UpdateUserRole(context.Context, string, dto.UpdateUserRoleRequest) (*domain.User, *errs.AppError)
 }
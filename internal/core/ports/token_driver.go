@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"context"
+	"github.com/go-ms-project-store/internal/core/domain"
+	"github.com/go-ms-project-store/internal/pkg/errs"
+)
+
+// TokenDriver issues and validates access tokens. Refresh tokens are always
+// opaque and stored in the database, whichever driver is configured.
+type TokenDriver interface {
+	GetTokenAbilities(context.Context, string) ([]string, *errs.AppError)
+	IssueAccessToken(context.Context, domain.Token) (string, *errs.AppError)
+	RevokeSessions(uint64, ...uint64)
+	RevokeUser(uint64)
+	ValidateToken(context.Context, string) (*domain.Token, *errs.AppError)
+}
@@ -1,9 +1,9 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
-	"fmt"
 	"time"
 
 	"github.com/go-ms-project-store/internal/core/domain"
@@ -14,19 +14,28 @@ import (
 	"github.com/go-ms-project-store/internal/pkg/logger"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// lastUsedAtResolution is how stale last_used_at may get before a request
+// writes it again
+const lastUsedAtResolution = time.Minute
+
 type AuthRepositoryDB struct {
-	client   *sqlx.DB
-	userRepo ports.UserRepository
-	roleRepo ports.RoleRepository
+	client           *sqlx.DB
+	identityRepo     ports.IdentityRepository
+	loginAttemptRepo ports.LoginAttemptRepository
+	userRepo         ports.UserRepository
+	roleRepo         ports.RoleRepository
+	sessionRepo      ports.SessionRepository
+	twoFactorRepo    ports.TwoFactorRepository
 }
 
-func (rdb AuthRepositoryDB) createToken(tokenType string, au domain.Token) (*domain.Token, *errs.AppError) {
+func (rdb AuthRepositoryDB) createToken(ctx context.Context, exec sqlx.ExecerContext, tokenType string, au domain.Token) (*domain.Token, *errs.AppError) {
 	genToken, err := helpers.GenerateToken()
 	if err != nil {
-		logger.Error("Error while generating token " + err.Error())
+		logger.FromContext(ctx).Error("Error while generating token", zap.Error(err))
 		return nil, errs.NewUnexpectedError("unexpected database error")
 	}
 
@@ -40,56 +49,406 @@ func (rdb AuthRepositoryDB) createToken(tokenType string, au domain.Token) (*dom
 		var err error
 		abilitiesJSON, err = json.Marshal(au.Abilities)
 		if err != nil {
-			logger.Error("Error while converting abilities to JSON " + err.Error())
+			logger.FromContext(ctx).Error("Error while converting abilities to JSON", zap.Error(err))
 			return nil, errs.NewUnexpectedError("unexpected error processing token data")
 		}
 	}
 
+	// Tokens issued outside of a login session are stored without one,
+	// only rotated refresh tokens have a parent and personal tokens may
+	// never expire
+	var sessionID, parentID, expiresAt interface{}
+	if au.SessionID != 0 {
+		sessionID = au.SessionID
+	}
+	if au.ParentID != 0 {
+		parentID = au.ParentID
+	}
+	if !au.ExpiresAt.IsZero() {
+		expiresAt = au.ExpiresAt
+	}
+
 	query := `INSERT INTO personal_access_tokens 
-    (tokenable_id, tokenable_type, name, token, abilities, expires_at, created_at, updated_at) 
+    (tokenable_id, tokenable_type, session_id, parent_id, name, token, abilities, expires_at, created_at, updated_at) 
     VALUES 
-    (?, ?, ?, ?, ?, ?, ?, ?)`
+    (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
-	result, sqlxErr := rdb.client.Exec(
+	result, sqlxErr := exec.ExecContext(ctx,
 		query,
 		au.UserID,
 		"App\\Models\\User",
+		sessionID,
+		parentID,
 		tokenType,
 		hashedToken,
 		string(abilitiesJSON),
-		au.ExpiresAt,
+		expiresAt,
 		au.CreatedAt,
 		au.UpdatedAt)
 	if sqlxErr != nil {
-		logger.Error("Error while creating new token " + sqlxErr.Error())
+		logger.FromContext(ctx).Error("Error while creating new token", zap.Error(sqlxErr))
 		return nil, errs.NewUnexpectedError("unexpected database error")
 	}
 
 	// Get the last inserted ID
 	id, err := result.LastInsertId()
 	if err != nil {
-		logger.Error("Error while getting last insert id " + err.Error())
+		logger.FromContext(ctx).Error("Error while getting last insert id", zap.Error(err))
 		return nil, errs.NewUnexpectedError("unexpected database error")
 	}
 
 	au.ID = uint64(id)
+	au.Name = tokenType
 	au.HashedToken = hashedToken
 	au.Token = genToken
 
 	return &au, nil
 }
 
-func (rdb AuthRepositoryDB) CreateAccessToken(au domain.Token) (*domain.Token, *errs.AppError) {
-	return rdb.createToken(string(enums.AccessToken), au)
+func (rdb AuthRepositoryDB) CreateAccessToken(ctx context.Context, au domain.Token) (*domain.Token, *errs.AppError) {
+	ctx, done := observe(ctx, "auth", "CreateAccessToken")
+	defer done()
+
+	return rdb.createToken(ctx, rdb.client, string(enums.AccessToken), au)
+}
+
+func (rdb AuthRepositoryDB) CreateRefreshToken(ctx context.Context, au domain.Token) (*domain.Token, *errs.AppError) {
+	ctx, done := observe(ctx, "auth", "CreateRefreshToken")
+	defer done()
+
+	return rdb.createToken(ctx, rdb.client, string(enums.RefreshToken), au)
+}
+
+// CreateChallengeToken stores the short-lived token handed out when a login
+// still has to pass two-factor authentication
+func (rdb AuthRepositoryDB) CreateChallengeToken(ctx context.Context, au domain.Token) (*domain.Token, *errs.AppError) {
+	ctx, done := observe(ctx, "auth", "CreateChallengeToken")
+	defer done()
+
+	return rdb.createToken(ctx, rdb.client, string(enums.TwoFactorChallengeToken), au)
+}
+
+func (rdb AuthRepositoryDB) DeleteChallengeToken(ctx context.Context, user_id uint64, id uint64) *errs.AppError {
+	ctx, done := observe(ctx, "auth", "DeleteChallengeToken")
+	defer done()
+
+	query := `DELETE FROM personal_access_tokens WHERE id = ? AND tokenable_id = ? AND name = ?`
+
+	_, err := rdb.client.ExecContext(ctx, query, id, user_id, string(enums.TwoFactorChallengeToken))
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while deleting challenge token", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return nil
+}
+
+// CreatePersonalAccessToken stores a user-named API token. Personal tokens
+// don't belong to a session and are told apart from login tokens by name.
+func (rdb AuthRepositoryDB) CreatePersonalAccessToken(ctx context.Context, au domain.Token) (*domain.Token, *errs.AppError) {
+	ctx, done := observe(ctx, "auth", "CreatePersonalAccessToken")
+	defer done()
+
+	return rdb.createToken(ctx, rdb.client, au.Name, au)
+}
+
+func (rdb AuthRepositoryDB) DeletePersonalAccessToken(ctx context.Context, user_id uint64, id uint64) *errs.AppError {
+	ctx, done := observe(ctx, "auth", "DeletePersonalAccessToken")
+	defer done()
+
+	query := `DELETE FROM personal_access_tokens 
+              WHERE id = ? AND tokenable_id = ? AND session_id IS NULL AND name NOT IN (?, ?, ?)`
+
+	result, err := rdb.client.ExecContext(ctx,
+		query,
+		id,
+		user_id,
+		string(enums.AccessToken),
+		string(enums.RefreshToken),
+		string(enums.TwoFactorChallengeToken),
+	)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while deleting personal access token", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while getting rows affected", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	if rowsAffected == 0 {
+		return errs.NewNotFoundError("Token not found")
+	}
+
+	return nil
+}
+
+// DeleteExpiredTokens removes at most limit tokens that expired before the
+// given time and returns how many were removed. Tokens without an expiry are
+// kept until they are revoked.
+func (rdb AuthRepositoryDB) DeleteExpiredTokens(ctx context.Context, before time.Time, limit int) (int64, *errs.AppError) {
+	ctx, done := observe(ctx, "auth", "DeleteExpiredTokens")
+	defer done()
+
+	query := `DELETE FROM personal_access_tokens 
+              WHERE expires_at IS NOT NULL AND expires_at < ? 
+              ORDER BY expires_at 
+              LIMIT ?`
+
+	result, err := rdb.client.ExecContext(ctx, query, before, limit)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while deleting expired tokens", zap.Error(err))
+		return 0, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while getting rows affected", zap.Error(err))
+		return 0, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return rowsAffected, nil
+}
+
+func (rdb AuthRepositoryDB) FindPersonalAccessTokens(ctx context.Context, user_id uint64) (domain.Tokens, *errs.AppError) {
+	ctx, done := observe(ctx, "auth", "FindPersonalAccessTokens")
+	defer done()
+
+	query := `SELECT 
+		id, 
+		name, 
+		abilities, 
+		last_used_at, 
+		expires_at, 
+		created_at 
+	FROM personal_access_tokens 
+	WHERE tokenable_id = ? AND session_id IS NULL AND name NOT IN (?, ?, ?) 
+	ORDER BY created_at DESC`
+
+	rows, err := rdb.client.QueryContext(ctx,
+		query,
+		user_id,
+		string(enums.AccessToken),
+		string(enums.RefreshToken),
+		string(enums.TwoFactorChallengeToken),
+	)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while querying personal_access_tokens table", zap.Error(err))
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+	defer rows.Close()
+
+	tokens := domain.Tokens{}
+	for rows.Next() {
+		var token domain.Token
+		var abilitiesJSON string
+		var lastUsedAt, expiresAt sql.NullTime
+
+		err := rows.Scan(&token.ID, &token.Name, &abilitiesJSON, &lastUsedAt, &expiresAt, &token.CreatedAt)
+		if err != nil {
+			logger.FromContext(ctx).Error("Error while scanning personal access token row", zap.Error(err))
+			return nil, errs.NewUnexpectedError("unexpected database error")
+		}
+
+		if err := json.Unmarshal([]byte(abilitiesJSON), &token.Abilities); err != nil {
+			logger.FromContext(ctx).Error("Error parsing abilities JSON:", zap.Error(err))
+			return nil, errs.NewUnexpectedError("unexpected error")
+		}
+
+		token.UserID = user_id
+		token.LastUsedAt = lastUsedAt.Time
+		token.ExpiresAt = expiresAt.Time
+		tokens = append(tokens, token)
+	}
+
+	if err = rows.Err(); err != nil {
+		logger.FromContext(ctx).Error("Error after iterating over personal access token rows", zap.Error(err))
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return tokens, nil
+}
+
+// RotateRefreshToken replaces a refresh token with a new one linked to it
+// and drops the stored access tokens of its session. The old refresh token
+// is kept, marked as rotated, so a later attempt to use it again can be
+// recognised as reuse. It fails with 401 when the token was already rotated.
+func (rdb AuthRepositoryDB) RotateRefreshToken(ctx context.Context, refreshTokenId uint64, rt domain.Token) (*domain.Token, *errs.AppError) {
+	ctx, done := observe(ctx, "auth", "RotateRefreshToken")
+	defer done()
+
+	tx, err := rdb.client.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while starting transaction:", zap.Error(err))
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	defer tx.Rollback()
+
+	query := `UPDATE personal_access_tokens SET rotated_at = ? 
+              WHERE id = ? AND name = ? AND rotated_at IS NULL`
+
+	result, err := tx.ExecContext(ctx, query, time.Now(), refreshTokenId, string(enums.RefreshToken))
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while rotating refresh token", zap.Error(err))
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while getting rows affected", zap.Error(err))
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	// Another request rotated this token first
+	if rowsAffected == 0 {
+		return nil, errs.NewUnauthorizedError("Token has been revoked")
+	}
+
+	// The new token keeps the session's absolute expiry, so a session can't
+	// be kept alive forever by refreshing it
+	var parentExpiresAt sql.NullTime
+	query = `SELECT expires_at FROM personal_access_tokens WHERE id = ?`
+
+	err = tx.GetContext(ctx, &parentExpiresAt, query, refreshTokenId)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while querying refresh token expiry", zap.Error(err))
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	if parentExpiresAt.Valid && parentExpiresAt.Time.Before(rt.ExpiresAt) {
+		rt.ExpiresAt = parentExpiresAt.Time
+	}
+
+	var sessionID interface{}
+	if rt.SessionID != 0 {
+		sessionID = rt.SessionID
+	}
+
+	query = `DELETE FROM personal_access_tokens 
+              WHERE tokenable_id = ? AND name = ? AND session_id <=> ?`
+
+	_, err = tx.ExecContext(ctx, query, rt.UserID, string(enums.AccessToken), sessionID)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while revoking access token", zap.Error(err))
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	rt.ParentID = refreshTokenId
+
+	refresh, appErr := rdb.createToken(ctx, tx, string(enums.RefreshToken), rt)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.FromContext(ctx).Error("Error while committing transaction:", zap.Error(err))
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return refresh, nil
+}
+
+// CreatePasswordResetToken issues a single-use reset token for the user,
+// replacing any previous one. Only the hash is stored; the plaintext is returned.
+func (rdb AuthRepositoryDB) CreatePasswordResetToken(ctx context.Context, user_id uint64, expiresAt time.Time) (string, *errs.AppError) {
+	ctx, done := observe(ctx, "auth", "CreatePasswordResetToken")
+	defer done()
+
+	genToken, err := helpers.GenerateToken()
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while generating password reset token", zap.Error(err))
+		return "", errs.NewUnexpectedError("unexpected database error")
+	}
+
+	tx, err := rdb.client.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while starting transaction:", zap.Error(err))
+		return "", errs.NewUnexpectedError("unexpected database error")
+	}
+
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM password_reset_tokens WHERE user_id = ?`, user_id)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while deleting previous password reset tokens", zap.Error(err))
+		return "", errs.NewUnexpectedError("unexpected database error")
+	}
+
+	query := `INSERT INTO password_reset_tokens (user_id, token, expires_at, created_at) VALUES (?, ?, ?, ?)`
+
+	_, err = tx.ExecContext(ctx, query, user_id, helpers.HashToken(genToken), expiresAt, time.Now())
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while creating password reset token", zap.Error(err))
+		return "", errs.NewUnexpectedError("unexpected database error")
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.FromContext(ctx).Error("Error while committing transaction:", zap.Error(err))
+		return "", errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return genToken, nil
 }
 
-func (rdb AuthRepositoryDB) CreateRefreshToken(au domain.Token) (*domain.Token, *errs.AppError) {
-	return rdb.createToken(string(enums.RefreshToken), au)
+// ConsumePasswordResetToken validates a reset token and deletes it so it
+// cannot be used again, returning the user it was issued for
+func (rdb AuthRepositoryDB) ConsumePasswordResetToken(ctx context.Context, token string) (uint64, *errs.AppError) {
+	ctx, done := observe(ctx, "auth", "ConsumePasswordResetToken")
+	defer done()
+
+	tx, err := rdb.client.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while starting transaction:", zap.Error(err))
+		return 0, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	defer tx.Rollback()
+
+	var resetToken struct {
+		ID        uint64    `db:"id"`
+		UserID    uint64    `db:"user_id"`
+		ExpiresAt time.Time `db:"expires_at"`
+	}
+
+	query := `SELECT id, user_id, expires_at FROM password_reset_tokens WHERE token = ? FOR UPDATE`
+
+	err = tx.GetContext(ctx, &resetToken, query, helpers.HashToken(token))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, errs.NewValidationError("token", "The password reset token is invalid or has expired")
+		}
+		logger.FromContext(ctx).Error("Error while querying password_reset_tokens table", zap.Error(err))
+		return 0, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM password_reset_tokens WHERE id = ?`, resetToken.ID)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while deleting password reset token", zap.Error(err))
+		return 0, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.FromContext(ctx).Error("Error while committing transaction:", zap.Error(err))
+		return 0, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	if resetToken.ExpiresAt.Before(time.Now()) {
+		return 0, errs.NewValidationError("token", "The password reset token is invalid or has expired")
+	}
+
+	return resetToken.UserID, nil
 }
-func (rdb AuthRepositoryDB) GetTokenAbilities(fullToken string) ([]string, *errs.AppError) {
+
+func (rdb AuthRepositoryDB) GetTokenAbilities(ctx context.Context, fullToken string) ([]string, *errs.AppError) {
+	ctx, done := observe(ctx, "auth", "GetTokenAbilities")
+	defer done()
+
 	_, tokenString, err := helpers.ParseToken(fullToken)
 	if err != nil {
-		logger.Error("Error while parsing token " + err.Error())
+		logger.FromContext(ctx).Error("Error while parsing token", zap.Error(err))
 		return nil, errs.NewUnauthorizedError("Invalid Token")
 	}
 
@@ -98,128 +457,132 @@ func (rdb AuthRepositoryDB) GetTokenAbilities(fullToken string) ([]string, *errs
 	query := `SELECT abilities from personal_access_tokens where token = ?`
 
 	var abilitiesJSON string
-	err = rdb.client.Get(&abilitiesJSON, query, hashedToken)
+	err = rdb.client.GetContext(ctx, &abilitiesJSON, query, hashedToken)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			logger.Error("No token found with token: " + fullToken)
+			logger.FromContext(ctx).Error("No token found for the presented token")
 			return nil, errs.NewUnauthorizedError("Invalid token")
 		}
-		logger.Error("Error while querying personal_access_tokens table " + err.Error())
+		logger.FromContext(ctx).Error("Error while querying personal_access_tokens table", zap.Error(err))
 		return nil, errs.NewUnexpectedError("unexpected database error")
 	}
 
 	var abilities []string
 	if err := json.Unmarshal([]byte(abilitiesJSON), &abilities); err != nil {
-		logger.Error("Error parsing abilities JSON: " + err.Error())
+		logger.FromContext(ctx).Error("Error parsing abilities JSON:", zap.Error(err))
 		return nil, errs.NewUnexpectedError("unexpected error")
 	}
 
 	return abilities, nil
 }
 
-func (rdb AuthRepositoryDB) Login(au domain.AuthUser) (*domain.User, *errs.AppError) {
-	query := `SELECT id, email, password from users where email = ?`
+func (rdb AuthRepositoryDB) Login(ctx context.Context, au domain.AuthUser) (*domain.User, *errs.AppError) {
+	ctx, done := observe(ctx, "auth", "Login")
+	defer done()
+
+	query := `SELECT id, email, password, locked_at from users where email = ?`
 	var user domain.User
 
-	err := rdb.client.Get(&user, query, au.Email)
+	err := rdb.client.GetContext(ctx, &user, query, au.Email)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			logger.Error("No user found with email: " + au.Email)
+			logger.FromContext(ctx).Error("No user found for login", zap.String("email", au.Email))
 			return nil, errs.NewUnauthorizedError("Invalid credentials")
 		} else {
-			logger.Error("Error while querying users table " + err.Error())
+			logger.FromContext(ctx).Error("Error while querying users table", zap.Error(err))
 			return nil, errs.NewUnexpectedError("unexpected database error")
 		}
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(au.Password))
 	if err != nil {
-		logger.Error("Error while generating token " + err.Error())
+		logger.FromContext(ctx).Error("Error while generating token", zap.Error(err))
 		return nil, errs.NewUnauthorizedError("Invalid credentials")
 	}
 
+	if user.IsLocked() {
+		logger.FromContext(ctx).Warn("Login attempt on locked account", zap.String("email", au.Email))
+		return nil, errs.NewUnauthorizedError("Account is locked")
+	}
+
 	return &user, nil
 }
 
-func (rdb AuthRepositoryDB) Register(au domain.UserRegister) (*domain.User, *errs.AppError) {
-	query := `SELECT id, email, password from users where email = ?`
-	var user domain.User
+// VerifyPassword checks a plaintext password against the user's stored hash
+func (rdb AuthRepositoryDB) VerifyPassword(ctx context.Context, user_id uint64, password string) *errs.AppError {
+	ctx, done := observe(ctx, "auth", "VerifyPassword")
+	defer done()
+
+	query := `SELECT password from users where id = ?`
+	var hashedPassword string
 
-	err := rdb.client.Get(&user, query, au.Email)
+	err := rdb.client.GetContext(ctx, &hashedPassword, query, user_id)
 	if err != nil {
-		if err != sql.ErrNoRows {
-			logger.Error("Error while querying users table " + err.Error())
-			return nil, errs.NewUnexpectedError("unexpected database error")
+		if err == sql.ErrNoRows {
+			return errs.NewNotFoundError("User not found")
 		}
-	} else {
-		logger.Error("User already exists")
-		return nil, errs.NewUnexpectedError("User already exists")
+		logger.FromContext(ctx).Error("Error while querying users table", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
 	}
 
-	role, errRole := rdb.roleRepo.FindByName(string(enums.CustomerRole))
-	au.RoleId = uint64(role.Id)
-	if errRole != nil {
-		logger.Error("Error while finding role " + err.Error())
-		return nil, errs.NewUnexpectedError("unexpected database error")
+	err = bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+	if err != nil {
+		return errs.NewValidationError("password", "The provided password is incorrect")
 	}
 
-	query = `INSERT INTO users (name, email, password, role_id, uuid) VALUES (?, ?, ?, ?, ?)`
+	return nil
+}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(au.Password), 12)
-	if err != nil {
-		logger.Error("Error while hashing password " + err.Error())
-		return nil, errs.NewUnexpectedError("unexpected database error")
-	}
+func (rdb AuthRepositoryDB) Register(ctx context.Context, au domain.UserRegister) (*domain.User, *errs.AppError) {
+	ctx, done := observe(ctx, "auth", "Register")
+	defer done()
 
-	result, err := rdb.client.Exec(query, au.Name, au.Email, string(hashedPassword), au.RoleId, au.UUID)
-	if err != nil {
-		logger.Error("Error while creating new user " + err.Error())
-		return nil, errs.NewUnexpectedError("unexpected database error")
-	}
+	query := `SELECT id, email, password from users where email = ?`
+	var user domain.User
 
-	id, err := result.LastInsertId()
+	err := rdb.client.GetContext(ctx, &user, query, au.Email)
 	if err != nil {
-		logger.Error("Error while getting last insert id " + err.Error())
-		return nil, errs.NewUnexpectedError("unexpected database error")
+		if err != sql.ErrNoRows {
+			logger.FromContext(ctx).Error("Error while querying users table", zap.Error(err))
+			return nil, errs.NewUnexpectedError("unexpected database error")
+		}
+	} else {
+		logger.FromContext(ctx).Error("User already exists")
+		return nil, errs.NewUnexpectedError("User already exists")
 	}
 
-	newUser := domain.User{
-		Id:        int64(id),
-		Name:      au.Name,
-		Email:     au.Email,
-		Password:  string(hashedPassword),
-		UUID:      au.UUID,
-		CreatedAt: au.CreatedAt,
+	role, errRole := rdb.roleRepo.FindByName(ctx, string(enums.CustomerRole))
+	if errRole != nil {
+		logger.FromContext(ctx).Error("Error while finding role", zap.String("error", errRole.Message))
+		return nil, errs.NewUnexpectedError("unexpected database error")
 	}
+	au.RoleId = uint64(role.Id)
 
-	return &newUser, nil
+	return rdb.userRepo.Create(ctx, au)
 }
 
-func (rdb AuthRepositoryDB) Logout(id uint64) *errs.AppError {
-	query := `DELETE FROM personal_access_tokens WHERE tokenable_id = ?`
+// revokeToken deletes the tokens of the given type issued for a session.
+// Tokens issued outside of a session are matched by user instead.
+func (rdb AuthRepositoryDB) revokeToken(ctx context.Context, user_id uint64, session_id uint64, tokenType enums.TokenName) *errs.AppError {
+	query := `DELETE FROM personal_access_tokens 
+              WHERE tokenable_id = ? AND name = ? AND session_id = ?`
+	args := []interface{}{user_id, string(tokenType), session_id}
 
-	_, err := rdb.client.Exec(query, id)
-	if err != nil {
-		logger.Error("Error while deleting tokens " + err.Error())
-		return errs.NewUnexpectedError("unexpected database error")
+	if session_id == 0 {
+		query = `DELETE FROM personal_access_tokens 
+              WHERE tokenable_id = ? AND name = ? AND session_id IS NULL`
+		args = args[:2]
 	}
 
-	return nil
-}
-
-func (rdb AuthRepositoryDB) revokeToken(user_id uint64, tokenType enums.TokenName) *errs.AppError {
-	query := `DELETE FROM personal_access_tokens 
-              WHERE tokenable_id = ? AND name = ?`
-
-	result, err := rdb.client.Exec(query, user_id, string(tokenType))
+	result, err := rdb.client.ExecContext(ctx, query, args...)
 	if err != nil {
-		logger.Error("Error while revoking " + string(tokenType) + " token " + err.Error())
+		logger.FromContext(ctx).Error("Error while revoking token", zap.String("token_type", string(tokenType)), zap.Error(err))
 		return errs.NewUnexpectedError("unexpected database error")
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		logger.Error("Error while getting rows affected " + err.Error())
+		logger.FromContext(ctx).Error("Error while getting rows affected", zap.Error(err))
 		return errs.NewUnexpectedError("unexpected database error")
 	}
 
@@ -230,72 +593,128 @@ func (rdb AuthRepositoryDB) revokeToken(user_id uint64, tokenType enums.TokenNam
 	return nil
 }
 
-func (rdb AuthRepositoryDB) RevokeAccessToken(user_id uint64) *errs.AppError {
-	return rdb.revokeToken(user_id, enums.AccessToken)
+func (rdb AuthRepositoryDB) RevokeAccessToken(ctx context.Context, user_id uint64, session_id uint64) *errs.AppError {
+	ctx, done := observe(ctx, "auth", "RevokeAccessToken")
+	defer done()
+
+	return rdb.revokeToken(ctx, user_id, session_id, enums.AccessToken)
+}
+
+func (rdb AuthRepositoryDB) RevokeRefreshToken(ctx context.Context, user_id uint64, session_id uint64) *errs.AppError {
+	ctx, done := observe(ctx, "auth", "RevokeRefreshToken")
+	defer done()
+
+	return rdb.revokeToken(ctx, user_id, session_id, enums.RefreshToken)
+}
+
+func (rdb AuthRepositoryDB) IdentityRepo() ports.IdentityRepository {
+	return rdb.identityRepo
 }
 
-func (rdb AuthRepositoryDB) RevokeRefreshToken(user_id uint64) *errs.AppError {
-	return rdb.revokeToken(user_id, enums.RefreshToken)
+func (rdb AuthRepositoryDB) LoginAttemptRepo() ports.LoginAttemptRepository {
+	return rdb.loginAttemptRepo
 }
 
 func (rdb AuthRepositoryDB) RoleRepo() ports.RoleRepository {
 	return rdb.roleRepo
 }
 
+func (rdb AuthRepositoryDB) SessionRepo() ports.SessionRepository {
+	return rdb.sessionRepo
+}
+
+func (rdb AuthRepositoryDB) TwoFactorRepo() ports.TwoFactorRepository {
+	return rdb.twoFactorRepo
+}
+
 func (rdb AuthRepositoryDB) UserRepo() ports.UserRepository {
 	return rdb.userRepo
 }
 
-func (rdb AuthRepositoryDB) ValidateToken(fullToken string) (uint64, *errs.AppError) {
+// ValidateToken checks the token and returns it with its user and session.
+// Rotated refresh tokens are returned as well so callers can detect reuse.
+func (rdb AuthRepositoryDB) ValidateToken(ctx context.Context, fullToken string) (*domain.Token, *errs.AppError) {
+	ctx, done := observe(ctx, "auth", "ValidateToken")
+	defer done()
+
 	tokenID, tokenString, err := helpers.ParseToken(fullToken)
 	if err != nil {
-		logger.Error("Error while parsing token " + err.Error())
-		return 0, errs.NewUnauthorizedError("Invalid Token")
+		logger.FromContext(ctx).Error("Error while parsing token", zap.Error(err))
+		return nil, errs.NewUnauthorizedError("Invalid Token")
 	}
 
 	hashedToken := helpers.HashToken(tokenString)
 
 	var userID uint64
+	var name string
+	var sessionID sql.NullInt64
 	var expiresAt sql.NullTime
 	var lastUsedAt sql.NullTime
+	var rotatedAt sql.NullTime
 
 	query := `
 	SELECT 
 		tokenable_id, 
+		name, 
+		session_id, 
 		expires_at, 
-		last_used_at 
+		last_used_at, 
+		rotated_at 
 	FROM personal_access_tokens 
 	WHERE id = ? AND token = ?`
 
-	err = rdb.client.QueryRow(query, tokenID, hashedToken).Scan(&userID, &expiresAt, &lastUsedAt)
+	err = rdb.client.QueryRowContext(ctx, query, tokenID, hashedToken).Scan(&userID, &name, &sessionID, &expiresAt, &lastUsedAt, &rotatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			logger.Error(fmt.Sprintf("No token found with id: %d", tokenID))
-			return 0, errs.NewUnauthorizedError("Invalid Token")
+			logger.FromContext(ctx).Error("No token found", zap.Uint64("token_id", uint64(tokenID)))
+			return nil, errs.NewUnauthorizedError("Invalid Token")
 		} else {
-			logger.Error("Error while querying personal_access_tokens table " + err.Error())
-			return 0, errs.NewUnexpectedError("unexpected database error")
+			logger.FromContext(ctx).Error("Error while querying personal_access_tokens table", zap.Error(err))
+			return nil, errs.NewUnexpectedError("unexpected database error")
 		}
 	}
 
 	if expiresAt.Valid && expiresAt.Time.Before(time.Now()) {
-		return 0, errs.NewUnauthorizedError("Token Expired")
+		return nil, errs.NewUnauthorizedError("Token Expired")
 	}
 
-	query = `UPDATE personal_access_tokens SET last_used_at = ? WHERE id = ?`
-	_, err = rdb.client.Exec(query, time.Now(), tokenID)
-	if err != nil {
-		logger.Error("Error while updating last_used_at " + err.Error())
-		return 0, errs.NewUnexpectedError("unexpected database error")
+	// Touch the token together with the session it belongs to. Every request
+	// validates its token, so the time is only written once it is stale.
+	lastUsed := lastUsedAt.Time
+	if now := time.Now(); !lastUsedAt.Valid || now.Sub(lastUsedAt.Time) >= lastUsedAtResolution {
+		query = `UPDATE personal_access_tokens t 
+		LEFT JOIN auth_sessions s ON s.id = t.session_id 
+		SET t.last_used_at = ?, s.last_used_at = ? 
+		WHERE t.id = ?`
+		_, err = rdb.client.ExecContext(ctx, query, now, now, tokenID)
+		if err != nil {
+			logger.FromContext(ctx).Error("Error while updating last_used_at", zap.Error(err))
+			return nil, errs.NewUnexpectedError("unexpected database error")
+		}
+		lastUsed = now
+	}
+
+	token := domain.Token{
+		ID:         uint64(tokenID),
+		UserID:     userID,
+		SessionID:  uint64(sessionID.Int64),
+		Name:       name,
+		RotatedAt:  rotatedAt.Time,
+		ExpiresAt:  expiresAt.Time,
+		LastUsedAt: lastUsed,
 	}
 
-	return userID, nil
+	return &token, nil
 }
 
 func NewAuthRepositoryDB(dbClient *sqlx.DB) AuthRepositoryDB {
 	return AuthRepositoryDB{
-		client:   dbClient,
-		userRepo: NewUserRepositoryDB(dbClient),
-		roleRepo: NewRoleRepositoryDB(dbClient),
+		client:           dbClient,
+		identityRepo:     NewIdentityRepositoryDB(dbClient),
+		loginAttemptRepo: NewLoginAttemptRepositoryDB(dbClient),
+		userRepo:         NewUserRepositoryDB(dbClient),
+		roleRepo:         NewRoleRepositoryDB(dbClient),
+		sessionRepo:      NewSessionRepositoryDB(dbClient),
+		twoFactorRepo:    NewTwoFactorRepositoryDB(dbClient),
 	}
 }
@@ -13,6 +13,7 @@ import (
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/gosimple/slug"
 	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
 )
 
 type CategoryRepositoryDB struct {
@@ -20,13 +21,16 @@ type CategoryRepositoryDB struct {
 	verifier *db.FieldVerifier
 }
 
-func (rdb CategoryRepositoryDB) Create(c domain.Category) (*domain.Category, *errs.AppError) {
+func (rdb CategoryRepositoryDB) Create(ctx context.Context, c domain.Category) (*domain.Category, *errs.AppError) {
+	ctx, done := observe(ctx, "category", "Create")
+	defer done()
+
 	var finalSlug string
 	var nameExists *domain.Category
 
-	nameExists, _ = rdb.FindByName(c.Name)
+	nameExists, _ = rdb.FindByName(ctx, c.Name)
 	if nameExists != nil {
-		logger.Error("Error while creating new category, name already exists")
+		logger.FromContext(ctx).Error("Error while creating new category, name already exists")
 		return nil, errs.NewValidationError("name", "The name has already been taken")
 	}
 
@@ -43,7 +47,7 @@ func (rdb CategoryRepositoryDB) Create(c domain.Category) (*domain.Category, *er
 	counter := 1
 	for {
 		// Try to find if the current slug exists
-		existing, err := rdb.FindBySlug(finalSlug)
+		existing, err := rdb.FindBySlug(ctx, finalSlug)
 		if err != nil {
 			// If error is because slug doesn't exist, we can use this slug
 			break
@@ -59,15 +63,15 @@ func (rdb CategoryRepositoryDB) Create(c domain.Category) (*domain.Category, *er
 
 	insertQuery := `INSERT INTO categories (name, slug, created_at, updated_at) VALUES (?, ?, ?, ?)`
 
-	res, sqlxErr := rdb.client.Exec(insertQuery, c.Name, finalSlug, c.CreatedAt, c.UpdatedAt)
+	res, sqlxErr := rdb.client.ExecContext(ctx, insertQuery, c.Name, finalSlug, c.CreatedAt, c.UpdatedAt)
 	if sqlxErr != nil {
-		logger.Error("Error while creating new category " + sqlxErr.Error())
+		logger.FromContext(ctx).Error("Error while creating new category", zap.Error(sqlxErr))
 		return nil, errs.NewUnexpectedError("unexpected database error")
 	}
 
 	id, sqlxErr := res.LastInsertId()
 	if sqlxErr != nil {
-		logger.Error("Error while getting last insert id for new category " + sqlxErr.Error())
+		logger.FromContext(ctx).Error("Error while getting last insert id for new category", zap.Error(sqlxErr))
 		return nil, errs.NewUnexpectedError("unexpected database error")
 	}
 
@@ -77,18 +81,21 @@ func (rdb CategoryRepositoryDB) Create(c domain.Category) (*domain.Category, *er
 	return &c, nil
 }
 
-func (rdb CategoryRepositoryDB) Delete(id int) *errs.AppError {
+func (rdb CategoryRepositoryDB) Delete(ctx context.Context, id int) *errs.AppError {
+	ctx, done := observe(ctx, "category", "Delete")
+	defer done()
+
 	query := `DELETE FROM categories WHERE id = ?`
 
-	result, err := rdb.client.ExecContext(context.Background(), query, id)
+	result, err := rdb.client.ExecContext(ctx, query, id)
 	if err != nil {
-		logger.Error("Error while deleting category: " + err.Error())
+		logger.FromContext(ctx).Error("Error while deleting category:", zap.Error(err))
 		return errs.NewUnexpectedError("unexpected database error")
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		logger.Error("Error getting rows affected: " + err.Error())
+		logger.FromContext(ctx).Error("Error getting rows affected:", zap.Error(err))
 		return errs.NewUnexpectedError("unexpected database error")
 	}
 
@@ -99,7 +106,10 @@ func (rdb CategoryRepositoryDB) Delete(id int) *errs.AppError {
 	return nil
 }
 
-func (rdb CategoryRepositoryDB) FindById(id int) (*domain.Category, *errs.AppError) {
+func (rdb CategoryRepositoryDB) FindById(ctx context.Context, id int) (*domain.Category, *errs.AppError) {
+	ctx, done := observe(ctx, "category", "FindById")
+	defer done()
+
 	query := `SELECT
 		id,
 		name,
@@ -112,13 +122,13 @@ func (rdb CategoryRepositoryDB) FindById(id int) (*domain.Category, *errs.AppErr
 
 	var category domain.Category
 
-	err := rdb.client.Get(&category, query, id)
+	err := rdb.client.GetContext(ctx, &category, query, id)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errs.NewNotFoundError("Category not found")
 		} else {
-			logger.Error("Error while querying category table " + err.Error())
+			logger.FromContext(ctx).Error("Error while querying category table", zap.Error(err))
 			return nil, errs.NewUnexpectedError("unexpected database error")
 		}
 	}
@@ -126,15 +136,18 @@ func (rdb CategoryRepositoryDB) FindById(id int) (*domain.Category, *errs.AppErr
 	return &category, nil
 }
 
-func (rdb CategoryRepositoryDB) FindAll(filter pagination.DataDBFilter) (domain.Categories, int64, *errs.AppError) {
+func (rdb CategoryRepositoryDB) FindAll(ctx context.Context, filter pagination.DataDBFilter) (domain.Categories, int64, *errs.AppError) {
+	ctx, done := observe(ctx, "category", "FindAll")
+	defer done()
+
 	var total int64
 	categories := domain.Categories{}
 
 	countQuery := `SELECT COUNT(*) FROM categories`
 
-	err := rdb.client.Get(&total, countQuery)
+	err := rdb.client.GetContext(ctx, &total, countQuery)
 	if err != nil {
-		logger.Error("Error while counting category table " + err.Error())
+		logger.FromContext(ctx).Error("Error while counting category table", zap.Error(err))
 		return nil, 0, errs.NewUnexpectedError("unexpected database error")
 	}
 
@@ -155,7 +168,7 @@ func (rdb CategoryRepositoryDB) FindAll(filter pagination.DataDBFilter) (domain.
 	// Calculate offset
 	offset := (filter.Page - 1) * filter.PerPage
 
-	err = rdb.client.Select(
+	err = rdb.client.SelectContext(ctx,
 		&categories,
 		query,
 		filter.PerPage,
@@ -163,14 +176,17 @@ func (rdb CategoryRepositoryDB) FindAll(filter pagination.DataDBFilter) (domain.
 	)
 
 	if err != nil {
-		logger.Error("Error while querying category table " + err.Error())
+		logger.FromContext(ctx).Error("Error while querying category table", zap.Error(err))
 		return nil, 0, errs.NewUnexpectedError("unexpected database error")
 	}
 
 	return categories, total, nil
 }
 
-func (rdb CategoryRepositoryDB) FindByName(name string) (*domain.Category, *errs.AppError) {
+func (rdb CategoryRepositoryDB) FindByName(ctx context.Context, name string) (*domain.Category, *errs.AppError) {
+	ctx, done := observe(ctx, "category", "FindByName")
+	defer done()
+
 	query := `SELECT
 		id,
 		name,
@@ -183,13 +199,13 @@ func (rdb CategoryRepositoryDB) FindByName(name string) (*domain.Category, *errs
 
 	var category domain.Category
 
-	err := rdb.client.Get(&category, query, name)
+	err := rdb.client.GetContext(ctx, &category, query, name)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errs.NewNotFoundError("Category not found")
 		} else {
-			logger.Error("Error while querying category table " + err.Error())
+			logger.FromContext(ctx).Error("Error while querying category table", zap.Error(err))
 			return nil, errs.NewUnexpectedError("unexpected database error")
 		}
 	}
@@ -197,7 +213,10 @@ func (rdb CategoryRepositoryDB) FindByName(name string) (*domain.Category, *errs
 	return &category, nil
 }
 
-func (rdb CategoryRepositoryDB) FindBySlug(slug string) (*domain.Category, *errs.AppError) {
+func (rdb CategoryRepositoryDB) FindBySlug(ctx context.Context, slug string) (*domain.Category, *errs.AppError) {
+	ctx, done := observe(ctx, "category", "FindBySlug")
+	defer done()
+
 	query := `SELECT
 		id,
 		name,
@@ -210,13 +229,13 @@ func (rdb CategoryRepositoryDB) FindBySlug(slug string) (*domain.Category, *errs
 
 	var category domain.Category
 
-	err := rdb.client.Get(&category, query, slug)
+	err := rdb.client.GetContext(ctx, &category, query, slug)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errs.NewNotFoundError("Category not found")
 		} else {
-			logger.Error("Error while querying category table " + err.Error())
+			logger.FromContext(ctx).Error("Error while querying category table", zap.Error(err))
 			return nil, errs.NewUnexpectedError("unexpected database error")
 		}
 	}
@@ -224,35 +243,38 @@ func (rdb CategoryRepositoryDB) FindBySlug(slug string) (*domain.Category, *errs
 	return &category, nil
 }
 
-func (rdb CategoryRepositoryDB) Update(c domain.Category) (*domain.Category, *errs.AppError) {
+func (rdb CategoryRepositoryDB) Update(ctx context.Context, c domain.Category) (*domain.Category, *errs.AppError) {
+	ctx, done := observe(ctx, "category", "Update")
+	defer done()
+
 	var err error
 
 	// First, check if the category exists
-	existingCategory, errPkg := rdb.FindById(int(c.Id))
+	existingCategory, errPkg := rdb.FindById(ctx, int(c.Id))
 	if errPkg != nil {
 		return nil, errs.NewNotFoundError("Category not found")
 	}
 
 	// Verify name uniqueness
-	if err := rdb.verifier.VerifyUniqueField("name", c.Name, c.Id); err != nil {
+	if err := rdb.verifier.VerifyUniqueField(ctx, "name", c.Name, c.Id); err != nil {
 		return nil, err
 	}
 
 	// Verify slug uniqueness
-	if err := rdb.verifier.VerifyUniqueField("slug", c.Slug, c.Id); err != nil {
+	if err := rdb.verifier.VerifyUniqueField(ctx, "slug", c.Slug, c.Id); err != nil {
 		return nil, err
 	}
 
 	updateQuery := `UPDATE categories SET name = ?, slug = ? WHERE id = ?`
-	result, err := rdb.client.Exec(updateQuery, c.Name, c.Slug, c.Id)
+	result, err := rdb.client.ExecContext(ctx, updateQuery, c.Name, c.Slug, c.Id)
 	if err != nil {
-		logger.Error("Error while updating category: " + err.Error())
+		logger.FromContext(ctx).Error("Error while updating category:", zap.Error(err))
 		return nil, errs.NewUnexpectedError("Unexpected database error")
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		logger.Error("Error getting rows affected: " + err.Error())
+		logger.FromContext(ctx).Error("Error getting rows affected:", zap.Error(err))
 		return nil, errs.NewUnexpectedError("Unexpected database error")
 	}
 
@@ -261,7 +283,7 @@ func (rdb CategoryRepositoryDB) Update(c domain.Category) (*domain.Category, *er
 	}
 
 	// Fetch the updated category
-	updatedCategory, errPkg := rdb.FindById(int(c.Id))
+	updatedCategory, errPkg := rdb.FindById(ctx, int(c.Id))
 	if errPkg != nil {
 		return nil, errs.NewUnexpectedError("Error fetching updated category")
 	}
@@ -0,0 +1,167 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// fakeDB is a database/sql driver that records the statements it runs.
+// Queries answer with the rows registered for a fragment of their text.
+type fakeDB struct {
+	mu        sync.Mutex
+	execs     []fakeExec
+	rows      map[string][]driver.Value
+	committed bool
+}
+
+type fakeExec struct {
+	query string
+	args  []driver.Value
+}
+
+var (
+	fakeDBsMu sync.Mutex
+	fakeDBs   = map[string]*fakeDB{}
+)
+
+func init() {
+	sql.Register("fakedb", fakeDriver{})
+}
+
+// newFakeDB opens a client on a fresh fakeDB
+func newFakeDB(t *testing.T) (*sqlx.DB, *fakeDB) {
+	t.Helper()
+
+	db := &fakeDB{rows: map[string][]driver.Value{}}
+
+	fakeDBsMu.Lock()
+	fakeDBs[t.Name()] = db
+	fakeDBsMu.Unlock()
+
+	client, err := sqlx.Open("fakedb", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	return sqlx.NewDb(client.DB, "mysql"), db
+}
+
+// onQuery answers queries containing fragment with a single row
+func (db *fakeDB) onQuery(fragment string, row ...driver.Value) {
+	db.rows[fragment] = row
+}
+
+// exec returns the recorded statement on the table, if any
+func (db *fakeDB) exec(prefix string) (fakeExec, bool) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, e := range db.execs {
+		if strings.HasPrefix(e.query, prefix) {
+			return e, true
+		}
+	}
+
+	return fakeExec{}, false
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	fakeDBsMu.Lock()
+	defer fakeDBsMu.Unlock()
+
+	db, ok := fakeDBs[name]
+	if !ok {
+		return nil, fmt.Errorf("fakedb: unknown database %q", name)
+	}
+
+	return &fakeConn{db: db}, nil
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("fakedb: prepared statements are not supported")
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) { return fakeTx{c.db}, nil }
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	e := fakeExec{query: strings.Join(strings.Fields(query), " ")}
+	for _, arg := range args {
+		e.args = append(e.args, arg.Value)
+	}
+	c.db.execs = append(c.db.execs, e)
+
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	for fragment, row := range c.db.rows {
+		if strings.Contains(query, fragment) {
+			return &fakeRows{row: row}, nil
+		}
+	}
+
+	return &fakeRows{}, nil
+}
+
+type fakeTx struct {
+	db *fakeDB
+}
+
+func (tx fakeTx) Commit() error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+	tx.db.committed = true
+
+	return nil
+}
+
+func (tx fakeTx) Rollback() error { return nil }
+
+type fakeRows struct {
+	row  []driver.Value
+	done bool
+}
+
+func (r *fakeRows) Columns() []string {
+	columns := make([]string, len(r.row))
+	for i := range columns {
+		columns[i] = fmt.Sprintf("c%d", i)
+	}
+
+	return columns
+}
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.row == nil || r.done {
+		return io.EOF
+	}
+	r.done = true
+	copy(dest, r.row)
+
+	return nil
+}
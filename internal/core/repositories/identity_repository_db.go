@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/go-ms-project-store/internal/core/domain"
+	"github.com/go-ms-project-store/internal/pkg/errs"
+	"github.com/go-ms-project-store/internal/pkg/logger"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+type IdentityRepositoryDB struct {
+	client *sqlx.DB
+}
+
+func (rdb IdentityRepositoryDB) Create(ctx context.Context, i domain.Identity) (*domain.Identity, *errs.AppError) {
+	ctx, done := observe(ctx, "identity", "Create")
+	defer done()
+
+	query := `INSERT INTO user_identities (user_id, provider, subject, email, created_at, updated_at) 
+              VALUES (?, ?, ?, ?, ?, ?)`
+
+	result, err := rdb.client.ExecContext(ctx, query, i.UserId, i.Provider, i.Subject, i.Email, i.CreatedAt, i.UpdatedAt)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while linking identity", zap.Error(err))
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while getting last insert id", zap.Error(err))
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	i.Id = uint64(id)
+
+	return &i, nil
+}
+
+func (rdb IdentityRepositoryDB) FindByProviderSubject(ctx context.Context, provider string, subject string) (*domain.Identity, *errs.AppError) {
+	ctx, done := observe(ctx, "identity", "FindByProviderSubject")
+	defer done()
+
+	query := `SELECT 
+		id, 
+		user_id, 
+		provider, 
+		subject, 
+		email, 
+		created_at, 
+		updated_at 
+	FROM user_identities 
+	WHERE provider = ? AND subject = ?`
+
+	var identity domain.Identity
+
+	err := rdb.client.GetContext(ctx, &identity, query, provider, subject)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errs.NewNotFoundError("Identity not found")
+		}
+		logger.FromContext(ctx).Error("Error while querying user_identities table", zap.Error(err))
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return &identity, nil
+}
+
+func NewIdentityRepositoryDB(dbClient *sqlx.DB) IdentityRepositoryDB {
+	return IdentityRepositoryDB{client: dbClient}
+}
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-ms-project-store/internal/core/domain"
+	"github.com/go-ms-project-store/internal/pkg/errs"
+	"github.com/go-ms-project-store/internal/pkg/logger"
+	"github.com/go-ms-project-store/internal/pkg/pagination"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+type LoginAttemptRepositoryDB struct {
+	client *sqlx.DB
+}
+
+func (rdb LoginAttemptRepositoryDB) Create(ctx context.Context, a domain.LoginAttempt) *errs.AppError {
+	ctx, done := observe(ctx, "login_attempt", "Create")
+	defer done()
+
+	query := `INSERT INTO login_attempts (user_id, email, ip_address, user_agent, successful, created_at) 
+              VALUES (?, ?, ?, ?, ?, ?)`
+
+	_, err := rdb.client.ExecContext(ctx, query, a.UserId, a.Email, a.IpAddress, a.UserAgent, a.Successful, a.CreatedAt)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while creating login attempt", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return nil
+}
+
+// FindAll lists login attempts, optionally only those for one email address
+func (rdb LoginAttemptRepositoryDB) FindAll(ctx context.Context, filter pagination.DataDBFilter, email string) (domain.LoginAttempts, int64, *errs.AppError) {
+	ctx, done := observe(ctx, "login_attempt", "FindAll")
+	defer done()
+
+	var total int64
+	attempts := domain.LoginAttempts{}
+
+	countQuery := "SELECT COUNT(*) FROM login_attempts"
+
+	baseQuery := `
+	SELECT
+		id,
+		user_id,
+		email,
+		ip_address,
+		user_agent,
+		successful,
+		created_at
+	FROM login_attempts`
+
+	var args []interface{}
+	if email != "" {
+		countQuery += " WHERE email = ?"
+		baseQuery += " WHERE email = ?"
+		args = append(args, email)
+	}
+
+	err := rdb.client.GetContext(ctx, &total, countQuery, args...)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while counting login_attempts table", zap.Error(err))
+		return nil, 0, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	query := fmt.Sprintf("%s ORDER BY %s %s LIMIT ? OFFSET ?",
+		baseQuery,
+		filter.OrderBy,
+		filter.OrderDir)
+
+	offset := (filter.Page - 1) * filter.PerPage
+	args = append(args, filter.PerPage, offset)
+
+	err = rdb.client.SelectContext(ctx, &attempts, query, args...)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while querying login_attempts table", zap.Error(err))
+		return nil, 0, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return attempts, total, nil
+}
+
+func NewLoginAttemptRepositoryDB(dbClient *sqlx.DB) LoginAttemptRepositoryDB {
+	return LoginAttemptRepositoryDB{client: dbClient}
+}
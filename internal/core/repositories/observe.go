@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/go-ms-project-store/internal/pkg/metrics"
+	"github.com/go-ms-project-store/internal/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// observe starts a span and a latency measurement for a repository
+// operation. Call the returned function when the operation completes:
+//
+//	ctx, done := observe(ctx, "product", "FindAll")
+//	defer done()
+func observe(ctx context.Context, repository string, operation string) (context.Context, func()) {
+	ctx, span := tracing.Start(ctx, repository+"."+operation, attribute.String("db.system", "mysql"))
+	stop := metrics.ObserveQuery(repository, operation)
+
+	return ctx, func() {
+		stop()
+		span.End()
+	}
+}
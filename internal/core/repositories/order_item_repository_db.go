@@ -1,12 +1,14 @@
 package repositories
 
 import (
+	"context"
 	"github.com/go-ms-project-store/internal/core/domain"
 	"github.com/go-ms-project-store/internal/pkg/db"
 	"github.com/go-ms-project-store/internal/pkg/errs"
 	"github.com/go-ms-project-store/internal/pkg/logger"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
 )
 
 type OrderItemRepositoryDB struct {
@@ -14,18 +16,21 @@ type OrderItemRepositoryDB struct {
 	verifier *db.FieldVerifier
 }
 
-func (rdb OrderItemRepositoryDB) Create(o domain.OrderItem) (*domain.OrderItem, *errs.AppError) {
+func (rdb OrderItemRepositoryDB) Create(ctx context.Context, o domain.OrderItem) (*domain.OrderItem, *errs.AppError) {
+	ctx, done := observe(ctx, "order_item", "Create")
+	defer done()
+
 	insertQuery := `INSERT INTO order_items (amount, quantity, order_id, product_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`
 
-	res, sqlxErr := rdb.client.Exec(insertQuery, o.Amount, o.Quantity, o.OrderId, o.ProductId, o.CreatedAt, o.UpdatedAt)
+	res, sqlxErr := rdb.client.ExecContext(ctx, insertQuery, o.Amount, o.Quantity, o.OrderId, o.ProductId, o.CreatedAt, o.UpdatedAt)
 	if sqlxErr != nil {
-		logger.Error("Error while creating new order item" + sqlxErr.Error())
+		logger.FromContext(ctx).Error("Error while creating new order item", zap.Error(sqlxErr))
 		return nil, errs.NewUnexpectedError("unexpected database error")
 	}
 
 	id, sqlxErr := res.LastInsertId()
 	if sqlxErr != nil {
-		logger.Error("Error while getting last insert id for new order item " + sqlxErr.Error())
+		logger.FromContext(ctx).Error("Error while getting last insert id for new order item", zap.Error(sqlxErr))
 		return nil, errs.NewUnexpectedError("unexpected database error")
 	}
 
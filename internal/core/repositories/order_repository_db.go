@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/go-ms-project-store/internal/pkg/logger"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
 )
 
 type OrderRepositoryDB struct {
@@ -19,11 +21,14 @@ type OrderRepositoryDB struct {
 	orderItemRepo ports.OrderItemRepository
 }
 
-func (rdb OrderRepositoryDB) Create(o domain.Order) (*domain.Order, *errs.AppError) {
+func (rdb OrderRepositoryDB) Create(ctx context.Context, o domain.Order) (*domain.Order, *errs.AppError) {
+	ctx, done := observe(ctx, "order", "Create")
+	defer done()
+
 	// Start transaction
-	tx, err := rdb.client.Beginx()
+	tx, err := rdb.client.BeginTxx(ctx, nil)
 	if err != nil {
-		logger.Error("Error while starting transaction: " + err.Error())
+		logger.FromContext(ctx).Error("Error while starting transaction:", zap.Error(err))
 		return nil, errs.NewUnexpectedError("unexpected database error")
 	}
 
@@ -34,7 +39,7 @@ func (rdb OrderRepositoryDB) Create(o domain.Order) (*domain.Order, *errs.AppErr
 	insertOrderQuery := `INSERT INTO orders (uuid, external_id, status, amount, user_id, created_at, updated_at) 
                         VALUES (?, ?, ?, ?, ?, ?, ?)`
 
-	result, err := tx.Exec(insertOrderQuery,
+	result, err := tx.ExecContext(ctx, insertOrderQuery,
 		o.UUID,
 		o.ExternalId,
 		o.Status,
@@ -43,14 +48,14 @@ func (rdb OrderRepositoryDB) Create(o domain.Order) (*domain.Order, *errs.AppErr
 		o.CreatedAt,
 		o.UpdatedAt)
 	if err != nil {
-		logger.Error("Error while creating new order: " + err.Error())
+		logger.FromContext(ctx).Error("Error while creating new order:", zap.Error(err))
 		return nil, errs.NewUnexpectedError("unexpected database error")
 	}
 
 	// Get the last inserted order ID
 	orderId, err := result.LastInsertId()
 	if err != nil {
-		logger.Error("Error while getting last insert id for new order: " + err.Error())
+		logger.FromContext(ctx).Error("Error while getting last insert id for new order:", zap.Error(err))
 		return nil, errs.NewUnexpectedError("unexpected database error")
 	}
 
@@ -59,7 +64,7 @@ func (rdb OrderRepositoryDB) Create(o domain.Order) (*domain.Order, *errs.AppErr
                            VALUES (?, ?, ?, ?, ?, ?)`
 
 	for _, item := range o.OrderItems {
-		_, err = tx.Exec(insertOrderItemQuery,
+		_, err = tx.ExecContext(ctx, insertOrderItemQuery,
 			orderId,
 			item.ProductId,
 			item.Quantity,
@@ -68,22 +73,25 @@ func (rdb OrderRepositoryDB) Create(o domain.Order) (*domain.Order, *errs.AppErr
 			item.UpdatedAt)
 
 		if err != nil {
-			logger.Error("Error while creating order item: " + err.Error())
+			logger.FromContext(ctx).Error("Error while creating order item:", zap.Error(err))
 			return nil, errs.NewUnexpectedError("unexpected database error")
 		}
 	}
 
 	// Commit transaction
 	if err = tx.Commit(); err != nil {
-		logger.Error("Error while committing transaction: " + err.Error())
+		logger.FromContext(ctx).Error("Error while committing transaction:", zap.Error(err))
 		return nil, errs.NewUnexpectedError("unexpected database error")
 	}
 
 	// Fetch the complete order with items and product information
-	return rdb.FindById(uint64(orderId))
+	return rdb.FindById(ctx, uint64(orderId))
 }
 
-func (rdb OrderRepositoryDB) FindById(id uint64) (*domain.Order, *errs.AppError) {
+func (rdb OrderRepositoryDB) FindById(ctx context.Context, id uint64) (*domain.Order, *errs.AppError) {
+	ctx, done := observe(ctx, "order", "FindById")
+	defer done()
+
 	query := `
         SELECT 
             o.id,
@@ -116,12 +124,12 @@ func (rdb OrderRepositoryDB) FindById(id uint64) (*domain.Order, *errs.AppError)
         WHERE o.id = ?
     `
 
-	rows, err := rdb.client.Queryx(query, id)
+	rows, err := rdb.client.QueryxContext(ctx, query, id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errs.NewNotFoundError("Order not found")
 		}
-		logger.Error("Error while querying order table " + err.Error())
+		logger.FromContext(ctx).Error("Error while querying order table", zap.Error(err))
 		return nil, errs.NewUnexpectedError("unexpected database error")
 	}
 	defer rows.Close()
@@ -157,7 +165,7 @@ func (rdb OrderRepositoryDB) FindById(id uint64) (*domain.Order, *errs.AppError)
 		}
 
 		if err := rows.StructScan(&row); err != nil {
-			logger.Error("Error while scanning order row " + err.Error())
+			logger.FromContext(ctx).Error("Error while scanning order row", zap.Error(err))
 			return nil, errs.NewUnexpectedError("unexpected database error")
 		}
 
@@ -233,6 +241,38 @@ func (rdb OrderRepositoryDB) FindById(id uint64) (*domain.Order, *errs.AppError)
 	return order, nil
 }
 
+// RecomputeTotals sets the amount of every order to the sum of its items and
+// returns how many orders were corrected. Orders without items are left as is.
+func (rdb OrderRepositoryDB) RecomputeTotals(ctx context.Context) (int64, *errs.AppError) {
+	ctx, done := observe(ctx, "order", "RecomputeTotals")
+	defer done()
+
+	query := `
+        UPDATE orders o
+        JOIN (
+            SELECT order_id, SUM(amount * quantity) AS total
+            FROM order_items
+            GROUP BY order_id
+        ) items ON items.order_id = o.id
+        SET o.amount = items.total
+        WHERE o.amount != items.total
+    `
+
+	result, err := rdb.client.ExecContext(ctx, query)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while recomputing order totals", zap.Error(err))
+		return 0, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while getting rows affected", zap.Error(err))
+		return 0, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return rowsAffected, nil
+}
+
 func (rdb OrderRepositoryDB) ProductRepo() ports.ProductRepository {
 	return rdb.productRepo
 }
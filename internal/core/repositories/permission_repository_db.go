@@ -0,0 +1,204 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/go-ms-project-store/internal/core/domain"
+	"github.com/go-ms-project-store/internal/pkg/db"
+	"github.com/go-ms-project-store/internal/pkg/errs"
+	"github.com/go-ms-project-store/internal/pkg/logger"
+	"github.com/go-ms-project-store/internal/pkg/pagination"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+type PermissionRepositoryDB struct {
+	client   *sqlx.DB
+	verifier *db.FieldVerifier
+}
+
+func (rdb PermissionRepositoryDB) Create(ctx context.Context, p domain.Permission) (*domain.Permission, *errs.AppError) {
+	ctx, done := observe(ctx, "permission", "Create")
+	defer done()
+
+	if err := rdb.verifier.VerifyUniqueField(ctx, "name", p.Name, 0); err != nil {
+		return nil, err
+	}
+
+	insertQuery := `INSERT INTO permissions (name, created_at, updated_at) VALUES (?, ?, ?)`
+
+	res, sqlxErr := rdb.client.ExecContext(ctx, insertQuery, p.Name, p.CreatedAt, p.UpdatedAt)
+	if sqlxErr != nil {
+		logger.FromContext(ctx).Error("Error while creating new permission", zap.Error(sqlxErr))
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	id, sqlxErr := res.LastInsertId()
+	if sqlxErr != nil {
+		logger.FromContext(ctx).Error("Error while getting last insert id for new permission", zap.Error(sqlxErr))
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	p.Id = id
+
+	return &p, nil
+}
+
+func (rdb PermissionRepositoryDB) Delete(ctx context.Context, id int) *errs.AppError {
+	ctx, done := observe(ctx, "permission", "Delete")
+	defer done()
+
+	tx, err := rdb.client.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while starting transaction:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM permission_role WHERE permission_id = ?`, id)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while detaching permission from roles:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM permissions WHERE id = ?`, id)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while deleting permission:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.FromContext(ctx).Error("Error getting rows affected:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	if rowsAffected == 0 {
+		return errs.NewNotFoundError("Permission not found")
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.FromContext(ctx).Error("Error while committing transaction:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return nil
+}
+
+func (rdb PermissionRepositoryDB) FindAll(ctx context.Context, filter pagination.DataDBFilter) (domain.Permissions, int64, *errs.AppError) {
+	ctx, done := observe(ctx, "permission", "FindAll")
+	defer done()
+
+	var total int64
+	permissions := domain.Permissions{}
+
+	err := rdb.client.GetContext(ctx, &total, `SELECT COUNT(*) FROM permissions`)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while counting permission table", zap.Error(err))
+		return nil, 0, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	query := fmt.Sprintf(`
+	SELECT
+		id,
+		name,
+		created_at,
+		updated_at
+	FROM permissions
+	ORDER BY %s %s
+	LIMIT ? OFFSET ?
+    `,
+		filter.OrderBy,
+		filter.OrderDir)
+
+	offset := (filter.Page - 1) * filter.PerPage
+
+	err = rdb.client.SelectContext(ctx, &permissions, query, filter.PerPage, offset)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while querying permission table", zap.Error(err))
+		return nil, 0, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return permissions, total, nil
+}
+
+func (rdb PermissionRepositoryDB) FindById(ctx context.Context, id int) (*domain.Permission, *errs.AppError) {
+	ctx, done := observe(ctx, "permission", "FindById")
+	defer done()
+
+	query := `SELECT
+		id,
+		name,
+		created_at,
+		updated_at
+	FROM permissions
+	WHERE id = ?
+    `
+
+	var permission domain.Permission
+
+	err := rdb.client.GetContext(ctx, &permission, query, id)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errs.NewNotFoundError("Permission not found")
+		} else {
+			logger.FromContext(ctx).Error("Error while querying permission table", zap.Error(err))
+			return nil, errs.NewUnexpectedError("unexpected database error")
+		}
+	}
+
+	return &permission, nil
+}
+
+func (rdb PermissionRepositoryDB) Update(ctx context.Context, p domain.Permission) (*domain.Permission, *errs.AppError) {
+	ctx, done := observe(ctx, "permission", "Update")
+	defer done()
+
+	existingPermission, errPkg := rdb.FindById(ctx, int(p.Id))
+	if errPkg != nil {
+		return nil, errs.NewNotFoundError("Permission not found")
+	}
+
+	if err := rdb.verifier.VerifyUniqueField(ctx, "name", p.Name, p.Id); err != nil {
+		return nil, err
+	}
+
+	updateQuery := `UPDATE permissions SET name = ?, updated_at = ? WHERE id = ?`
+	result, err := rdb.client.ExecContext(ctx, updateQuery, p.Name, p.UpdatedAt, p.Id)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while updating permission:", zap.Error(err))
+		return nil, errs.NewUnexpectedError("Unexpected database error")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.FromContext(ctx).Error("Error getting rows affected:", zap.Error(err))
+		return nil, errs.NewUnexpectedError("Unexpected database error")
+	}
+
+	if rowsAffected == 0 {
+		return existingPermission, nil
+	}
+
+	updatedPermission, errPkg := rdb.FindById(ctx, int(p.Id))
+	if errPkg != nil {
+		return nil, errs.NewUnexpectedError("Error fetching updated permission")
+	}
+
+	return updatedPermission, nil
+}
+
+func NewPermissionRepositoryDB(dbClient *sqlx.DB) PermissionRepositoryDB {
+	return PermissionRepositoryDB{
+		client: dbClient,
+		verifier: &db.FieldVerifier{
+			DB:        dbClient,
+			TableName: "permissions",
+		},
+	}
+}
@@ -14,6 +14,7 @@ import (
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/gosimple/slug"
 	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
 )
 
 type ProductRepositoryDB struct {
@@ -21,20 +22,23 @@ type ProductRepositoryDB struct {
 	verifier *db.FieldVerifier
 }
 
-func (rdb ProductRepositoryDB) Create(p domain.Product) (*domain.Product, *errs.AppError) {
+func (rdb ProductRepositoryDB) Create(ctx context.Context, p domain.Product) (*domain.Product, *errs.AppError) {
+	ctx, done := observe(ctx, "product", "Create")
+	defer done()
+
 	var finalSlug string
 	var nameExists *domain.Product
 	crb := NewCategoryRepositoryDB(rdb.client)
 
-	categoryExists, appError := crb.FindById(int(p.CategoryId))
+	categoryExists, appError := crb.FindById(ctx, int(p.CategoryId))
 	if appError != nil {
-		logger.Error("Error while creating new product, category does not exist")
+		logger.FromContext(ctx).Error("Error while creating new product, category does not exist")
 		return nil, errs.NewValidationError("category_id", "The category does not exist")
 	}
 
-	nameExists, _ = rdb.FindByName(p.Name)
+	nameExists, _ = rdb.FindByName(ctx, p.Name)
 	if nameExists != nil {
-		logger.Error("Error while creating new product, name already exists")
+		logger.FromContext(ctx).Error("Error while creating new product, name already exists")
 		return nil, errs.NewValidationError("name", "The name has already been taken")
 	}
 
@@ -51,7 +55,7 @@ func (rdb ProductRepositoryDB) Create(p domain.Product) (*domain.Product, *errs.
 	counter := 1
 	for {
 		// Try to find if the current slug exists
-		existing, err := rdb.FindBySlug(finalSlug)
+		existing, err := rdb.FindBySlug(ctx, finalSlug)
 		if err != nil {
 			// If error is because slug doesn't exist, we can use this slug
 			break
@@ -77,7 +81,7 @@ func (rdb ProductRepositoryDB) Create(p domain.Product) (*domain.Product, *errs.
 		updated_at) 
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
-	res, sqlxErr := rdb.client.Exec(
+	res, sqlxErr := rdb.client.ExecContext(ctx,
 		insertQuery,
 		p.Name,
 		finalSlug,
@@ -89,13 +93,13 @@ func (rdb ProductRepositoryDB) Create(p domain.Product) (*domain.Product, *errs.
 		p.CreatedAt,
 		p.UpdatedAt)
 	if sqlxErr != nil {
-		logger.Error("Error while creating new product " + sqlxErr.Error())
+		logger.FromContext(ctx).Error("Error while creating new product", zap.Error(sqlxErr))
 		return nil, errs.NewUnexpectedError("unexpected database error")
 	}
 
 	id, sqlxErr := res.LastInsertId()
 	if sqlxErr != nil {
-		logger.Error("Error while getting last insert id for new product " + sqlxErr.Error())
+		logger.FromContext(ctx).Error("Error while getting last insert id for new product", zap.Error(sqlxErr))
 		return nil, errs.NewUnexpectedError("unexpected database error")
 	}
 
@@ -106,18 +110,21 @@ func (rdb ProductRepositoryDB) Create(p domain.Product) (*domain.Product, *errs.
 	return &p, nil
 }
 
-func (rdb ProductRepositoryDB) Delete(id int) *errs.AppError {
+func (rdb ProductRepositoryDB) Delete(ctx context.Context, id int) *errs.AppError {
+	ctx, done := observe(ctx, "product", "Delete")
+	defer done()
+
 	query := `DELETE FROM products WHERE id = ?`
 
-	result, err := rdb.client.ExecContext(context.Background(), query, id)
+	result, err := rdb.client.ExecContext(ctx, query, id)
 	if err != nil {
-		logger.Error("Error while deleting product: " + err.Error())
+		logger.FromContext(ctx).Error("Error while deleting product:", zap.Error(err))
 		return errs.NewUnexpectedError("unexpected database error")
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		logger.Error("Error getting rows affected: " + err.Error())
+		logger.FromContext(ctx).Error("Error getting rows affected:", zap.Error(err))
 		return errs.NewUnexpectedError("unexpected database error")
 	}
 
@@ -128,7 +135,10 @@ func (rdb ProductRepositoryDB) Delete(id int) *errs.AppError {
 	return nil
 }
 
-func (rdb ProductRepositoryDB) FindById(id int) (*domain.Product, *errs.AppError) {
+func (rdb ProductRepositoryDB) FindById(ctx context.Context, id int) (*domain.Product, *errs.AppError) {
+	ctx, done := observe(ctx, "product", "FindById")
+	defer done()
+
 	query := `
     SELECT 
         p.id,
@@ -150,18 +160,21 @@ func (rdb ProductRepositoryDB) FindById(id int) (*domain.Product, *errs.AppError
     LEFT JOIN categories c ON p.category_id = c.id
     WHERE p.id = ?`
 
-	row := rdb.client.QueryRowx(query, id)
-	return rdb.scanProduct(row)
+	row := rdb.client.QueryRowxContext(ctx, query, id)
+	return rdb.scanProduct(ctx, row)
 }
 
-func (rdb ProductRepositoryDB) FindAll(filter pagination.DataDBFilter) (domain.Products, int64, *errs.AppError) {
+func (rdb ProductRepositoryDB) FindAll(ctx context.Context, filter pagination.DataDBFilter) (domain.Products, int64, *errs.AppError) {
+	ctx, done := observe(ctx, "product", "FindAll")
+	defer done()
+
 	var total int64
 	products := domain.Products{}
 
 	countQuery := `SELECT COUNT(*) FROM products`
-	err := rdb.client.Get(&total, countQuery)
+	err := rdb.client.GetContext(ctx, &total, countQuery)
 	if err != nil {
-		logger.Error("Error while counting product table " + err.Error())
+		logger.FromContext(ctx).Error("Error while counting product table", zap.Error(err))
 		return nil, 0, errs.NewUnexpectedError("unexpected database error")
 	}
 
@@ -196,15 +209,15 @@ func (rdb ProductRepositoryDB) FindAll(filter pagination.DataDBFilter) (domain.P
 
 	offset := (filter.Page - 1) * filter.PerPage
 
-	rows, err := rdb.client.Queryx(query, filter.PerPage, offset)
+	rows, err := rdb.client.QueryxContext(ctx, query, filter.PerPage, offset)
 	if err != nil {
-		logger.Error("Error while querying product table " + err.Error())
+		logger.FromContext(ctx).Error("Error while querying product table", zap.Error(err))
 		return nil, 0, errs.NewUnexpectedError("unexpected database error")
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		product, err := rdb.scanProducts(rows)
+		product, err := rdb.scanProducts(ctx, rows)
 		if err != nil {
 			return nil, 0, err
 		}
@@ -212,44 +225,53 @@ func (rdb ProductRepositoryDB) FindAll(filter pagination.DataDBFilter) (domain.P
 	}
 
 	if err = rows.Err(); err != nil {
-		logger.Error("Error after iterating over product rows " + err.Error())
+		logger.FromContext(ctx).Error("Error after iterating over product rows", zap.Error(err))
 		return nil, 0, errs.NewUnexpectedError("unexpected database error")
 	}
 
 	return products, total, nil
 }
 
-func (rdb ProductRepositoryDB) FindByName(name string) (*domain.Product, *errs.AppError) {
-	return rdb.findByField("p.name", name)
+func (rdb ProductRepositoryDB) FindByName(ctx context.Context, name string) (*domain.Product, *errs.AppError) {
+	ctx, done := observe(ctx, "product", "FindByName")
+	defer done()
+
+	return rdb.findByField(ctx, "p.name", name)
 }
 
-func (rdb ProductRepositoryDB) FindBySlug(slug string) (*domain.Product, *errs.AppError) {
-	return rdb.findByField("p.slug", slug)
+func (rdb ProductRepositoryDB) FindBySlug(ctx context.Context, slug string) (*domain.Product, *errs.AppError) {
+	ctx, done := observe(ctx, "product", "FindBySlug")
+	defer done()
+
+	return rdb.findByField(ctx, "p.slug", slug)
 }
 
-func (rdb ProductRepositoryDB) Update(p domain.Product) (*domain.Product, *errs.AppError) {
+func (rdb ProductRepositoryDB) Update(ctx context.Context, p domain.Product) (*domain.Product, *errs.AppError) {
+	ctx, done := observe(ctx, "product", "Update")
+	defer done()
+
 	var err error
 	crb := NewCategoryRepositoryDB(rdb.client)
 
-	categoryExists, appError := crb.FindById(int(p.CategoryId))
+	categoryExists, appError := crb.FindById(ctx, int(p.CategoryId))
 	if appError != nil {
-		logger.Error("Error while creating new product, category does not exist")
+		logger.FromContext(ctx).Error("Error while creating new product, category does not exist")
 		return nil, errs.NewValidationError("category_id", "The category does not exist")
 	}
 
 	// First, check if the product exists
-	existingProduct, errPkg := rdb.FindById(int(p.Id))
+	existingProduct, errPkg := rdb.FindById(ctx, int(p.Id))
 	if errPkg != nil {
 		return nil, errs.NewNotFoundError("Product not found")
 	}
 
 	// Verify name uniqueness
-	if err := rdb.verifier.VerifyUniqueField("name", p.Name, p.Id); err != nil {
+	if err := rdb.verifier.VerifyUniqueField(ctx, "name", p.Name, p.Id); err != nil {
 		return nil, err
 	}
 
 	// Verify slug uniqueness
-	if err := rdb.verifier.VerifyUniqueField("slug", p.Slug, p.Id); err != nil {
+	if err := rdb.verifier.VerifyUniqueField(ctx, "slug", p.Slug, p.Id); err != nil {
 		return nil, err
 	}
 
@@ -262,15 +284,15 @@ func (rdb ProductRepositoryDB) Update(p domain.Product) (*domain.Product, *errs.
 		amount = ? 
 		WHERE id = ?`
 
-	result, err := rdb.client.Exec(updateQuery, p.Name, p.Slug, p.CategoryId, p.Description, p.Amount, p.Id)
+	result, err := rdb.client.ExecContext(ctx, updateQuery, p.Name, p.Slug, p.CategoryId, p.Description, p.Amount, p.Id)
 	if err != nil {
-		logger.Error("Error while updating product: " + err.Error())
+		logger.FromContext(ctx).Error("Error while updating product:", zap.Error(err))
 		return nil, errs.NewUnexpectedError("Unexpected database error")
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		logger.Error("Error getting rows affected: " + err.Error())
+		logger.FromContext(ctx).Error("Error getting rows affected:", zap.Error(err))
 		return nil, errs.NewUnexpectedError("Unexpected database error")
 	}
 
@@ -279,7 +301,7 @@ func (rdb ProductRepositoryDB) Update(p domain.Product) (*domain.Product, *errs.
 	}
 
 	// Fetch the updated product
-	updatedProduct, errPkg := rdb.FindById(int(p.Id))
+	updatedProduct, errPkg := rdb.FindById(ctx, int(p.Id))
 	if errPkg != nil {
 		return nil, errs.NewUnexpectedError("Error fetching updated product")
 	}
@@ -289,7 +311,10 @@ func (rdb ProductRepositoryDB) Update(p domain.Product) (*domain.Product, *errs.
 	return updatedProduct, nil
 }
 
-func (rdb ProductRepositoryDB) WhereIn(uuids []string) ([]domain.Product, *errs.AppError) {
+func (rdb ProductRepositoryDB) WhereIn(ctx context.Context, uuids []string) ([]domain.Product, *errs.AppError) {
+	ctx, done := observe(ctx, "product", "WhereIn")
+	defer done()
+
 	if len(uuids) == 0 {
 		return []domain.Product{}, nil
 	}
@@ -308,9 +333,9 @@ func (rdb ProductRepositoryDB) WhereIn(uuids []string) ([]domain.Product, *errs.
         WHERE uuid IN (%s)`,
 		strings.Join(placeholders, ","))
 
-	rows, err := rdb.client.Queryx(query, args...)
+	rows, err := rdb.client.QueryxContext(ctx, query, args...)
 	if err != nil {
-		logger.Error("Error while querying products with UUIDs: " + err.Error())
+		logger.FromContext(ctx).Error("Error while querying products with UUIDs:", zap.Error(err))
 		return nil, errs.NewUnexpectedError("unexpected database error")
 	}
 	defer rows.Close()
@@ -319,14 +344,14 @@ func (rdb ProductRepositoryDB) WhereIn(uuids []string) ([]domain.Product, *errs.
 	for rows.Next() {
 		var product domain.Product
 		if err := rows.Scan(&product.Id, &product.Amount, &product.UUID); err != nil {
-			logger.Error("Error while scanning product: " + err.Error())
+			logger.FromContext(ctx).Error("Error while scanning product:", zap.Error(err))
 			return nil, errs.NewUnexpectedError("unexpected database error")
 		}
 		products = append(products, product)
 	}
 
 	if err = rows.Err(); err != nil {
-		logger.Error("Error after iterating over product rows: " + err.Error())
+		logger.FromContext(ctx).Error("Error after iterating over product rows:", zap.Error(err))
 		return nil, errs.NewUnexpectedError("unexpected database error")
 	}
 
@@ -343,7 +368,7 @@ func NewProductRepositoryDB(dbClient *sqlx.DB) ProductRepositoryDB {
 	}
 }
 
-func (rdb ProductRepositoryDB) findByField(field, value string) (*domain.Product, *errs.AppError) {
+func (rdb ProductRepositoryDB) findByField(ctx context.Context, field, value string) (*domain.Product, *errs.AppError) {
 	query := `SELECT
        	p.id,
         p.uuid,
@@ -364,8 +389,8 @@ func (rdb ProductRepositoryDB) findByField(field, value string) (*domain.Product
 	LEFT JOIN categories c ON p.category_id = c.id
     WHERE ` + field + ` = ?`
 
-	row := rdb.client.QueryRowx(query, value)
-	return rdb.scanProduct(row)
+	row := rdb.client.QueryRowxContext(ctx, query, value)
+	return rdb.scanProduct(ctx, row)
 }
 
 func (rdb ProductRepositoryDB) processProduct(product *domain.Product, category *domain.Category, uuidBytes []byte) (*domain.Product, *errs.AppError) {
@@ -379,7 +404,7 @@ func (rdb ProductRepositoryDB) processProduct(product *domain.Product, category
 	return product, nil
 }
 
-func (rdb ProductRepositoryDB) scanProduct(row *sqlx.Row) (*domain.Product, *errs.AppError) {
+func (rdb ProductRepositoryDB) scanProduct(ctx context.Context, row *sqlx.Row) (*domain.Product, *errs.AppError) {
 	var product domain.Product
 	var category domain.Category
 	var uuidBytes []byte
@@ -406,14 +431,14 @@ func (rdb ProductRepositoryDB) scanProduct(row *sqlx.Row) (*domain.Product, *err
 		if err == sql.ErrNoRows {
 			return nil, errs.NewNotFoundError("product not found")
 		}
-		logger.Error("Error while scanning product row " + err.Error())
+		logger.FromContext(ctx).Error("Error while scanning product row", zap.Error(err))
 		return nil, errs.NewUnexpectedError("unexpected database error")
 	}
 
 	return rdb.processProduct(&product, &category, uuidBytes)
 }
 
-func (rdb ProductRepositoryDB) scanProducts(rows *sqlx.Rows) (*domain.Product, *errs.AppError) {
+func (rdb ProductRepositoryDB) scanProducts(ctx context.Context, rows *sqlx.Rows) (*domain.Product, *errs.AppError) {
 	var product domain.Product
 	var category domain.Category
 	var uuidBytes []byte
@@ -437,7 +462,7 @@ func (rdb ProductRepositoryDB) scanProducts(rows *sqlx.Rows) (*domain.Product, *
 	)
 
 	if err != nil {
-		logger.Error("Error while scanning product row " + err.Error())
+		logger.FromContext(ctx).Error("Error while scanning product row", zap.Error(err))
 		return nil, errs.NewUnexpectedError("unexpected database error")
 	}
 
@@ -1,14 +1,18 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 
 	"github.com/go-ms-project-store/internal/core/domain"
 	"github.com/go-ms-project-store/internal/pkg/db"
 	"github.com/go-ms-project-store/internal/pkg/errs"
 	"github.com/go-ms-project-store/internal/pkg/logger"
+	"github.com/go-ms-project-store/internal/pkg/pagination"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
 )
 
 type RoleRepositoryDB struct {
@@ -16,32 +20,275 @@ type RoleRepositoryDB struct {
 	verifier *db.FieldVerifier
 }
 
-func (rdb RoleRepositoryDB) FindByName(name string) (*domain.Role, *errs.AppError) {
+func (rdb RoleRepositoryDB) AttachPermissions(ctx context.Context, roleId int64, permissionIds []int64) *errs.AppError {
+	ctx, done := observe(ctx, "role", "AttachPermissions")
+	defer done()
 
-	// Prepare query
-	query := `SELECT
+	query, args, err := sqlx.In(`SELECT COUNT(*) FROM permissions WHERE id IN (?)`, permissionIds)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while building permissions query", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	var found int
+	err = rdb.client.GetContext(ctx, &found, rdb.client.Rebind(query), args...)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while counting permissions", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	if found != len(uniqueIds(permissionIds)) {
+		return errs.NewValidationError("permissions", "One or more permissions do not exist")
+	}
+
+	insertQuery := `INSERT IGNORE INTO permission_role (permission_id, role_id) VALUES (?, ?)`
+	for _, permissionId := range permissionIds {
+		_, err = rdb.client.ExecContext(ctx, insertQuery, permissionId, roleId)
+		if err != nil {
+			logger.FromContext(ctx).Error("Error while attaching permission to role", zap.Error(err))
+			return errs.NewUnexpectedError("unexpected database error")
+		}
+	}
+
+	return nil
+}
+
+func (rdb RoleRepositoryDB) CountUsers(ctx context.Context, roleId int64) (int64, *errs.AppError) {
+	ctx, done := observe(ctx, "role", "CountUsers")
+	defer done()
+
+	var total int64
+
+	err := rdb.client.GetContext(ctx, &total, `SELECT COUNT(*) FROM users WHERE role_id = ?`, roleId)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while counting users for role", zap.Error(err))
+		return 0, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return total, nil
+}
+
+func (rdb RoleRepositoryDB) Create(ctx context.Context, r domain.Role) (*domain.Role, *errs.AppError) {
+	ctx, done := observe(ctx, "role", "Create")
+	defer done()
+
+	if err := rdb.verifier.VerifyUniqueField(ctx, "name", r.Name, 0); err != nil {
+		return nil, err
+	}
+
+	insertQuery := `INSERT INTO roles (name, requires_two_factor, created_at, updated_at) VALUES (?, ?, ?, ?)`
+
+	res, sqlxErr := rdb.client.ExecContext(ctx, insertQuery, r.Name, r.RequiresTwoFactor, r.CreatedAt, r.UpdatedAt)
+	if sqlxErr != nil {
+		logger.FromContext(ctx).Error("Error while creating new role", zap.Error(sqlxErr))
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	id, sqlxErr := res.LastInsertId()
+	if sqlxErr != nil {
+		logger.FromContext(ctx).Error("Error while getting last insert id for new role", zap.Error(sqlxErr))
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	r.Id = id
+
+	return &r, nil
+}
+
+func (rdb RoleRepositoryDB) Delete(ctx context.Context, id int) *errs.AppError {
+	ctx, done := observe(ctx, "role", "Delete")
+	defer done()
+
+	tx, err := rdb.client.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while starting transaction:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM permission_role WHERE role_id = ?`, id)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while detaching role permissions:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM roles WHERE id = ?`, id)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while deleting role:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.FromContext(ctx).Error("Error getting rows affected:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	if rowsAffected == 0 {
+		return errs.NewNotFoundError("Role not found")
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.FromContext(ctx).Error("Error while committing transaction:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return nil
+}
+
+func (rdb RoleRepositoryDB) DetachPermission(ctx context.Context, roleId int64, permissionId int64) *errs.AppError {
+	ctx, done := observe(ctx, "role", "DetachPermission")
+	defer done()
+
+	query := `DELETE FROM permission_role WHERE role_id = ? AND permission_id = ?`
+
+	result, err := rdb.client.ExecContext(ctx, query, roleId, permissionId)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while detaching permission from role:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.FromContext(ctx).Error("Error getting rows affected:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	if rowsAffected == 0 {
+		return errs.NewNotFoundError("Permission is not attached to this role")
+	}
+
+	return nil
+}
+
+func (rdb RoleRepositoryDB) FindAll(ctx context.Context, filter pagination.DataDBFilter) (domain.Roles, int64, *errs.AppError) {
+	ctx, done := observe(ctx, "role", "FindAll")
+	defer done()
+
+	var total int64
+	roles := domain.Roles{}
+
+	err := rdb.client.GetContext(ctx, &total, `SELECT COUNT(*) FROM roles`)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while counting role table", zap.Error(err))
+		return nil, 0, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	query := fmt.Sprintf(`
+	SELECT
 		id,
 		name,
+		requires_two_factor,
 		created_at,
 		updated_at
 	FROM roles
-	WHERE name = ?
+	ORDER BY %s %s
+	LIMIT ? OFFSET ?
+    `,
+		filter.OrderBy,
+		filter.OrderDir)
+
+	offset := (filter.Page - 1) * filter.PerPage
+
+	err = rdb.client.SelectContext(ctx, &roles, query, filter.PerPage, offset)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while querying role table", zap.Error(err))
+		return nil, 0, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	if appErr := rdb.loadPermissions(ctx, roles); appErr != nil {
+		return nil, 0, appErr
+	}
+
+	return roles, total, nil
+}
+
+func (rdb RoleRepositoryDB) FindById(ctx context.Context, id int) (*domain.Role, *errs.AppError) {
+	ctx, done := observe(ctx, "role", "FindById")
+	defer done()
+
+	return rdb.findRoleBy(ctx, "id", id)
+}
+
+func (rdb RoleRepositoryDB) FindByName(ctx context.Context, name string) (*domain.Role, *errs.AppError) {
+	ctx, done := observe(ctx, "role", "FindByName")
+	defer done()
+
+	return rdb.findRoleBy(ctx, "name", name)
+}
+
+// FindByUserId returns the role assigned to a user, with its permissions
+func (rdb RoleRepositoryDB) FindByUserId(ctx context.Context, userId uint64) (*domain.Role, *errs.AppError) {
+	ctx, done := observe(ctx, "role", "FindByUserId")
+	defer done()
+
+	query := `SELECT
+		r.id,
+		r.name,
+		r.requires_two_factor,
+		r.created_at,
+		r.updated_at
+	FROM roles r
+	JOIN users u ON u.role_id = r.id
+	WHERE u.id = ?
     `
 
 	var role domain.Role
 
-	err := rdb.client.Get(&role, query, name)
-
+	err := rdb.client.GetContext(ctx, &role, query, userId)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errs.NewNotFoundError("Role not found")
-		} else {
-			logger.Error("Error while querying role table " + err.Error())
-			return nil, errs.NewUnexpectedError("unexpected database error")
 		}
+		logger.FromContext(ctx).Error("Error while querying role table", zap.Error(err))
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	roles := domain.Roles{role}
+	if appErr := rdb.loadPermissions(ctx, roles); appErr != nil {
+		return nil, appErr
 	}
 
-	return &role, nil
+	return &roles[0], nil
+}
+
+func (rdb RoleRepositoryDB) Update(ctx context.Context, r domain.Role) (*domain.Role, *errs.AppError) {
+	ctx, done := observe(ctx, "role", "Update")
+	defer done()
+
+	existingRole, errPkg := rdb.FindById(ctx, int(r.Id))
+	if errPkg != nil {
+		return nil, errs.NewNotFoundError("Role not found")
+	}
+
+	if err := rdb.verifier.VerifyUniqueField(ctx, "name", r.Name, r.Id); err != nil {
+		return nil, err
+	}
+
+	updateQuery := `UPDATE roles SET name = ?, requires_two_factor = ?, updated_at = ? WHERE id = ?`
+	result, err := rdb.client.ExecContext(ctx, updateQuery, r.Name, r.RequiresTwoFactor, r.UpdatedAt, r.Id)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while updating role:", zap.Error(err))
+		return nil, errs.NewUnexpectedError("Unexpected database error")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.FromContext(ctx).Error("Error getting rows affected:", zap.Error(err))
+		return nil, errs.NewUnexpectedError("Unexpected database error")
+	}
+
+	if rowsAffected == 0 {
+		return existingRole, nil
+	}
+
+	updatedRole, errPkg := rdb.FindById(ctx, int(r.Id))
+	if errPkg != nil {
+		return nil, errs.NewUnexpectedError("Error fetching updated role")
+	}
+
+	return updatedRole, nil
 }
 
 func NewRoleRepositoryDB(dbClient *sqlx.DB) RoleRepositoryDB {
@@ -53,3 +300,113 @@ func NewRoleRepositoryDB(dbClient *sqlx.DB) RoleRepositoryDB {
 		},
 	}
 }
+
+func (rdb RoleRepositoryDB) findRoleBy(ctx context.Context, field string, value interface{}) (*domain.Role, *errs.AppError) {
+	query := `SELECT
+		id,
+		name,
+		requires_two_factor,
+		created_at,
+		updated_at
+	FROM roles
+	WHERE ` + field + ` = ?
+    `
+
+	var role domain.Role
+
+	err := rdb.client.GetContext(ctx, &role, query, value)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errs.NewNotFoundError("Role not found")
+		} else {
+			logger.FromContext(ctx).Error("Error while querying role table", zap.Error(err))
+			return nil, errs.NewUnexpectedError("unexpected database error")
+		}
+	}
+
+	roles := domain.Roles{role}
+	if appErr := rdb.loadPermissions(ctx, roles); appErr != nil {
+		return nil, appErr
+	}
+
+	return &roles[0], nil
+}
+
+// loadPermissions fills the Permissions of every role with a single query
+func (rdb RoleRepositoryDB) loadPermissions(ctx context.Context, roles domain.Roles) *errs.AppError {
+	if len(roles) == 0 {
+		return nil
+	}
+
+	roleIds := make([]int64, len(roles))
+	for i, role := range roles {
+		roleIds[i] = role.Id
+	}
+
+	query, args, err := sqlx.In(`
+	SELECT
+		pr.role_id,
+		p.id,
+		p.name,
+		p.created_at,
+		p.updated_at
+	FROM permissions p
+	JOIN permission_role pr ON pr.permission_id = p.id
+	WHERE pr.role_id IN (?)
+	ORDER BY p.name`, roleIds)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while building role permissions query", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	rows, err := rdb.client.QueryxContext(ctx, rdb.client.Rebind(query), args...)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while querying role permissions", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+	defer rows.Close()
+
+	permissions := make(map[int64][]domain.Permission)
+	for rows.Next() {
+		var roleId int64
+		var permission domain.Permission
+
+		err := rows.Scan(
+			&roleId,
+			&permission.Id,
+			&permission.Name,
+			&permission.CreatedAt,
+			&permission.UpdatedAt,
+		)
+		if err != nil {
+			logger.FromContext(ctx).Error("Error while scanning role permission row", zap.Error(err))
+			return errs.NewUnexpectedError("unexpected database error")
+		}
+
+		permissions[roleId] = append(permissions[roleId], permission)
+	}
+
+	if err = rows.Err(); err != nil {
+		logger.FromContext(ctx).Error("Error after iterating over role permission rows", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	for i := range roles {
+		roles[i].Permissions = permissions[roles[i].Id]
+	}
+
+	return nil
+}
+
+func uniqueIds(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	unique := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,163 @@
+package repositories
+
+import (
+	"context"
+	"github.com/go-ms-project-store/internal/core/domain"
+	"github.com/go-ms-project-store/internal/pkg/errs"
+	"github.com/go-ms-project-store/internal/pkg/logger"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+type SessionRepositoryDB struct {
+	client *sqlx.DB
+}
+
+func (rdb SessionRepositoryDB) Create(ctx context.Context, s domain.Session) (*domain.Session, *errs.AppError) {
+	ctx, done := observe(ctx, "session", "Create")
+	defer done()
+
+	insertQuery := `INSERT INTO auth_sessions
+		(user_id, device_name, user_agent, ip_address, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)`
+
+	res, sqlxErr := rdb.client.ExecContext(ctx, insertQuery, s.UserId, s.DeviceName, s.UserAgent, s.IpAddress, s.CreatedAt, s.UpdatedAt)
+	if sqlxErr != nil {
+		logger.FromContext(ctx).Error("Error while creating new session", zap.Error(sqlxErr))
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	id, sqlxErr := res.LastInsertId()
+	if sqlxErr != nil {
+		logger.FromContext(ctx).Error("Error while getting last insert id for new session", zap.Error(sqlxErr))
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	s.Id = uint64(id)
+
+	return &s, nil
+}
+
+// Delete ends one session of the user together with its tokens
+func (rdb SessionRepositoryDB) Delete(ctx context.Context, userId uint64, sessionId uint64) *errs.AppError {
+	ctx, done := observe(ctx, "session", "Delete")
+	defer done()
+
+	tx, err := rdb.client.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while starting transaction:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM auth_sessions WHERE id = ? AND user_id = ?`, sessionId, userId)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while deleting session", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.FromContext(ctx).Error("Error getting rows affected:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	if rowsAffected == 0 {
+		return errs.NewNotFoundError("Session not found")
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM personal_access_tokens WHERE session_id = ?`, sessionId)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while deleting session tokens", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.FromContext(ctx).Error("Error while committing transaction:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return nil
+}
+
+// DeleteAll ends every session of the user, including tokens issued outside of one
+func (rdb SessionRepositoryDB) DeleteAll(ctx context.Context, userId uint64) *errs.AppError {
+	ctx, done := observe(ctx, "session", "DeleteAll")
+	defer done()
+
+	_, err := rdb.client.ExecContext(ctx, `DELETE FROM personal_access_tokens WHERE tokenable_id = ?`, userId)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while deleting tokens", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	_, err = rdb.client.ExecContext(ctx, `DELETE FROM auth_sessions WHERE user_id = ?`, userId)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while deleting sessions", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return nil
+}
+
+// DeleteOthers ends every session of the user except the given one.
+// Personal access tokens have no session and are kept.
+func (rdb SessionRepositoryDB) DeleteOthers(ctx context.Context, userId uint64, keepSessionId uint64) *errs.AppError {
+	ctx, done := observe(ctx, "session", "DeleteOthers")
+	defer done()
+
+	_, err := rdb.client.ExecContext(ctx,
+		`DELETE FROM personal_access_tokens WHERE tokenable_id = ? AND session_id IS NOT NULL AND session_id != ?`,
+		userId,
+		keepSessionId,
+	)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while deleting tokens of other sessions", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	_, err = rdb.client.ExecContext(ctx, `DELETE FROM auth_sessions WHERE user_id = ? AND id != ?`, userId, keepSessionId)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while deleting other sessions", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return nil
+}
+
+func (rdb SessionRepositoryDB) FindAllByUser(ctx context.Context, userId uint64) (domain.Sessions, *errs.AppError) {
+	ctx, done := observe(ctx, "session", "FindAllByUser")
+	defer done()
+
+	sessions := domain.Sessions{}
+
+	query := `SELECT
+		id,
+		user_id,
+		device_name,
+		user_agent,
+		ip_address,
+		last_used_at,
+		created_at,
+		updated_at
+	FROM auth_sessions
+	WHERE user_id = ?
+	ORDER BY COALESCE(last_used_at, created_at) DESC
+    `
+
+	err := rdb.client.SelectContext(ctx, &sessions, query, userId)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while querying auth_sessions table", zap.Error(err))
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return sessions, nil
+}
+
+func NewSessionRepositoryDB(dbClient *sqlx.DB) SessionRepositoryDB {
+	return SessionRepositoryDB{
+		client: dbClient,
+	}
+}
@@ -0,0 +1,28 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDeleteOthersKeepsPersonalTokens(t *testing.T) {
+	client, db := newFakeDB(t)
+
+	if err := (SessionRepositoryDB{client: client}).DeleteOthers(context.Background(), 1, 5); err != nil {
+		t.Fatal(err.Message)
+	}
+
+	tokens, ok := db.exec("DELETE FROM personal_access_tokens")
+	if !ok {
+		t.Fatal("the tokens of the other sessions weren't deleted")
+	}
+	// Personal access tokens are the ones without a session
+	if !strings.Contains(tokens.query, "session_id IS NOT NULL") || strings.Contains(tokens.query, "session_id IS NULL") {
+		t.Errorf("tokens deleted with %q, want only session tokens", tokens.query)
+	}
+
+	if _, ok := db.exec("DELETE FROM auth_sessions"); !ok {
+		t.Error("the other sessions weren't deleted")
+	}
+}
@@ -0,0 +1,261 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/go-ms-project-store/internal/core/domain"
+	"github.com/go-ms-project-store/internal/pkg/errs"
+	"github.com/go-ms-project-store/internal/pkg/logger"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+type TwoFactorRepositoryDB struct {
+	client *sqlx.DB
+}
+
+// Confirm activates a pending enrollment, consuming the time step of the
+// code used to confirm it, and stores the hashed recovery codes
+func (rdb TwoFactorRepositoryDB) Confirm(ctx context.Context, userId uint64, step int64, recoveryCodes []string) *errs.AppError {
+	ctx, done := observe(ctx, "two_factor", "Confirm")
+	defer done()
+
+	tx, err := rdb.client.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while starting transaction:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	defer tx.Rollback()
+
+	query := `UPDATE two_factor_credentials 
+              SET confirmed_at = ?, last_used_step = ?, updated_at = ? 
+              WHERE user_id = ? AND confirmed_at IS NULL`
+
+	result, err := tx.ExecContext(ctx, query, time.Now(), step, time.Now(), userId)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while confirming two-factor authentication", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.FromContext(ctx).Error("Error getting rows affected:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	if rowsAffected == 0 {
+		return errs.NewValidationError("code", "Two-factor authentication is not pending confirmation")
+	}
+
+	if appErr := replaceRecoveryCodes(ctx, tx, userId, recoveryCodes); appErr != nil {
+		return appErr
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.FromContext(ctx).Error("Error while committing transaction:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return nil
+}
+
+func (rdb TwoFactorRepositoryDB) Delete(ctx context.Context, userId uint64) *errs.AppError {
+	ctx, done := observe(ctx, "two_factor", "Delete")
+	defer done()
+
+	tx, err := rdb.client.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while starting transaction:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM two_factor_recovery_codes WHERE user_id = ?`, userId)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while deleting recovery codes", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM two_factor_credentials WHERE user_id = ?`, userId)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while deleting two-factor credentials", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.FromContext(ctx).Error("Error getting rows affected:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	if rowsAffected == 0 {
+		return errs.NewNotFoundError("Two-factor authentication is not enabled")
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.FromContext(ctx).Error("Error while committing transaction:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return nil
+}
+
+func (rdb TwoFactorRepositoryDB) FindByUserId(ctx context.Context, userId uint64) (*domain.TwoFactor, *errs.AppError) {
+	ctx, done := observe(ctx, "two_factor", "FindByUserId")
+	defer done()
+
+	query := `SELECT
+		user_id,
+		secret,
+		last_used_step,
+		confirmed_at,
+		created_at,
+		updated_at
+	FROM two_factor_credentials
+	WHERE user_id = ?
+    `
+
+	var twoFactor domain.TwoFactor
+
+	err := rdb.client.GetContext(ctx, &twoFactor, query, userId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errs.NewNotFoundError("Two-factor authentication is not enabled")
+		}
+		logger.FromContext(ctx).Error("Error while querying two_factor_credentials table", zap.Error(err))
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return &twoFactor, nil
+}
+
+// MarkStepUsed records the time step of an accepted code so the same code
+// can't be replayed. It fails when a code of that step was already used.
+func (rdb TwoFactorRepositoryDB) MarkStepUsed(ctx context.Context, userId uint64, step int64) *errs.AppError {
+	ctx, done := observe(ctx, "two_factor", "MarkStepUsed")
+	defer done()
+
+	query := `UPDATE two_factor_credentials 
+              SET last_used_step = ?, updated_at = ? 
+              WHERE user_id = ? AND (last_used_step IS NULL OR last_used_step < ?)`
+
+	result, err := rdb.client.ExecContext(ctx, query, step, time.Now(), userId, step)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while updating two-factor last used step", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.FromContext(ctx).Error("Error getting rows affected:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	if rowsAffected == 0 {
+		return errs.NewValidationError("code", "The two-factor code has already been used")
+	}
+
+	return nil
+}
+
+func (rdb TwoFactorRepositoryDB) ReplaceRecoveryCodes(ctx context.Context, userId uint64, recoveryCodes []string) *errs.AppError {
+	ctx, done := observe(ctx, "two_factor", "ReplaceRecoveryCodes")
+	defer done()
+
+	tx, err := rdb.client.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while starting transaction:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	defer tx.Rollback()
+
+	if appErr := replaceRecoveryCodes(ctx, tx, userId, recoveryCodes); appErr != nil {
+		return appErr
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.FromContext(ctx).Error("Error while committing transaction:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return nil
+}
+
+// Save starts a new enrollment, replacing any previous secret
+func (rdb TwoFactorRepositoryDB) Save(ctx context.Context, t domain.TwoFactor) *errs.AppError {
+	ctx, done := observe(ctx, "two_factor", "Save")
+	defer done()
+
+	query := `INSERT INTO two_factor_credentials 
+              (user_id, secret, created_at, updated_at) 
+              VALUES (?, ?, ?, ?) 
+              ON DUPLICATE KEY UPDATE 
+              secret = VALUES(secret), confirmed_at = NULL, last_used_step = NULL, updated_at = VALUES(updated_at)`
+
+	_, err := rdb.client.ExecContext(ctx, query, t.UserId, t.Secret, t.CreatedAt, t.UpdatedAt)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while saving two-factor credentials", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return nil
+}
+
+// UseRecoveryCode consumes an unused recovery code, given as its hash
+func (rdb TwoFactorRepositoryDB) UseRecoveryCode(ctx context.Context, userId uint64, code string) *errs.AppError {
+	ctx, done := observe(ctx, "two_factor", "UseRecoveryCode")
+	defer done()
+
+	query := `UPDATE two_factor_recovery_codes 
+              SET used_at = ? 
+              WHERE user_id = ? AND code = ? AND used_at IS NULL`
+
+	result, err := rdb.client.ExecContext(ctx, query, time.Now(), userId, code)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while using recovery code", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.FromContext(ctx).Error("Error getting rows affected:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	if rowsAffected == 0 {
+		return errs.NewValidationError("recovery_code", "The recovery code is invalid")
+	}
+
+	return nil
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sqlx.Tx, userId uint64, recoveryCodes []string) *errs.AppError {
+	_, err := tx.ExecContext(ctx, `DELETE FROM two_factor_recovery_codes WHERE user_id = ?`, userId)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while deleting recovery codes", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	query := `INSERT INTO two_factor_recovery_codes (user_id, code, created_at) VALUES (?, ?, ?)`
+	for _, code := range recoveryCodes {
+		_, err = tx.ExecContext(ctx, query, userId, code, time.Now())
+		if err != nil {
+			logger.FromContext(ctx).Error("Error while storing recovery code", zap.Error(err))
+			return errs.NewUnexpectedError("unexpected database error")
+		}
+	}
+
+	return nil
+}
+
+func NewTwoFactorRepositoryDB(dbClient *sqlx.DB) TwoFactorRepositoryDB {
+	return TwoFactorRepositoryDB{
+		client: dbClient,
+	}
+}
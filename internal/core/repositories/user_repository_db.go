@@ -4,38 +4,64 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/go-ms-project-store/internal/core/domain"
 	"github.com/go-ms-project-store/internal/core/enums"
+	"github.com/go-ms-project-store/internal/core/ports"
 	"github.com/go-ms-project-store/internal/pkg/db"
 	"github.com/go-ms-project-store/internal/pkg/errs"
 	"github.com/go-ms-project-store/internal/pkg/logger"
 	"github.com/go-ms-project-store/internal/pkg/pagination"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type UserRepositoryDB struct {
 	client   *sqlx.DB
 	verifier *db.FieldVerifier
+	roleRepo ports.RoleRepository
 }
 
 type scanner interface {
 	Scan(dest ...interface{}) error
 }
 
-func (rdb UserRepositoryDB) Delete(id string) *errs.AppError {
-	query := `DELETE FROM users WHERE uuid = ?`
+// Anonymize scrubs the personal data of an account while keeping the row,
+// so past orders that reference it remain intact. The account can no longer
+// log in and every token it held is revoked.
+func (rdb UserRepositoryDB) Anonymize(ctx context.Context, id uint64) *errs.AppError {
+	ctx, done := observe(ctx, "user", "Anonymize")
+	defer done()
 
-	result, err := rdb.client.ExecContext(context.Background(), query, id)
+	tx, err := rdb.client.BeginTxx(ctx, nil)
 	if err != nil {
-		logger.Error("Error while deleting user: " + err.Error())
+		logger.FromContext(ctx).Error("Error while starting transaction:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	defer tx.Rollback()
+
+	query := `UPDATE users SET 
+		name = ?, 
+		email = CONCAT('deleted-', uuid, '@deleted.invalid'), 
+		password = '', 
+		email_verified_at = NULL, 
+		locked_at = ?, 
+		updated_at = ? 
+		WHERE id = ?`
+
+	result, err := tx.ExecContext(ctx, query, "Deleted user", time.Now(), time.Now(), id)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while anonymizing user:", zap.Error(err))
 		return errs.NewUnexpectedError("unexpected database error")
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		logger.Error("Error getting rows affected: " + err.Error())
+		logger.FromContext(ctx).Error("Error getting rows affected:", zap.Error(err))
 		return errs.NewUnexpectedError("unexpected database error")
 	}
 
@@ -43,19 +69,176 @@ func (rdb UserRepositoryDB) Delete(id string) *errs.AppError {
 		return errs.NewNotFoundError("User not found")
 	}
 
+	_, err = tx.ExecContext(ctx, `DELETE FROM personal_access_tokens WHERE tokenable_id = ?`, id)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while revoking tokens of anonymized user:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM auth_sessions WHERE user_id = ?`, id)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while ending sessions of anonymized user:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM user_identities WHERE user_id = ?`, id)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while unlinking identities of anonymized user:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.FromContext(ctx).Error("Error while committing transaction:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return nil
+}
+
+// CountUnlockedByRole counts the users of a role who can still sign in
+func (rdb UserRepositoryDB) CountUnlockedByRole(ctx context.Context, roleName string) (int64, *errs.AppError) {
+	ctx, done := observe(ctx, "user", "CountUnlockedByRole")
+	defer done()
+
+	var total int64
+
+	query := `SELECT COUNT(*) FROM users u JOIN roles r ON u.role_id = r.id WHERE r.name = ? AND u.locked_at IS NULL`
+
+	err := rdb.client.GetContext(ctx, &total, query, roleName)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while counting users by role", zap.Error(err))
+		return 0, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return total, nil
+}
+
+func (rdb UserRepositoryDB) Create(ctx context.Context, u domain.UserRegister) (*domain.User, *errs.AppError) {
+	ctx, done := observe(ctx, "user", "Create")
+	defer done()
+
+	if err := rdb.verifier.VerifyUniqueField(ctx, "email", u.Email, 0); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(u.Password), 12)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while hashing password", zap.Error(err))
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	query := `INSERT INTO users (name, email, password, role_id, uuid, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	result, err := rdb.client.ExecContext(ctx, query, u.Name, u.Email, string(hashedPassword), u.RoleId, u.UUID, u.CreatedAt, u.UpdatedAt)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while creating new user", zap.Error(err))
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while getting last insert id", zap.Error(err))
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	newUser := domain.User{
+		Id:        id,
+		Name:      u.Name,
+		Email:     u.Email,
+		Password:  string(hashedPassword),
+		RoleId:    int64(u.RoleId),
+		UUID:      u.UUID,
+		CreatedAt: u.CreatedAt,
+		UpdatedAt: u.UpdatedAt,
+	}
+
+	return &newUser, nil
+}
+
+// Delete removes an account along with its tokens and sessions. Accounts
+// with orders are kept so the orders remain intact.
+func (rdb UserRepositoryDB) Delete(ctx context.Context, id string) *errs.AppError {
+	ctx, done := observe(ctx, "user", "Delete")
+	defer done()
+
+	tx, err := rdb.client.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while starting transaction:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	defer tx.Rollback()
+
+	var userId int64
+	err = tx.GetContext(ctx, &userId, `SELECT id FROM users WHERE uuid = ? FOR UPDATE`, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errs.NewNotFoundError("User not found")
+		}
+		logger.FromContext(ctx).Error("Error while querying users table", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	var orders int64
+	err = tx.GetContext(ctx, &orders, `SELECT COUNT(*) FROM orders WHERE user_id = ?`, userId)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while counting orders of user:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	if orders > 0 {
+		return errs.NewValidationError("user", "A user with orders cannot be deleted")
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM personal_access_tokens WHERE tokenable_id = ?`, userId)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while deleting tokens of user:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM auth_sessions WHERE user_id = ?`, userId)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while deleting sessions of user:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM users WHERE id = ?`, userId)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while deleting user:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.FromContext(ctx).Error("Error while committing transaction:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
 	return nil
 }
 
-func (rdb UserRepositoryDB) FindById(id uint64) (*domain.User, *errs.AppError) {
-	return rdb.findUserBy("u.id", id)
+func (rdb UserRepositoryDB) FindById(ctx context.Context, id uint64) (*domain.User, *errs.AppError) {
+	ctx, done := observe(ctx, "user", "FindById")
+	defer done()
+
+	return rdb.findUserBy(ctx, "u.id", id)
 }
 
-func (rdb UserRepositoryDB) FindByUuid(uuid string) (*domain.User, *errs.AppError) {
-	return rdb.findUserBy("u.uuid", uuid)
+func (rdb UserRepositoryDB) FindByEmail(ctx context.Context, email string) (*domain.User, *errs.AppError) {
+	ctx, done := observe(ctx, "user", "FindByEmail")
+	defer done()
+
+	return rdb.findUserBy(ctx, "u.email", email)
+}
+
+func (rdb UserRepositoryDB) FindByUuid(ctx context.Context, uuid string) (*domain.User, *errs.AppError) {
+	ctx, done := observe(ctx, "user", "FindByUuid")
+	defer done()
+
+	return rdb.findUserBy(ctx, "u.uuid", uuid)
 }
 
 // Private helper method to handle both FindById and FindByUuid
-func (rdb UserRepositoryDB) findUserBy(field string, value interface{}) (*domain.User, *errs.AppError) {
+func (rdb UserRepositoryDB) findUserBy(ctx context.Context, field string, value interface{}) (*domain.User, *errs.AppError) {
 	query := `
     SELECT 
         u.id,
@@ -65,17 +248,21 @@ func (rdb UserRepositoryDB) findUserBy(field string, value interface{}) (*domain
         u.role_id,
         r.name AS role_name,
         u.email_verified_at, 
+        u.locked_at,
         u.created_at,
         u.updated_at
     FROM users u
     JOIN roles r ON u.role_id = r.id
     WHERE ` + field + ` = ?`
 
-	row := rdb.client.QueryRowx(query, value)
-	return rdb.scanUserWithRole(row)
+	row := rdb.client.QueryRowxContext(ctx, query, value)
+	return rdb.scanUserWithRole(ctx, row)
 }
 
-func (rdb UserRepositoryDB) FindAll(filter pagination.DataDBFilter, roleName string) (domain.Users, int64, *errs.AppError) {
+func (rdb UserRepositoryDB) FindAll(ctx context.Context, filter pagination.DataDBFilter, roleName string) (domain.Users, int64, *errs.AppError) {
+	ctx, done := observe(ctx, "user", "FindAll")
+	defer done()
+
 	var total int64
 	users := domain.Users{}
 
@@ -92,6 +279,7 @@ func (rdb UserRepositoryDB) FindAll(filter pagination.DataDBFilter, roleName str
         u.role_id,
 		r.name AS role_name,
         u.email_verified_at, 
+        u.locked_at,
         u.created_at,
         u.updated_at
     FROM users u
@@ -106,9 +294,9 @@ func (rdb UserRepositoryDB) FindAll(filter pagination.DataDBFilter, roleName str
 	}
 
 	// Execute count query
-	err := rdb.client.Get(&total, countQuery, args...)
+	err := rdb.client.GetContext(ctx, &total, countQuery, args...)
 	if err != nil {
-		logger.Error("Error while counting user table " + err.Error())
+		logger.FromContext(ctx).Error("Error while counting user table", zap.Error(err))
 		return nil, 0, errs.NewUnexpectedError("unexpected database error")
 	}
 
@@ -133,15 +321,15 @@ func (rdb UserRepositoryDB) FindAll(filter pagination.DataDBFilter, roleName str
 	// fmt.Println("Debug - roleName:", roleName)
 
 	// Execute the main query
-	rows, err := rdb.client.Queryx(query, args...)
+	rows, err := rdb.client.QueryxContext(ctx, query, args...)
 	if err != nil {
-		logger.Error("Error while querying user table " + err.Error())
+		logger.FromContext(ctx).Error("Error while querying user table", zap.Error(err))
 		return nil, 0, errs.NewUnexpectedError("unexpected database error")
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		user, err := rdb.scanUserWithRole(rows)
+		user, err := rdb.scanUserWithRole(ctx, rows)
 		if err != nil {
 			return nil, 0, err
 		}
@@ -149,19 +337,215 @@ func (rdb UserRepositoryDB) FindAll(filter pagination.DataDBFilter, roleName str
 	}
 
 	if err = rows.Err(); err != nil {
-		logger.Error("Error after iterating over user rows " + err.Error())
+		logger.FromContext(ctx).Error("Error after iterating over user rows", zap.Error(err))
 		return nil, 0, errs.NewUnexpectedError("unexpected database error")
 	}
 
 	return users, total, nil
 }
 
-func (rdb UserRepositoryDB) FindAllCustomers(filter pagination.DataDBFilter) (domain.Users, int64, *errs.AppError) {
-	return rdb.FindAll(filter, string(enums.CustomerRole))
+func (rdb UserRepositoryDB) FindAllCustomers(ctx context.Context, filter pagination.DataDBFilter) (domain.Users, int64, *errs.AppError) {
+	ctx, done := observe(ctx, "user", "FindAllCustomers")
+	defer done()
+
+	return rdb.FindAll(ctx, filter, string(enums.CustomerRole))
+}
+
+func (rdb UserRepositoryDB) FindAllAdmins(ctx context.Context, filter pagination.DataDBFilter) (domain.Users, int64, *errs.AppError) {
+	ctx, done := observe(ctx, "user", "FindAllAdmins")
+	defer done()
+
+	return rdb.FindAll(ctx, filter, string(enums.AdminRole))
+}
+
+// MarkEmailVerified flags the email as verified, provided it is still the
+// address the verification was issued for
+func (rdb UserRepositoryDB) MarkEmailVerified(ctx context.Context, id uint64, email string) *errs.AppError {
+	ctx, done := observe(ctx, "user", "MarkEmailVerified")
+	defer done()
+
+	query := `UPDATE users SET email_verified_at = ?, updated_at = ? WHERE id = ? AND email = ?`
+
+	result, err := rdb.client.ExecContext(ctx, query, time.Now(), time.Now(), id, email)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while verifying user email:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.FromContext(ctx).Error("Error getting rows affected:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	if rowsAffected == 0 {
+		return errs.NewNotFoundError("User not found")
+	}
+
+	return nil
+}
+
+func (rdb UserRepositoryDB) RoleRepo() ports.RoleRepository {
+	return rdb.roleRepo
+}
+
+// SetLocked locks or unlocks an account. Locking also revokes every token
+// the user holds so existing sessions end immediately.
+func (rdb UserRepositoryDB) SetLocked(ctx context.Context, uuid string, locked bool) *errs.AppError {
+	ctx, done := observe(ctx, "user", "SetLocked")
+	defer done()
+
+	var lockedAt interface{}
+	if locked {
+		lockedAt = time.Now()
+	}
+
+	tx, err := rdb.client.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while starting transaction:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	defer tx.Rollback()
+
+	var userId int64
+	err = tx.GetContext(ctx, &userId, `SELECT id FROM users WHERE uuid = ?`, uuid)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errs.NewNotFoundError("User not found")
+		}
+		logger.FromContext(ctx).Error("Error while querying users table", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE users SET locked_at = ?, updated_at = ? WHERE id = ?`, lockedAt, time.Now(), userId)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while updating user lock:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	if locked {
+		_, err = tx.ExecContext(ctx, `DELETE FROM personal_access_tokens WHERE tokenable_id = ?`, userId)
+		if err != nil {
+			logger.FromContext(ctx).Error("Error while revoking tokens of locked user:", zap.Error(err))
+			return errs.NewUnexpectedError("unexpected database error")
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM auth_sessions WHERE user_id = ?`, userId)
+		if err != nil {
+			logger.FromContext(ctx).Error("Error while ending sessions of locked user:", zap.Error(err))
+			return errs.NewUnexpectedError("unexpected database error")
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.FromContext(ctx).Error("Error while committing transaction:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return nil
+}
+
+func (rdb UserRepositoryDB) Update(ctx context.Context, u domain.User) (*domain.User, *errs.AppError) {
+	ctx, done := observe(ctx, "user", "Update")
+	defer done()
+
+	existingUser, errPkg := rdb.FindById(ctx, uint64(u.Id))
+	if errPkg != nil {
+		return nil, errs.NewNotFoundError("User not found")
+	}
+
+	// Verify email uniqueness
+	if err := rdb.verifier.VerifyUniqueField(ctx, "email", u.Email, u.Id); err != nil {
+		return nil, err
+	}
+
+	// A changed email address has to be verified again
+	updateQuery := `UPDATE users SET 
+		email_verified_at = IF(email = ?, email_verified_at, NULL),
+		name = ?, 
+		email = ?, 
+		role_id = ?, 
+		updated_at = ? 
+		WHERE id = ?`
+	result, err := rdb.client.ExecContext(ctx, updateQuery, u.Email, u.Name, u.Email, u.RoleId, u.UpdatedAt, u.Id)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while updating user:", zap.Error(err))
+		return nil, errs.NewUnexpectedError("Unexpected database error")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.FromContext(ctx).Error("Error getting rows affected:", zap.Error(err))
+		return nil, errs.NewUnexpectedError("Unexpected database error")
+	}
+
+	if rowsAffected == 0 {
+		return existingUser, nil
+	}
+
+	updatedUser, errPkg := rdb.FindById(ctx, uint64(u.Id))
+	if errPkg != nil {
+		return nil, errs.NewUnexpectedError("Error fetching updated user")
+	}
+
+	return updatedUser, nil
+}
+
+func (rdb UserRepositoryDB) UpdatePassword(ctx context.Context, id uint64, password string) *errs.AppError {
+	ctx, done := observe(ctx, "user", "UpdatePassword")
+	defer done()
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), 12)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while hashing password", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	query := `UPDATE users SET password = ?, updated_at = ? WHERE id = ?`
+
+	result, err := rdb.client.ExecContext(ctx, query, string(hashedPassword), time.Now(), id)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while updating user password:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.FromContext(ctx).Error("Error getting rows affected:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	if rowsAffected == 0 {
+		return errs.NewNotFoundError("User not found")
+	}
+
+	return nil
 }
 
-func (rdb UserRepositoryDB) FindAllAdmins(filter pagination.DataDBFilter) (domain.Users, int64, *errs.AppError) {
-	return rdb.FindAll(filter, string(enums.AdminRole))
+func (rdb UserRepositoryDB) UpdateRole(ctx context.Context, uuid string, roleId int64) *errs.AppError {
+	ctx, done := observe(ctx, "user", "UpdateRole")
+	defer done()
+
+	query := `UPDATE users SET role_id = ?, updated_at = ? WHERE uuid = ?`
+
+	result, err := rdb.client.ExecContext(ctx, query, roleId, time.Now(), uuid)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while updating user role:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logger.FromContext(ctx).Error("Error getting rows affected:", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	if rowsAffected == 0 {
+		return errs.NewNotFoundError("User not found")
+	}
+
+	return nil
 }
 
 func NewUserRepositoryDB(dbClient *sqlx.DB) UserRepositoryDB {
@@ -171,6 +555,7 @@ func NewUserRepositoryDB(dbClient *sqlx.DB) UserRepositoryDB {
 			DB:        dbClient,
 			TableName: "users",
 		},
+		roleRepo: NewRoleRepositoryDB(dbClient),
 	}
 }
 
@@ -184,7 +569,7 @@ func (rdb UserRepositoryDB) processUser(user *domain.User, uuidBytes []byte) (*d
 	return user, nil
 }
 
-func (rdb UserRepositoryDB) scanUser(s scanner) (*domain.User, *errs.AppError) {
+func (rdb UserRepositoryDB) scanUser(ctx context.Context, s scanner) (*domain.User, *errs.AppError) {
 	var user domain.User
 	var uuidBytes []byte
 
@@ -195,6 +580,7 @@ func (rdb UserRepositoryDB) scanUser(s scanner) (*domain.User, *errs.AppError) {
 		&user.Email,
 		&user.RoleId,
 		&user.EmailVerifiedAt,
+		&user.LockedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -203,14 +589,14 @@ func (rdb UserRepositoryDB) scanUser(s scanner) (*domain.User, *errs.AppError) {
 		if err == sql.ErrNoRows {
 			return nil, errs.NewNotFoundError("user not found")
 		}
-		logger.Error("Error while scanning user row " + err.Error())
+		logger.FromContext(ctx).Error("Error while scanning user row", zap.Error(err))
 		return nil, errs.NewUnexpectedError("unexpected database error")
 	}
 
 	return rdb.processUser(&user, uuidBytes)
 }
 
-func (rdb UserRepositoryDB) scanUserWithRole(s scanner) (*domain.User, *errs.AppError) {
+func (rdb UserRepositoryDB) scanUserWithRole(ctx context.Context, s scanner) (*domain.User, *errs.AppError) {
 	var user domain.User
 	var uuidBytes []byte
 	var roleName string
@@ -223,6 +609,7 @@ func (rdb UserRepositoryDB) scanUserWithRole(s scanner) (*domain.User, *errs.App
 		&user.RoleId,
 		&roleName,
 		&user.EmailVerifiedAt,
+		&user.LockedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -231,7 +618,7 @@ func (rdb UserRepositoryDB) scanUserWithRole(s scanner) (*domain.User, *errs.App
 		if err == sql.ErrNoRows {
 			return nil, errs.NewNotFoundError("user not found")
 		}
-		logger.Error("Error while scanning user row " + err.Error())
+		logger.FromContext(ctx).Error("Error while scanning user row", zap.Error(err))
 		return nil, errs.NewUnexpectedError("unexpected database error")
 	}
 
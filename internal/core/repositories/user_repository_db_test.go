@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestDeleteRemovesTokensAndSessions(t *testing.T) {
+	client, db := newFakeDB(t)
+	db.onQuery("FROM users", int64(7))
+	db.onQuery("FROM orders", int64(0))
+
+	if err := (UserRepositoryDB{client: client}).Delete(context.Background(), "user-uuid"); err != nil {
+		t.Fatal(err.Message)
+	}
+
+	for _, prefix := range []string{"DELETE FROM personal_access_tokens", "DELETE FROM auth_sessions", "DELETE FROM users"} {
+		e, ok := db.exec(prefix)
+		if !ok {
+			t.Errorf("%q wasn't run", prefix)
+			continue
+		}
+		if len(e.args) != 1 || e.args[0] != int64(7) {
+			t.Errorf("%q ran with %v, want the user id", prefix, e.args)
+		}
+	}
+	if !db.committed {
+		t.Error("the deletion wasn't committed")
+	}
+}
+
+func TestDeleteRefusesAUserWithOrders(t *testing.T) {
+	client, db := newFakeDB(t)
+	db.onQuery("FROM users", int64(7))
+	db.onQuery("FROM orders", int64(2))
+
+	err := (UserRepositoryDB{client: client}).Delete(context.Background(), "user-uuid")
+	if err == nil || err.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Delete() = %+v, want a validation error", err)
+	}
+
+	if len(db.execs) != 0 || db.committed {
+		t.Errorf("statements %v ran for a user with orders", db.execs)
+	}
+}
+
+func TestDeleteUnknownUser(t *testing.T) {
+	client, _ := newFakeDB(t)
+
+	err := (UserRepositoryDB{client: client}).Delete(context.Background(), "user-uuid")
+	if err == nil || err.Code != http.StatusNotFound {
+		t.Fatalf("Delete() = %+v, want a 404", err)
+	}
+}
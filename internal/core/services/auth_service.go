@@ -1,111 +1,685 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-ms-project-store/internal/adapters/input/http/dto"
 	"github.com/go-ms-project-store/internal/core/domain"
 	"github.com/go-ms-project-store/internal/core/enums"
 	"github.com/go-ms-project-store/internal/core/ports"
+	"github.com/go-ms-project-store/internal/pkg/config"
 	"github.com/go-ms-project-store/internal/pkg/errs"
 	"github.com/go-ms-project-store/internal/pkg/helpers"
+	"github.com/go-ms-project-store/internal/pkg/logger"
+	"github.com/go-ms-project-store/internal/pkg/metrics"
+	"github.com/go-ms-project-store/internal/pkg/tracing"
+	"go.uber.org/zap"
 )
 
 type DefaultAuthService struct {
-	repo ports.AuthRepository
+	cfg            *config.Config
+	repo           ports.AuthRepository
+	tokens         ports.TokenDriver
+	mailer         ports.Mailer
+	resendThrottle *throttle
+	resetThrottle  *throttle
+	loginGuard     loginGuard
 }
 
-func (s DefaultAuthService) Login(req dto.NewLoginRequest) (*dto.TokenResponse, *errs.AppError) {
+const emailVerificationPurpose = "verify-email"
+
+const maxTwoFactorFailures = 5
+
+// ForgotPassword emails a password reset link when the address belongs to an
+// active account. It never reports whether the account exists.
+func (s DefaultAuthService) ForgotPassword(ctx context.Context, req dto.ForgotPasswordRequest) *errs.AppError {
+	ctx, span := tracing.Start(ctx, "AuthService.ForgotPassword")
+	defer span.End()
+
+	if ok, _ := s.resetThrottle.Allow(strings.ToLower(req.Email)); !ok {
+		return nil
+	}
+
+	user, err := s.repo.UserRepo().FindByEmail(ctx, req.Email)
+	if err != nil {
+		if err.Code != http.StatusNotFound {
+			logger.FromContext(ctx).Error("Error while finding user for password reset", zap.String("error", err.Message))
+		}
+		return nil
+	}
+
+	if user.IsLocked() {
+		return nil
+	}
+
+	token, err := s.repo.CreatePasswordResetToken(ctx, uint64(user.Id), time.Now().Add(s.cfg.Auth.PasswordResetLifetime))
+	if err != nil {
+		return nil
+	}
+
+	link := s.cfg.App.URL + "/reset-password?token=" + url.QueryEscape(token)
+
+	if appErr := s.mailer.Send(ctx, domain.NewPasswordResetMail(*user, link, s.cfg.Auth.PasswordResetLifetime)); appErr != nil {
+		logger.FromContext(ctx).Error("Error while sending password reset email", zap.Int64("recipient_id", user.Id))
+	}
+
+	return nil
+}
+
+// CreatePersonalToken issues a long-lived API token limited to abilities
+// from the registry. Wildcards such as "products:*" are accepted. Abilities
+// needing a permission the user's role lacks are refused.
+func (s DefaultAuthService) CreatePersonalToken(ctx context.Context, user_id uint64, req dto.NewPersonalTokenRequest) (*domain.Token, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "AuthService.CreatePersonalToken")
+	defer span.End()
+
+	if req.Name == string(enums.AccessToken) ||
+		req.Name == string(enums.RefreshToken) ||
+		req.Name == string(enums.TwoFactorChallengeToken) {
+		return nil, errs.NewValidationError("name", "The token name is reserved")
+	}
+
+	for _, ability := range req.Abilities {
+		if !enums.IsPersonalTokenAbility(ability) {
+			return nil, errs.NewValidationError("abilities", fmt.Sprintf("The ability %s does not exist", ability))
+		}
+	}
+
+	role, err := s.repo.RoleRepo().FindByUserId(ctx, user_id)
+	if err != nil {
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	for _, ability := range req.Abilities {
+		for _, permission := range enums.RequiredPermissions(ability) {
+			if !role.HasPermission(string(permission)) {
+				return nil, errs.NewValidationError("abilities", fmt.Sprintf("The ability %s requires the %s permission", ability, permission))
+			}
+		}
+	}
+
+	tokenDto := dto.NewTokenDTO{
+		UserID:    user_id,
+		Name:      req.Name,
+		Abilities: req.Abilities,
+	}
+	if req.ExpiresAt != nil {
+		tokenDto.ExpiresAt = *req.ExpiresAt
+	}
+
+	token, err := s.repo.CreatePersonalAccessToken(ctx, domain.NewToken(tokenDto))
+	if err != nil {
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return token, nil
+}
+
+func (s DefaultAuthService) GetPersonalTokens(ctx context.Context, user_id uint64) (domain.Tokens, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "AuthService.GetPersonalTokens")
+	defer span.End()
+
+	tokens, err := s.repo.FindPersonalAccessTokens(ctx, user_id)
+	if err != nil {
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return tokens, nil
+}
+
+func (s DefaultAuthService) RevokePersonalToken(ctx context.Context, user_id uint64, id uint64) *errs.AppError {
+	ctx, span := tracing.Start(ctx, "AuthService.RevokePersonalToken")
+	defer span.End()
+
+	err := s.repo.DeletePersonalAccessToken(ctx, user_id, id)
+	if err != nil {
+		if err.Code == http.StatusNotFound {
+			return err
+		}
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return nil
+}
+
+// Login checks the credentials and issues the token pair for a new session.
+// Users with two-factor authentication get a challenge token instead, to be
+// exchanged through VerifyTwoFactor.
+func (s DefaultAuthService) Login(ctx context.Context, req dto.NewLoginRequest) (*dto.TokenResponse, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "AuthService.Login")
+	defer span.End()
+
+	if err := s.loginGuard.Check(ctx, req.Email, req.IpAddress); err != nil {
+		metrics.Login(metrics.LoginPassword, metrics.LoginThrottled)
+		return nil, err
+	}
+
 	login := domain.NewLogin(req)
 
-	user, err := s.repo.Login(login)
+	user, err := s.repo.Login(ctx, login)
 	if err != nil {
 		if err.Code == http.StatusUnauthorized {
+			metrics.Login(metrics.LoginPassword, metrics.LoginFailed)
+			s.loginGuard.Failed(ctx, req.Email, req.IpAddress)
+			s.recordLoginAttempt(ctx, req, nil, false)
 			return nil, err
 		}
 		return nil, errs.NewUnexpectedError("unexpected database error")
 	}
 
+	userId := uint64(user.Id)
+	metrics.Login(metrics.LoginPassword, metrics.LoginSucceeded)
+	s.loginGuard.Succeeded(ctx, req.Email)
+	s.recordLoginAttempt(ctx, req, &userId, true)
+
+	return s.startSession(ctx, userId, req)
+}
+
+// VerifyTwoFactor exchanges a challenge token and a valid two-factor code for
+// the token pair of a new session. Invalid codes are counted per user across
+// challenges; each one backs off further codes, and the challenge is dropped
+// once the user is locked out.
+func (s DefaultAuthService) VerifyTwoFactor(ctx context.Context, user_id uint64, token_id uint64, req dto.VerifyTwoFactorRequest) (*dto.TokenResponse, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "AuthService.VerifyTwoFactor")
+	defer span.End()
+
+	if err := s.loginGuard.CheckTwoFactor(ctx, user_id); err != nil {
+		return nil, err
+	}
+
+	err := verifyTwoFactorCode(ctx, s.repo.TwoFactorRepo(), s.cfg.App.KeyBytes(), user_id, req.Code, req.RecoveryCode)
+	if err != nil {
+		if err.Code != http.StatusUnprocessableEntity {
+			return nil, err
+		}
+
+		if s.loginGuard.TwoFactorFailed(ctx, user_id) {
+			if appErr := s.repo.DeleteChallengeToken(ctx, user_id, token_id); appErr != nil {
+				return nil, appErr
+			}
+			return nil, errs.NewUnauthorizedError("Too many invalid codes, please log in again")
+		}
+
+		return nil, err
+	}
+
+	s.loginGuard.TwoFactorSucceeded(ctx, user_id)
+
+	err = s.repo.DeleteChallengeToken(ctx, user_id, token_id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.issueLoginTokens(ctx, user_id, dto.NewLoginRequest{
+		DeviceName: req.DeviceName,
+		UserAgent:  req.UserAgent,
+		IpAddress:  req.IpAddress,
+	})
+}
+
+// Logout ends the session the request was made from. Tokens issued outside
+// of a session have nothing to group them, so all of them are revoked.
+func (s DefaultAuthService) Logout(ctx context.Context, user_id uint64, session_id uint64) *errs.AppError {
+	ctx, span := tracing.Start(ctx, "AuthService.Logout")
+	defer span.End()
+
+	if session_id == 0 {
+		return s.endAllSessions(ctx, user_id)
+	}
+
+	err := s.repo.SessionRepo().Delete(ctx, user_id, session_id)
+	if err != nil {
+		return err
+	}
+
+	s.tokens.RevokeSessions(user_id, session_id)
+
+	return nil
+}
+
+func (s DefaultAuthService) Me(ctx context.Context, user_id uint64) (*domain.User, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "AuthService.Me")
+	defer span.End()
+
+	user, err := s.repo.UserRepo().FindById(ctx, user_id)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s DefaultAuthService) UpdateMe(ctx context.Context, user_id uint64, req dto.UpdateMeRequest) (*domain.User, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "AuthService.UpdateMe")
+	defer span.End()
+
+	user, err := s.Me(ctx, user_id)
+	if err != nil {
+		return nil, err
+	}
+
+	emailChanged := req.Email != "" && req.Email != user.Email
+
+	if req.Name != "" {
+		user.Name = req.Name
+	}
+	if req.Email != "" {
+		user.Email = req.Email
+	}
+	user.UpdatedAt = time.Now()
+
+	updatedUser, err := s.repo.UserRepo().Update(ctx, *user)
+	if err != nil {
+		if err.Code == http.StatusUnprocessableEntity {
+			return nil, err
+		} else if err.Code == http.StatusNotFound {
+			return nil, err
+		}
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	if emailChanged {
+		s.sendVerificationMail(ctx, *updatedUser)
+	}
+
+	return updatedUser, nil
+}
+
+// ChangePassword replaces the user's password after checking the current one
+// and signs out every session except the one used for this request
+func (s DefaultAuthService) ChangePassword(ctx context.Context, user_id uint64, session_id uint64, req dto.ChangePasswordRequest) *errs.AppError {
+	ctx, span := tracing.Start(ctx, "AuthService.ChangePassword")
+	defer span.End()
+
+	err := s.repo.VerifyPassword(ctx, user_id, req.CurrentPassword)
+	if err != nil {
+		if err.Code == http.StatusUnprocessableEntity {
+			return errs.NewValidationError("current_password", err.Message)
+		}
+		return err
+	}
+
+	err = s.repo.UserRepo().UpdatePassword(ctx, user_id, req.Password)
+	if err != nil {
+		return err
+	}
+
+	return s.endOtherSessions(ctx, user_id, session_id)
+}
+
+// DeleteMe closes the user's account. Personal data is anonymized rather than
+// removed so past orders stay consistent.
+func (s DefaultAuthService) DeleteMe(ctx context.Context, user_id uint64, req dto.DeleteMeRequest) *errs.AppError {
+	ctx, span := tracing.Start(ctx, "AuthService.DeleteMe")
+	defer span.End()
+
+	user, err := s.Me(ctx, user_id)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.VerifyPassword(ctx, user_id, req.Password)
+	if err != nil {
+		return err
+	}
+
+	last, err := isLastAdmin(ctx, s.repo.UserRepo(), user)
+	if err != nil {
+		return err
+	}
+	if last {
+		return errs.NewValidationError("user", "The last admin account cannot be deleted")
+	}
+
+	err = s.repo.UserRepo().Anonymize(ctx, user_id)
+	if err != nil {
+		return err
+	}
+
+	s.tokens.RevokeUser(user_id)
+
+	return nil
+}
+
+func (s DefaultAuthService) GetSessions(ctx context.Context, user_id uint64) (domain.Sessions, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "AuthService.GetSessions")
+	defer span.End()
+
+	sessions, err := s.repo.SessionRepo().FindAllByUser(ctx, user_id)
+	if err != nil {
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return sessions, nil
+}
+
+// RefreshToken rotates the refresh token of the current session, issuing a
+// new access and refresh token pair linked to the one presented
+func (s DefaultAuthService) RefreshToken(ctx context.Context, user_id uint64, session_id uint64, token_id uint64) (*dto.TokenResponse, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "AuthService.RefreshToken")
+	defer span.End()
+
 	atAbility := []string{string(enums.AccessTokenAbility)}
 	atDto := dto.NewTokenDTO{
-		UserID:    uint64(user.Id),
+		UserID:    uint64(user_id),
+		SessionID: session_id,
 		Name:      string(enums.AccessToken),
+		ExpiresAt: time.Now().Add(s.cfg.Auth.AccessTokenLifetime),
 		Abilities: atAbility,
-		ExpiresAt: helpers.GetAccessTokenExpiry(),
 	}
 
 	rtAbility := []string{string(enums.RefreshTokenAbility)}
 	rtDto := dto.NewTokenDTO{
-		UserID:    uint64(user.Id),
+		UserID:    uint64(user_id),
+		SessionID: session_id,
+		ParentID:  token_id,
 		Name:      string(enums.RefreshToken),
+		ExpiresAt: time.Now().Add(s.cfg.Auth.RefreshTokenLifetime),
 		Abilities: rtAbility,
-		ExpiresAt: helpers.GetRefreshTokenExpiry(),
 	}
 
-	acToken := domain.NewToken(atDto)
-	ac, err := s.repo.CreateAccessToken(acToken)
+	rt, err := s.repo.RotateRefreshToken(ctx, token_id, domain.NewToken(rtDto))
 	if err != nil {
-		if err.Code == http.StatusUnprocessableEntity {
-			return nil, err
+		if err.Code == http.StatusUnauthorized {
+			// The token was rotated by a concurrent request, which is reuse as well
+			return nil, revokeTokenFamily(ctx, s.repo, s.tokens, domain.Token{ID: token_id, UserID: user_id, SessionID: session_id})
 		}
 		return nil, errs.NewUnexpectedError("unexpected database error")
 	}
 
-	rToken := domain.NewToken(rtDto)
-	rt, err := s.repo.CreateRefreshToken(rToken)
+	// Nor does the access token outlive the session
+	if rt.ExpiresAt.Before(atDto.ExpiresAt) {
+		atDto.ExpiresAt = rt.ExpiresAt
+	}
+
+	ac, err := s.tokens.IssueAccessToken(ctx, domain.NewToken(atDto))
 	if err != nil {
-		if err.Code == http.StatusUnprocessableEntity {
-			return nil, err
-		}
 		return nil, errs.NewUnexpectedError("unexpected database error")
 	}
 
-	res := dto.TokenResponse{
-		AccessToken:  fmt.Sprintf("%d|%s", ac.ID, ac.Token),
-		RefreshToken: fmt.Sprintf("%d|%s", rt.ID, rt.Token),
-		ExpiresIn:    60,
-		TokenType:    "Bearer",
+	return s.newTokenResponse(ac, atDto.ExpiresAt, rt), nil
+}
+
+func (s DefaultAuthService) Register(ctx context.Context, dto dto.NewUserRegisterRequest) (*domain.User, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "AuthService.Register")
+	defer span.End()
+
+	newUser := domain.NewUserRegister(dto)
+
+	user, err := s.repo.Register(ctx, newUser)
+	if err != nil {
+		return nil, err
 	}
 
-	return &res, nil
+	metrics.Registered(metrics.LoginPassword)
+
+	s.sendVerificationMail(ctx, *user)
+
+	return user, nil
 }
 
-func (s DefaultAuthService) Logout(user_id uint64) *errs.AppError {
-	err := s.repo.Logout(user_id)
+// ResetPassword sets a new password using a reset token and signs out every
+// session the user holds
+func (s DefaultAuthService) ResetPassword(ctx context.Context, req dto.ResetPasswordRequest) *errs.AppError {
+	ctx, span := tracing.Start(ctx, "AuthService.ResetPassword")
+	defer span.End()
+
+	user_id, err := s.repo.ConsumePasswordResetToken(ctx, req.Token)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.UserRepo().UpdatePassword(ctx, user_id, req.Password)
 	if err != nil {
+		if err.Code == http.StatusNotFound {
+			return errs.NewValidationError("token", "The password reset token is invalid or has expired")
+		}
 		return err
 	}
 
+	return s.endAllSessions(ctx, user_id)
+}
+
+// SetPassword replaces the user's password without any proof from the user,
+// for operators, and signs out every session the user holds
+func (s DefaultAuthService) SetPassword(ctx context.Context, user_id uint64, req dto.SetPasswordRequest) *errs.AppError {
+	ctx, span := tracing.Start(ctx, "AuthService.SetPassword")
+	defer span.End()
+
+	err := s.repo.UserRepo().UpdatePassword(ctx, user_id, req.Password)
+	if err != nil {
+		return err
+	}
+
+	return s.endAllSessions(ctx, user_id)
+}
+
+// RevokeAllTokens signs the user out everywhere, personal tokens included
+func (s DefaultAuthService) RevokeAllTokens(ctx context.Context, user_id uint64) *errs.AppError {
+	ctx, span := tracing.Start(ctx, "AuthService.RevokeAllTokens")
+	defer span.End()
+
+	return s.endAllSessions(ctx, user_id)
+}
+
+// PruneExpiredTokens deletes every expired token, batchSize at a time
+func (s DefaultAuthService) PruneExpiredTokens(ctx context.Context, batchSize int) (int64, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "AuthService.PruneExpiredTokens")
+	defer span.End()
+
+	return pruneExpiredTokens(ctx, s.repo, batchSize)
+}
+
+func (s DefaultAuthService) RevokeOtherSessions(ctx context.Context, user_id uint64, session_id uint64) *errs.AppError {
+	ctx, span := tracing.Start(ctx, "AuthService.RevokeOtherSessions")
+	defer span.End()
+
+	return s.endOtherSessions(ctx, user_id, session_id)
+}
+
+func (s DefaultAuthService) RevokeSession(ctx context.Context, user_id uint64, session_id uint64) *errs.AppError {
+	ctx, span := tracing.Start(ctx, "AuthService.RevokeSession")
+	defer span.End()
+
+	err := s.repo.SessionRepo().Delete(ctx, user_id, session_id)
+	if err != nil {
+		if err.Code == http.StatusNotFound {
+			return err
+		}
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	s.tokens.RevokeSessions(user_id, session_id)
+
 	return nil
 }
 
-func (s DefaultAuthService) Me(user_id uint64) (*domain.User, *errs.AppError) {
-	user, err := s.repo.UserRepo().FindById(user_id)
+func (s DefaultAuthService) ResendVerificationEmail(ctx context.Context, user_id uint64) *errs.AppError {
+	ctx, span := tracing.Start(ctx, "AuthService.ResendVerificationEmail")
+	defer span.End()
+
+	user, err := s.Me(ctx, user_id)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return user, nil
+	if user.HasVerifiedEmail() {
+		return errs.NewValidationError("email", "The email address is already verified")
+	}
+
+	if ok, wait := s.resendThrottle.Allow(fmt.Sprint(user_id)); !ok {
+		return errs.NewTooManyRequestsError(fmt.Sprintf("Please wait %d seconds before requesting another email", int(wait.Seconds())+1))
+	}
+
+	if err := s.sendVerificationMail(ctx, *user); err != nil {
+		return err
+	}
+
+	return nil
 }
 
-func (s DefaultAuthService) RefreshToken(user_id uint64) (*dto.TokenResponse, *errs.AppError) {
+func (s DefaultAuthService) VerifyEmail(ctx context.Context, token string) *errs.AppError {
+	ctx, span := tracing.Start(ctx, "AuthService.VerifyEmail")
+	defer span.End()
 
-	err := s.repo.RevokeAccessToken(user_id)
+	payload, err := helpers.VerifySignedPayload(token, s.cfg.App.KeyBytes())
 	if err != nil {
-		return nil, err
+		logger.FromContext(ctx).Error("Error while verifying email token", zap.Error(err))
+		return errs.NewValidationError("token", "The verification link is invalid or has expired")
+	}
+
+	parts := strings.SplitN(payload, "|", 3)
+	if len(parts) != 3 || parts[0] != emailVerificationPurpose {
+		return errs.NewValidationError("token", "The verification link is invalid or has expired")
+	}
+
+	user_id, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return errs.NewValidationError("token", "The verification link is invalid or has expired")
+	}
+
+	appErr := s.repo.UserRepo().MarkEmailVerified(ctx, user_id, parts[2])
+	if appErr != nil {
+		if appErr.Code == http.StatusNotFound {
+			return errs.NewValidationError("token", "The verification link is invalid or has expired")
+		}
+		return appErr
+	}
+
+	return nil
+}
+
+// sendVerificationMail emails a signed link bound to the user's current
+// address. Failures are logged but don't abort the calling operation.
+func (s DefaultAuthService) sendVerificationMail(ctx context.Context, user domain.User) *errs.AppError {
+	payload := fmt.Sprintf("%s|%d|%s", emailVerificationPurpose, user.Id, user.Email)
+
+	token, err := helpers.SignPayload(payload, time.Now().Add(s.cfg.Auth.EmailVerificationLifetime), s.cfg.App.KeyBytes())
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while signing email verification token", zap.Error(err))
+		return errs.NewUnexpectedError("unexpected error sending verification email")
+	}
+
+	link := s.cfg.App.URL + "/api/v1/auth/verify-email?token=" + url.QueryEscape(token)
+
+	if appErr := s.mailer.Send(ctx, domain.NewVerificationMail(user, link)); appErr != nil {
+		logger.FromContext(ctx).Error("Error while sending verification email", zap.Int64("recipient_id", user.Id))
+		return appErr
+	}
+
+	return nil
+}
+
+// endAllSessions signs the user out everywhere
+func (s DefaultAuthService) endAllSessions(ctx context.Context, user_id uint64) *errs.AppError {
+	err := s.repo.SessionRepo().DeleteAll(ctx, user_id)
+	if err != nil {
+		return err
+	}
+
+	s.tokens.RevokeUser(user_id)
+
+	return nil
+}
+
+// endOtherSessions signs the user out of every session but the current one
+func (s DefaultAuthService) endOtherSessions(ctx context.Context, user_id uint64, session_id uint64) *errs.AppError {
+	sessions, err := s.repo.SessionRepo().FindAllByUser(ctx, user_id)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.SessionRepo().DeleteOthers(ctx, user_id, session_id)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if session.Id != session_id {
+			s.tokens.RevokeSessions(user_id, session.Id)
+		}
+	}
+
+	return nil
+}
+
+// recordLoginAttempt keeps the outcome of a login for review. Failing to
+// record it doesn't affect the login.
+func (s DefaultAuthService) recordLoginAttempt(ctx context.Context, req dto.NewLoginRequest, user_id *uint64, successful bool) {
+	if err := s.repo.LoginAttemptRepo().Create(ctx, domain.NewLoginAttempt(req, user_id, successful)); err != nil {
+		logger.FromContext(ctx).Error("Error while recording login attempt", zap.String("email", req.Email))
+	}
+}
+
+// startSession completes an authenticated login, handing out a two-factor
+// challenge instead of tokens when the user has it enabled
+func (s DefaultAuthService) startSession(ctx context.Context, user_id uint64, req dto.NewLoginRequest) (*dto.TokenResponse, *errs.AppError) {
+	twoFactor, err := s.repo.TwoFactorRepo().FindByUserId(ctx, user_id)
+	if err != nil && err.Code != http.StatusNotFound {
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	if twoFactor != nil && twoFactor.IsConfirmed() {
+		return s.issueChallengeToken(ctx, user_id)
+	}
+
+	return s.issueLoginTokens(ctx, user_id, req)
+}
+
+// issueChallengeToken hands out a short-lived token that can only be used to
+// complete two-factor authentication
+func (s DefaultAuthService) issueChallengeToken(ctx context.Context, user_id uint64) (*dto.TokenResponse, *errs.AppError) {
+	challenge, err := s.repo.CreateChallengeToken(ctx, domain.NewToken(dto.NewTokenDTO{
+		UserID:    user_id,
+		Name:      string(enums.TwoFactorChallengeToken),
+		Abilities: []string{string(enums.TwoFactorPendingAbility)},
+		ExpiresAt: time.Now().Add(s.cfg.Auth.TwoFactorChallengeLifetime),
+	}))
+	if err != nil {
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return &dto.TokenResponse{
+		AccessToken:       fmt.Sprintf("%d|%s", challenge.ID, challenge.Token),
+		ExpiresIn:         int(time.Until(challenge.ExpiresAt).Seconds()),
+		TokenType:         "Bearer",
+		TwoFactorRequired: true,
+	}, nil
+}
+
+// issueLoginTokens starts a session for the user and issues its access and
+// refresh tokens
+func (s DefaultAuthService) issueLoginTokens(ctx context.Context, user_id uint64, req dto.NewLoginRequest) (*dto.TokenResponse, *errs.AppError) {
+	session, err := s.repo.SessionRepo().Create(ctx, domain.NewSession(user_id, req))
+	if err != nil {
+		return nil, errs.NewUnexpectedError("unexpected database error")
 	}
 
 	atAbility := []string{string(enums.AccessTokenAbility)}
 	atDto := dto.NewTokenDTO{
-		UserID:    uint64(user_id),
+		UserID:    user_id,
+		SessionID: session.Id,
 		Name:      string(enums.AccessToken),
-		ExpiresAt: helpers.GetAccessTokenExpiry(),
 		Abilities: atAbility,
+		ExpiresAt: time.Now().Add(s.cfg.Auth.AccessTokenLifetime),
 	}
 
-	acToken := domain.NewToken(atDto)
-	ac, err := s.repo.CreateAccessToken(acToken)
+	rtAbility := []string{string(enums.RefreshTokenAbility)}
+	rtDto := dto.NewTokenDTO{
+		UserID:    user_id,
+		SessionID: session.Id,
+		Name:      string(enums.RefreshToken),
+		Abilities: rtAbility,
+		ExpiresAt: time.Now().Add(s.cfg.Auth.RefreshTokenLifetime),
+	}
+
+	ac, err := s.tokens.IssueAccessToken(ctx, domain.NewToken(atDto))
 	if err != nil {
 		if err.Code == http.StatusUnprocessableEntity {
 			return nil, err
@@ -113,26 +687,36 @@ func (s DefaultAuthService) RefreshToken(user_id uint64) (*dto.TokenResponse, *e
 		return nil, errs.NewUnexpectedError("unexpected database error")
 	}
 
-	res := dto.TokenResponse{
-		AccessToken: fmt.Sprintf("%d|%s", ac.ID, ac.Token),
-		ExpiresIn:   60,
-		TokenType:   "Bearer",
+	rToken := domain.NewToken(rtDto)
+	rt, err := s.repo.CreateRefreshToken(ctx, rToken)
+	if err != nil {
+		if err.Code == http.StatusUnprocessableEntity {
+			return nil, err
+		}
+		return nil, errs.NewUnexpectedError("unexpected database error")
 	}
 
-	return &res, nil
+	return s.newTokenResponse(ac, atDto.ExpiresAt, rt), nil
 }
 
-func (s DefaultAuthService) Register(dto dto.NewUserRegisterRequest) (*domain.User, *errs.AppError) {
-	newUser := domain.NewUserRegister(dto)
-
-	user, err := s.repo.Register(newUser)
-	if err != nil {
-		return nil, err
+func (s DefaultAuthService) newTokenResponse(accessToken string, accessExpiresAt time.Time, rt *domain.Token) *dto.TokenResponse {
+	return &dto.TokenResponse{
+		AccessToken:      accessToken,
+		RefreshToken:     fmt.Sprintf("%d|%s", rt.ID, rt.Token),
+		ExpiresIn:        int(time.Until(accessExpiresAt).Round(time.Second).Seconds()),
+		RefreshExpiresIn: int(time.Until(rt.ExpiresAt).Round(time.Second).Seconds()),
+		TokenType:        "Bearer",
 	}
-
-	return user, nil
 }
 
-func NewAuthService(repository ports.AuthRepository) DefaultAuthService {
-	return DefaultAuthService{repo: repository}
+func NewAuthService(repository ports.AuthRepository, tokens ports.TokenDriver, mailer ports.Mailer, attempts ports.AttemptStore, cfg *config.Config) DefaultAuthService {
+	return DefaultAuthService{
+		cfg:            cfg,
+		repo:           repository,
+		tokens:         tokens,
+		mailer:         mailer,
+		resendThrottle: newThrottle(time.Minute),
+		resetThrottle:  newThrottle(time.Minute),
+		loginGuard:     newLoginGuard(attempts, cfg.Auth),
+	}
 }
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-ms-project-store/internal/adapters/input/http/dto"
+	"github.com/go-ms-project-store/internal/core/domain"
+	"github.com/go-ms-project-store/internal/core/enums"
+	"github.com/go-ms-project-store/internal/core/ports"
+	"github.com/go-ms-project-store/internal/pkg/config"
+	"github.com/go-ms-project-store/internal/pkg/errs"
+)
+
+type fakeTokenRepo struct {
+	ports.AuthRepository
+	role    domain.Role
+	created []domain.Token
+}
+
+func (r *fakeTokenRepo) RoleRepo() ports.RoleRepository { return fakeRoleRepo{role: r.role} }
+
+func (r *fakeTokenRepo) CreatePersonalAccessToken(_ context.Context, token domain.Token) (*domain.Token, *errs.AppError) {
+	r.created = append(r.created, token)
+	return &token, nil
+}
+
+func (r *fakeTokenRepo) RotateRefreshToken(_ context.Context, parentId uint64, token domain.Token) (*domain.Token, *errs.AppError) {
+	// The session started 160 hours ago and has 8 hours left
+	token.ID = parentId + 1
+	token.ExpiresAt = time.Now().Add(8 * time.Hour)
+	return &token, nil
+}
+
+type fakeTokenIssuer struct {
+	ports.TokenDriver
+	issued []domain.Token
+}
+
+func (d *fakeTokenIssuer) IssueAccessToken(_ context.Context, token domain.Token) (string, *errs.AppError) {
+	d.issued = append(d.issued, token)
+	return "access", nil
+}
+
+type fakeRoleRepo struct {
+	ports.RoleRepository
+	role domain.Role
+}
+
+func (r fakeRoleRepo) FindByUserId(context.Context, uint64) (*domain.Role, *errs.AppError) {
+	role := r.role
+	return &role, nil
+}
+
+func TestCreatePersonalTokenLimitsAbilitiesToTheRole(t *testing.T) {
+	customer := domain.Role{Name: string(enums.CustomerRole)}
+	editor := domain.Role{Name: "editor", Permissions: []domain.Permission{{Name: string(enums.ManageCatalogPermission)}}}
+	admin := domain.Role{Name: string(enums.AdminRole)}
+
+	tests := []struct {
+		name      string
+		role      domain.Role
+		abilities []string
+		allowed   bool
+	}{
+		{"customer placing orders", customer, []string{"orders:create"}, true},
+		{"customer writing products", customer, []string{"products:write"}, false},
+		{"customer with a catalogue wildcard", customer, []string{"categories:*"}, false},
+		{"customer with every ability", customer, []string{"*"}, false},
+		{"role with the catalogue permission", editor, []string{"products:*", "categories:read"}, true},
+		{"admin with every ability", admin, []string{"*"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeTokenRepo{role: tt.role}
+			service := DefaultAuthService{repo: repo}
+
+			_, err := service.CreatePersonalToken(context.Background(), 1, dto.NewPersonalTokenRequest{Name: "ci", Abilities: tt.abilities})
+			if tt.allowed {
+				if err != nil {
+					t.Fatalf("CreatePersonalToken() refused: %s", err.Message)
+				}
+				if len(repo.created) != 1 {
+					t.Fatal("no token was created")
+				}
+				return
+			}
+
+			if err == nil || err.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("CreatePersonalToken() = %+v, want a validation error", err)
+			}
+			if len(repo.created) != 0 {
+				t.Error("a token was created")
+			}
+		})
+	}
+}
+
+func TestRefreshTokenKeepsTheSessionExpiry(t *testing.T) {
+	tokens := &fakeTokenIssuer{}
+	service := DefaultAuthService{
+		cfg:    &config.Config{Auth: config.Auth{AccessTokenLifetime: time.Hour, RefreshTokenLifetime: 168 * time.Hour}},
+		repo:   &fakeTokenRepo{},
+		tokens: tokens,
+	}
+
+	res, err := service.RefreshToken(context.Background(), 1, 2, 3)
+	if err != nil {
+		t.Fatal(err.Message)
+	}
+
+	if res.RefreshExpiresIn != int((8 * time.Hour).Seconds()) {
+		t.Errorf("RefreshExpiresIn = %d, want what is left of the session", res.RefreshExpiresIn)
+	}
+	if res.ExpiresIn != int(time.Hour.Seconds()) {
+		t.Errorf("ExpiresIn = %d, want the access token lifetime", res.ExpiresIn)
+	}
+}
+
+func TestRefreshTokenDoesNotOutliveTheSession(t *testing.T) {
+	tokens := &fakeTokenIssuer{}
+	service := DefaultAuthService{
+		cfg:    &config.Config{Auth: config.Auth{AccessTokenLifetime: 24 * time.Hour, RefreshTokenLifetime: 168 * time.Hour}},
+		repo:   &fakeTokenRepo{},
+		tokens: tokens,
+	}
+
+	res, err := service.RefreshToken(context.Background(), 1, 2, 3)
+	if err != nil {
+		t.Fatal(err.Message)
+	}
+
+	if len(tokens.issued) != 1 || tokens.issued[0].ExpiresAt.After(time.Now().Add(8*time.Hour)) {
+		t.Fatalf("issued access tokens = %+v, want one ending with the session", tokens.issued)
+	}
+	if res.ExpiresIn != int((8 * time.Hour).Seconds()) {
+		t.Errorf("ExpiresIn = %d, want what is left of the session", res.ExpiresIn)
+	}
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"net/http"
 	"time"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/go-ms-project-store/internal/pkg/errs"
 	"github.com/go-ms-project-store/internal/pkg/logger"
 	"github.com/go-ms-project-store/internal/pkg/pagination"
+	"github.com/go-ms-project-store/internal/pkg/tracing"
 )
 
 type DefaultCategoryService struct {
@@ -17,25 +19,31 @@ type DefaultCategoryService struct {
 }
 
 func (s DefaultCategoryService) GetAllCategories(r *http.Request) (domain.Categories, int64, pagination.DataDBFilter, *errs.AppError) {
+	ctx, span := tracing.Start(r.Context(), "CategoryService.GetAllCategories")
+	defer span.End()
+
 	allowedOrderBy := map[string]bool{
 		"id": true, "name": true, "slug": true, "created_at": true, "updated_at": true,
 	}
 
 	filter := pagination.GetBaseFilterParams(r, allowedOrderBy)
-	categories, totalRows, err := s.repo.FindAll(filter)
+	categories, totalRows, err := s.repo.FindAll(ctx, filter)
 
 	if err != nil {
-		logger.Error("Error while finding all categories")
+		logger.FromContext(ctx).Error("Error while finding all categories")
 		return nil, 0, pagination.DataDBFilter{}, errs.NewUnexpectedError("unexpected database error")
 	}
 
 	return categories, totalRows, filter, nil
 }
 
-func (s DefaultCategoryService) CreateCategory(req dto.NewCategoryRequest) (*domain.Category, *errs.AppError) {
+func (s DefaultCategoryService) CreateCategory(ctx context.Context, req dto.NewCategoryRequest) (*domain.Category, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "CategoryService.CreateCategory")
+	defer span.End()
+
 	category := domain.NewCategory(req)
 
-	newCategory, err := s.repo.Create(category)
+	newCategory, err := s.repo.Create(ctx, category)
 	if err != nil {
 		if err.Code != http.StatusUnprocessableEntity {
 			return nil, errs.NewUnexpectedError("unexpected database error")
@@ -47,7 +55,10 @@ func (s DefaultCategoryService) CreateCategory(req dto.NewCategoryRequest) (*dom
 	return newCategory, nil
 }
 
-func (s DefaultCategoryService) UpdateCategory(id int64, req dto.UpdateCategoryRequest) (*domain.Category, *errs.AppError) {
+func (s DefaultCategoryService) UpdateCategory(ctx context.Context, id int64, req dto.UpdateCategoryRequest) (*domain.Category, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "CategoryService.UpdateCategory")
+	defer span.End()
+
 	category := domain.Category{
 		Id:        id,
 		Name:      req.Name,
@@ -55,7 +66,7 @@ func (s DefaultCategoryService) UpdateCategory(id int64, req dto.UpdateCategoryR
 		UpdatedAt: time.Now(),
 	}
 
-	newCategory, err := s.repo.Update(category)
+	newCategory, err := s.repo.Update(ctx, category)
 	if err != nil {
 		if err.Code == http.StatusUnprocessableEntity {
 			return nil, err
@@ -68,8 +79,11 @@ func (s DefaultCategoryService) UpdateCategory(id int64, req dto.UpdateCategoryR
 	return newCategory, nil
 }
 
-func (s DefaultCategoryService) FindCategoryById(id int) (*domain.Category, *errs.AppError) {
-	category, err := s.repo.FindById(id)
+func (s DefaultCategoryService) FindCategoryById(ctx context.Context, id int) (*domain.Category, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "CategoryService.FindCategoryById")
+	defer span.End()
+
+	category, err := s.repo.FindById(ctx, id)
 	if err != nil {
 		if err.Code != http.StatusNotFound {
 			return nil, errs.NewUnexpectedError("unexpected database error")
@@ -81,8 +95,11 @@ func (s DefaultCategoryService) FindCategoryById(id int) (*domain.Category, *err
 	return category, nil
 }
 
-func (s DefaultCategoryService) DeleteCategory(id int) (bool, *errs.AppError) {
-	err := s.repo.Delete(id)
+func (s DefaultCategoryService) DeleteCategory(ctx context.Context, id int) (bool, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "CategoryService.DeleteCategory")
+	defer span.End()
+
+	err := s.repo.Delete(ctx, id)
 	if err != nil {
 		if err.Code != http.StatusNotFound {
 			return false, errs.NewUnexpectedError("unexpected database error")
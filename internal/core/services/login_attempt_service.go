@@ -0,0 +1,44 @@
+package services
+
+import (
+	"net/http"
+
+	"github.com/go-ms-project-store/internal/core/domain"
+	"github.com/go-ms-project-store/internal/core/ports"
+	"github.com/go-ms-project-store/internal/pkg/errs"
+	"github.com/go-ms-project-store/internal/pkg/logger"
+	"github.com/go-ms-project-store/internal/pkg/pagination"
+	"github.com/go-ms-project-store/internal/pkg/tracing"
+)
+
+type DefaultLoginAttemptService struct {
+	repo ports.LoginAttemptRepository
+}
+
+// GetAllLoginAttempts lists recorded logins, newest first unless another
+// order is requested. The email query parameter narrows them to one account.
+func (s DefaultLoginAttemptService) GetAllLoginAttempts(r *http.Request) (domain.LoginAttempts, int64, pagination.DataDBFilter, *errs.AppError) {
+	ctx, span := tracing.Start(r.Context(), "LoginAttemptService.GetAllLoginAttempts")
+	defer span.End()
+
+	allowedOrderBy := map[string]bool{
+		"id": true, "email": true, "ip_address": true, "created_at": true,
+	}
+
+	filter := pagination.GetBaseFilterParams(r, allowedOrderBy)
+	if r.URL.Query().Get("order_by") == "" {
+		filter.OrderDir = "desc"
+	}
+
+	attempts, totalRows, err := s.repo.FindAll(ctx, filter, r.URL.Query().Get("email"))
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while finding all login attempts")
+		return nil, 0, pagination.DataDBFilter{}, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return attempts, totalRows, filter, nil
+}
+
+func NewLoginAttemptService(repository ports.LoginAttemptRepository) DefaultLoginAttemptService {
+	return DefaultLoginAttemptService{repo: repository}
+}
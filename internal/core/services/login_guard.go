@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-ms-project-store/internal/core/ports"
+	"github.com/go-ms-project-store/internal/pkg/config"
+	"github.com/go-ms-project-store/internal/pkg/errs"
+	"github.com/go-ms-project-store/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// loginGuard slows down password guessing per email and per IP, and
+// two-factor code guessing per user. Each failure for an email or user
+// doubles the wait before its next attempt. Reaching the limit locks the
+// email, IP or user out for the lockout duration.
+type loginGuard struct {
+	store      ports.AttemptStore
+	baseDelay  time.Duration
+	lockout    time.Duration
+	maxByEmail int
+	maxByIP    int
+	// maxTwoFactor is the number of invalid codes a user gets across
+	// challenges before being locked out
+	maxTwoFactor int
+}
+
+// Check returns a 429 error carrying the remaining wait when either the
+// email or the IP is blocked
+func (g loginGuard) Check(ctx context.Context, email string, ip string) *errs.AppError {
+	var wait time.Duration
+
+	for _, key := range g.keys(email, ip) {
+		blockedFor, err := g.store.BlockedFor(key)
+		if err != nil {
+			// Fail open so a store outage doesn't prevent every login
+			logger.FromContext(ctx).Error("Error while checking login attempts", zap.String("key", key), zap.String("error", err.Message))
+			continue
+		}
+
+		if blockedFor > wait {
+			wait = blockedFor
+		}
+	}
+
+	if wait > 0 {
+		return errs.NewRetryAfterError("Too many login attempts, please try again later", wait)
+	}
+
+	return nil
+}
+
+func (g loginGuard) Failed(ctx context.Context, email string, ip string) {
+	keys := g.keys(email, ip)
+
+	// Addresses may be shared, so only emails back off before the limit
+	g.fail(ctx, keys[0], g.maxByEmail, true)
+	g.fail(ctx, keys[1], g.maxByIP, false)
+}
+
+// CheckTwoFactor returns a 429 error while the user's two-factor codes are
+// blocked. Failures are counted per user rather than per challenge, so
+// logging in again with the password doesn't grant fresh guesses.
+func (g loginGuard) CheckTwoFactor(ctx context.Context, userId uint64) *errs.AppError {
+	key := g.twoFactorKey(userId)
+
+	blockedFor, err := g.store.BlockedFor(key)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while checking two-factor attempts", zap.String("key", key), zap.String("error", err.Message))
+		return nil
+	}
+
+	if blockedFor > 0 {
+		return errs.NewRetryAfterError("Too many invalid codes, please try again later", blockedFor)
+	}
+
+	return nil
+}
+
+// TwoFactorFailed records an invalid code and reports whether the user is
+// now locked out
+func (g loginGuard) TwoFactorFailed(ctx context.Context, userId uint64) bool {
+	return g.fail(ctx, g.twoFactorKey(userId), g.maxTwoFactor, true)
+}
+
+func (g loginGuard) TwoFactorSucceeded(ctx context.Context, userId uint64) {
+	if err := g.store.Reset(g.twoFactorKey(userId)); err != nil {
+		logger.FromContext(ctx).Error("Error while resetting two-factor attempts", zap.String("error", err.Message))
+	}
+}
+
+// fail records a failure for the key and blocks it for the lockout once it
+// reaches the limit, or for the backoff before that when backOff is set. It
+// reports whether the limit was reached.
+func (g loginGuard) fail(ctx context.Context, key string, limit int, backOff bool) bool {
+	failures, err := g.store.Fail(key, g.lockout)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while recording login attempt", zap.String("key", key), zap.String("error", err.Message))
+		return false
+	}
+
+	var delay time.Duration
+	if failures >= limit {
+		logger.FromContext(ctx).Warn("Security event: login locked out", zap.String("key", key), zap.Int("failed_attempts", failures))
+		delay = g.lockout
+	} else if backOff {
+		delay = g.backoff(failures)
+	}
+
+	if delay > 0 {
+		if err := g.store.Block(key, delay); err != nil {
+			logger.FromContext(ctx).Error("Error while blocking login attempts", zap.String("key", key), zap.String("error", err.Message))
+		}
+	}
+
+	return failures >= limit
+}
+
+// Succeeded clears the failures for the email. The IP keeps its count so a
+// single valid account can't be used to reset guessing against others.
+func (g loginGuard) Succeeded(ctx context.Context, email string) {
+	if err := g.store.Reset(g.emailKey(email)); err != nil {
+		logger.FromContext(ctx).Error("Error while resetting login attempts", zap.String("error", err.Message))
+	}
+}
+
+func (g loginGuard) backoff(failures int) time.Duration {
+	delay := g.baseDelay * time.Duration(math.Pow(2, float64(failures-1)))
+	if delay > g.lockout {
+		return g.lockout
+	}
+
+	return delay
+}
+
+func (g loginGuard) twoFactorKey(userId uint64) string {
+	return "login:2fa:" + strconv.FormatUint(userId, 10)
+}
+
+func (g loginGuard) emailKey(email string) string {
+	return "login:email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// keys returns the email and IP keys. The IP must be the address resolved by
+// the trusted proxy list, never a raw client header, or anyone could dodge
+// the limit or lock out someone else's address. IPv6 clients usually hold a
+// whole /64, so it is counted as one address.
+func (g loginGuard) keys(email string, ip string) []string {
+	if parsed := net.ParseIP(ip); parsed != nil && parsed.To4() == nil {
+		ip = parsed.Mask(net.CIDRMask(64, 128)).String() + "/64"
+	}
+
+	return []string{g.emailKey(email), "login:ip:" + ip}
+}
+
+func newLoginGuard(store ports.AttemptStore, cfg config.Auth) loginGuard {
+	return loginGuard{
+		store:        store,
+		baseDelay:    time.Second,
+		lockout:      cfg.LoginLockoutDuration,
+		maxByEmail:   cfg.LoginMaxAttempts,
+		maxByIP:      cfg.LoginMaxAttemptsPerIP,
+		maxTwoFactor: maxTwoFactorFailures,
+	}
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-ms-project-store/internal/pkg/config"
+)
+
+func newTestLoginGuard() (loginGuard, *MemoryAttemptStore) {
+	store := NewMemoryAttemptStore()
+
+	return newLoginGuard(store, config.Auth{
+		LoginMaxAttempts:      3,
+		LoginMaxAttemptsPerIP: 5,
+		LoginLockoutDuration:  time.Hour,
+	}), store
+}
+
+func TestLoginGuardBacksOffPerEmail(t *testing.T) {
+	guard, store := newTestLoginGuard()
+	ctx := context.Background()
+
+	if err := guard.Check(ctx, "ada@example.com", "198.51.100.4"); err != nil {
+		t.Fatalf("first attempt was blocked: %v", err.Message)
+	}
+
+	guard.Failed(ctx, "ada@example.com", "198.51.100.4")
+	assertBlockedFor(t, store, "login:email:ada@example.com", time.Second)
+
+	guard.Failed(ctx, "Ada@Example.com ", "198.51.100.4")
+	assertBlockedFor(t, store, "login:email:ada@example.com", 2*time.Second)
+
+	err := guard.Check(ctx, "ada@example.com", "203.0.113.9")
+	if err == nil || err.Code != http.StatusTooManyRequests || err.RetryAfter != 2 {
+		t.Fatalf("Check() = %+v, want a 429 with the remaining wait", err)
+	}
+
+	// Shared addresses don't back off before reaching their limit
+	if wait, _ := store.BlockedFor("login:ip:198.51.100.4"); wait != 0 {
+		t.Errorf("the IP is blocked for %s after two failures", wait)
+	}
+}
+
+func TestLoginGuardLocksOutTheEmail(t *testing.T) {
+	guard, store := newTestLoginGuard()
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		guard.Failed(ctx, "ada@example.com", "198.51.100.4")
+	}
+
+	assertBlockedFor(t, store, "login:email:ada@example.com", time.Hour)
+	if err := guard.Check(ctx, "ada@example.com", "203.0.113.9"); err == nil {
+		t.Fatal("a locked out email was let through from another address")
+	}
+}
+
+func TestLoginGuardLocksOutTheIPAcrossEmails(t *testing.T) {
+	guard, store := newTestLoginGuard()
+	ctx := context.Background()
+
+	emails := []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com", "e@example.com"}
+	for _, email := range emails {
+		guard.Failed(ctx, email, "198.51.100.4")
+	}
+
+	assertBlockedFor(t, store, "login:ip:198.51.100.4", time.Hour)
+	if err := guard.Check(ctx, "fresh@example.com", "198.51.100.4"); err == nil {
+		t.Fatal("a locked out IP was let through with a new email")
+	}
+	if err := guard.Check(ctx, "fresh@example.com", "203.0.113.9"); err != nil {
+		t.Fatalf("another IP was blocked: %v", err.Message)
+	}
+}
+
+func TestLoginGuardCountsAnIPv6PrefixAsOneAddress(t *testing.T) {
+	guard, _ := newTestLoginGuard()
+	ctx := context.Background()
+
+	for i, ip := range []string{"2001:db8::1", "2001:db8::2", "2001:db8::3", "2001:db8::4", "2001:db8::5"} {
+		guard.Failed(ctx, string(rune('a'+i))+"@example.com", ip)
+	}
+
+	if err := guard.Check(ctx, "fresh@example.com", "2001:db8::ffff"); err == nil {
+		t.Fatal("rotating addresses within a /64 avoided the IP lockout")
+	}
+	if err := guard.Check(ctx, "fresh@example.com", "2001:db8:0:1::1"); err != nil {
+		t.Fatalf("another /64 was blocked: %v", err.Message)
+	}
+}
+
+func TestLoginGuardSucceededOnlyClearsTheEmail(t *testing.T) {
+	guard, store := newTestLoginGuard()
+	ctx := context.Background()
+
+	for i := 0; i < 4; i++ {
+		guard.Failed(ctx, "ada@example.com", "198.51.100.4")
+	}
+	guard.Succeeded(ctx, "ada@example.com")
+
+	if wait, _ := store.BlockedFor("login:email:ada@example.com"); wait != 0 {
+		t.Errorf("the email is still blocked for %s", wait)
+	}
+
+	// One more failure reaches the IP limit, its earlier failures were kept
+	guard.Failed(ctx, "other@example.com", "198.51.100.4")
+	assertBlockedFor(t, store, "login:ip:198.51.100.4", time.Hour)
+}
+
+func TestLoginGuardBackoffIsCappedByTheLockout(t *testing.T) {
+	guard, _ := newTestLoginGuard()
+
+	if got := guard.backoff(1); got != time.Second {
+		t.Errorf("backoff(1) = %s", got)
+	}
+	if got := guard.backoff(4); got != 8*time.Second {
+		t.Errorf("backoff(4) = %s", got)
+	}
+	if got := guard.backoff(30); got != time.Hour {
+		t.Errorf("backoff(30) = %s, want the lockout", got)
+	}
+}
+
+// assertBlockedFor allows for the time passed since the block was set
+func assertBlockedFor(t *testing.T, store *MemoryAttemptStore, key string, want time.Duration) {
+	t.Helper()
+
+	wait, err := store.BlockedFor(key)
+	if err != nil {
+		t.Fatal(err.Message)
+	}
+	if wait > want || wait < want-time.Second/2 {
+		t.Errorf("%s is blocked for %s, want %s", key, wait, want)
+	}
+}
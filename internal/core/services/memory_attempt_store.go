@@ -0,0 +1,87 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/go-ms-project-store/internal/pkg/errs"
+)
+
+// MemoryAttemptStore keeps login attempts in process memory. It suits
+// single-node setups; state is lost on restart.
+type MemoryAttemptStore struct {
+	mu      sync.Mutex
+	entries map[string]attemptEntry
+}
+
+type attemptEntry struct {
+	failures     int
+	blockedUntil time.Time
+	expiresAt    time.Time
+}
+
+func (s *MemoryAttemptStore) Block(key string, duration time.Duration) *errs.AppError {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := s.entries[key]
+	entry.blockedUntil = time.Now().Add(duration)
+	if entry.expiresAt.Before(entry.blockedUntil) {
+		entry.expiresAt = entry.blockedUntil
+	}
+	s.entries[key] = entry
+
+	return nil
+}
+
+func (s *MemoryAttemptStore) BlockedFor(key string) (time.Duration, *errs.AppError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return 0, nil
+	}
+
+	if wait := time.Until(entry.blockedUntil); wait > 0 {
+		return wait, nil
+	}
+
+	return 0, nil
+}
+
+func (s *MemoryAttemptStore) Fail(key string, ttl time.Duration) (int, *errs.AppError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range s.entries {
+		if now.After(entry.expiresAt) {
+			delete(s.entries, k)
+		}
+	}
+
+	entry := s.entries[key]
+	entry.failures++
+	if expiresAt := now.Add(ttl); entry.expiresAt.Before(expiresAt) {
+		entry.expiresAt = expiresAt
+	}
+	s.entries[key] = entry
+
+	return entry.failures, nil
+}
+
+func (s *MemoryAttemptStore) Reset(key string) *errs.AppError {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+
+	return nil
+}
+
+func NewMemoryAttemptStore() *MemoryAttemptStore {
+	return &MemoryAttemptStore{
+		entries: make(map[string]attemptEntry),
+	}
+}
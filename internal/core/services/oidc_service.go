@@ -0,0 +1,267 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-ms-project-store/internal/adapters/input/http/dto"
+	"github.com/go-ms-project-store/internal/core/domain"
+	"github.com/go-ms-project-store/internal/pkg/config"
+	"github.com/go-ms-project-store/internal/pkg/errs"
+	"github.com/go-ms-project-store/internal/pkg/helpers"
+	"github.com/go-ms-project-store/internal/pkg/logger"
+	"github.com/go-ms-project-store/internal/pkg/metrics"
+	"github.com/go-ms-project-store/internal/pkg/oidc"
+	"github.com/go-ms-project-store/internal/pkg/tracing"
+	"go.uber.org/zap"
+)
+
+// How long a user has to complete sign in at the provider
+const oidcStateLifetime = 10 * time.Minute
+
+type DefaultOIDCService struct {
+	auth      DefaultAuthService
+	providers map[string]*oidc.Provider
+}
+
+// Authorize starts the authorization code flow. The nonce and PKCE verifier
+// travel encrypted inside the state, so no server side storage is needed.
+// The state is bound to the browser that started the flow by a random value
+// the handler keeps in a cookie, so a state and code obtained by someone
+// else can't be used to sign the browser into their account.
+func (s DefaultOIDCService) Authorize(ctx context.Context, providerName string) (*dto.OIDCAuthorizationResponse, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "OIDCService.Authorize")
+	defer span.End()
+
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, errs.NewNotFoundError("Provider not found")
+	}
+
+	nonce, err := oidc.NewRandomString()
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while generating OIDC nonce", zap.Error(err))
+		return nil, errs.NewUnexpectedError("unexpected error starting sign in")
+	}
+
+	verifier, err := oidc.NewRandomString()
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while generating PKCE verifier", zap.Error(err))
+		return nil, errs.NewUnexpectedError("unexpected error starting sign in")
+	}
+
+	binding, err := oidc.NewRandomString()
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while generating OIDC state binding", zap.Error(err))
+		return nil, errs.NewUnexpectedError("unexpected error starting sign in")
+	}
+
+	payload := strings.Join([]string{providerName, nonce, verifier, binding, fmt.Sprint(time.Now().Unix())}, "|")
+	state, err := helpers.EncryptString(payload, s.auth.cfg.App.KeyBytes())
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while encrypting OIDC state", zap.Error(err))
+		return nil, errs.NewUnexpectedError("unexpected error starting sign in")
+	}
+
+	authURL, err := provider.AuthCodeURL(state, nonce, oidc.CodeChallengeS256(verifier))
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while building authorization URL", zap.String("provider", providerName), zap.Error(err))
+		return nil, errs.NewUnexpectedError("unexpected error starting sign in")
+	}
+
+	return &dto.OIDCAuthorizationResponse{
+		AuthorizationURL: authURL,
+		State:            state,
+		Binding:          binding,
+	}, nil
+}
+
+// Callback completes the flow with the code returned by the provider and
+// signs the user in the same way a password login does
+func (s DefaultOIDCService) Callback(ctx context.Context, providerName string, req dto.OIDCCallbackRequest) (*dto.TokenResponse, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "OIDCService.Callback")
+	defer span.End()
+
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, errs.NewNotFoundError("Provider not found")
+	}
+
+	nonce, verifier, appErr := s.openState(providerName, req.State, req.Binding)
+	if appErr != nil {
+		logger.FromContext(ctx).Warn("Security event: rejected OIDC state", zap.String("provider", providerName))
+		return nil, appErr
+	}
+
+	tokens, err := provider.Exchange(req.Code, verifier)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while exchanging authorization code", zap.String("provider", providerName), zap.Error(err))
+		metrics.Login(metrics.LoginOIDC, metrics.LoginFailed)
+		return nil, errs.NewUnauthorizedError("Unable to sign in with " + providerName)
+	}
+
+	idToken, err := provider.VerifyIDToken(tokens.IDToken, nonce)
+	if err != nil {
+		logger.FromContext(ctx).Warn("Security event: rejected id token", zap.String("provider", providerName), zap.Error(err))
+		metrics.Login(metrics.LoginOIDC, metrics.LoginFailed)
+		return nil, errs.NewUnauthorizedError("Unable to sign in with " + providerName)
+	}
+
+	user, appErr := s.findOrCreateUser(ctx, providerName, *idToken)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if user.IsLocked() {
+		logger.FromContext(ctx).Warn("Login attempt on locked account", zap.Int64("locked_user_id", user.Id))
+		metrics.Login(metrics.LoginOIDC, metrics.LoginLocked)
+		return nil, errs.NewUnauthorizedError("Account is locked")
+	}
+
+	loginReq := dto.NewLoginRequest{
+		Email:      user.Email,
+		DeviceName: req.DeviceName,
+		UserAgent:  req.UserAgent,
+		IpAddress:  req.IpAddress,
+	}
+
+	userId := uint64(user.Id)
+	metrics.Login(metrics.LoginOIDC, metrics.LoginSucceeded)
+	s.auth.recordLoginAttempt(ctx, loginReq, &userId, true)
+
+	return s.auth.startSession(ctx, userId, loginReq)
+}
+
+// findOrCreateUser resolves the identity to a user. Unknown identities are
+// linked to the account with the same email, or to a new customer account,
+// but only when the provider has verified the address. An existing account
+// must have verified the address too: otherwise whoever registered it first,
+// without owning the mailbox, would keep a password on the owner's account.
+func (s DefaultOIDCService) findOrCreateUser(ctx context.Context, providerName string, idToken oidc.IDToken) (*domain.User, *errs.AppError) {
+	repo := s.auth.repo
+
+	identity, err := repo.IdentityRepo().FindByProviderSubject(ctx, providerName, idToken.Subject)
+	if err == nil {
+		return repo.UserRepo().FindById(ctx, identity.UserId)
+	}
+	if err.Code != http.StatusNotFound {
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	if idToken.Email == "" || !idToken.IsEmailVerified() {
+		return nil, errs.NewValidationError("email", "The provider did not return a verified email address")
+	}
+
+	user, err := repo.UserRepo().FindByEmail(ctx, idToken.Email)
+	if err != nil {
+		if err.Code != http.StatusNotFound {
+			return nil, errs.NewUnexpectedError("unexpected database error")
+		}
+
+		user, err = s.registerUser(ctx, idToken)
+		if err != nil {
+			return nil, err
+		}
+	} else if !user.HasVerifiedEmail() {
+		logger.FromContext(ctx).Warn("Security event: refused to link identity to unverified account", zap.String("provider", providerName), zap.Int64("linked_user_id", user.Id))
+		metrics.Login(metrics.LoginOIDC, metrics.LoginFailed)
+		return nil, errs.NewValidationError("email", "An account with this email address exists but hasn't been verified. Verify the email address before signing in with "+providerName)
+	}
+
+	_, err = repo.IdentityRepo().Create(ctx, domain.NewIdentity(uint64(user.Id), providerName, idToken.Subject, idToken.Email))
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// registerUser creates a customer for a new identity. The account gets a
+// random password, which can be replaced through the password reset flow.
+func (s DefaultOIDCService) registerUser(ctx context.Context, idToken oidc.IDToken) (*domain.User, *errs.AppError) {
+	password, err := helpers.GenerateToken()
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while generating password", zap.Error(err))
+		return nil, errs.NewUnexpectedError("unexpected error creating account")
+	}
+
+	name := idToken.Name
+	if name == "" {
+		name = strings.Split(idToken.Email, "@")[0]
+	}
+
+	user, appErr := s.auth.repo.Register(ctx, domain.NewUserRegister(dto.NewUserRegisterRequest{
+		Name:     name,
+		Email:    idToken.Email,
+		Password: password,
+	}))
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if appErr := s.auth.repo.UserRepo().MarkEmailVerified(ctx, uint64(user.Id), user.Email); appErr != nil {
+		return nil, appErr
+	}
+
+	metrics.Registered(metrics.LoginOIDC)
+
+	return user, nil
+}
+
+// openState decrypts the state and checks it was issued for this provider,
+// to the same browser, and recently enough
+func (s DefaultOIDCService) openState(providerName string, state string, binding string) (string, string, *errs.AppError) {
+	invalid := errs.NewValidationError("state", "The sign in request is invalid or has expired")
+
+	payload, err := helpers.DecryptString(state, s.auth.cfg.App.KeyBytes())
+	if err != nil {
+		return "", "", invalid
+	}
+
+	parts := strings.Split(payload, "|")
+	if len(parts) != 5 || parts[0] != providerName {
+		return "", "", invalid
+	}
+
+	if binding == "" || subtle.ConstantTimeCompare([]byte(parts[3]), []byte(binding)) != 1 {
+		return "", "", invalid
+	}
+
+	issuedAt, err := strconv.ParseInt(parts[4], 10, 64)
+	if err != nil || time.Since(time.Unix(issuedAt, 0)) > oidcStateLifetime {
+		return "", "", invalid
+	}
+
+	return parts[1], parts[2], nil
+}
+
+// LoadOIDCProviders configures the providers enabled in the configuration.
+// Providers must support discovery.
+func LoadOIDCProviders(cfg config.OIDC) map[string]*oidc.Provider {
+	providers := make(map[string]*oidc.Provider)
+
+	for _, client := range cfg.Clients {
+		providers[client.Name] = oidc.NewProvider(oidc.Config{
+			Name:         client.Name,
+			Issuer:       client.Issuer,
+			ClientID:     client.ClientID,
+			ClientSecret: client.ClientSecret,
+			RedirectURL:  client.RedirectURL,
+			Scopes:       client.Scopes,
+		}, nil)
+	}
+
+	return providers
+}
+
+func NewOIDCService(auth DefaultAuthService, providers map[string]*oidc.Provider) DefaultOIDCService {
+	return DefaultOIDCService{
+		auth:      auth,
+		providers: providers,
+	}
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-ms-project-store/internal/core/domain"
+	"github.com/go-ms-project-store/internal/core/ports"
+	"github.com/go-ms-project-store/internal/pkg/config"
+	"github.com/go-ms-project-store/internal/pkg/errs"
+	"github.com/go-ms-project-store/internal/pkg/helpers"
+	"github.com/go-ms-project-store/internal/pkg/oidc"
+)
+
+// fakeOIDCRepo stands in for the repositories findOrCreateUser uses. The
+// embedded interfaces are nil, so any other call panics.
+type fakeOIDCRepo struct {
+	ports.AuthRepository
+	users      *fakeUserRepo
+	identities *fakeIdentityRepo
+}
+
+func (r fakeOIDCRepo) UserRepo() ports.UserRepository         { return r.users }
+func (r fakeOIDCRepo) IdentityRepo() ports.IdentityRepository { return r.identities }
+
+type fakeUserRepo struct {
+	ports.UserRepository
+	users map[string]*domain.User
+}
+
+func (r *fakeUserRepo) FindByEmail(_ context.Context, email string) (*domain.User, *errs.AppError) {
+	if user, ok := r.users[email]; ok {
+		return user, nil
+	}
+
+	return nil, errs.NewNotFoundError("User not found")
+}
+
+type fakeIdentityRepo struct {
+	ports.IdentityRepository
+	created []domain.Identity
+}
+
+func (r *fakeIdentityRepo) FindByProviderSubject(context.Context, string, string) (*domain.Identity, *errs.AppError) {
+	return nil, errs.NewNotFoundError("Identity not found")
+}
+
+func (r *fakeIdentityRepo) Create(_ context.Context, identity domain.Identity) (*domain.Identity, *errs.AppError) {
+	r.created = append(r.created, identity)
+	return &identity, nil
+}
+
+func newTestOIDCService(users ...*domain.User) (DefaultOIDCService, *fakeIdentityRepo) {
+	repo := fakeOIDCRepo{
+		users:      &fakeUserRepo{users: make(map[string]*domain.User)},
+		identities: &fakeIdentityRepo{},
+	}
+	for _, user := range users {
+		repo.users.users[user.Email] = user
+	}
+
+	auth := DefaultAuthService{
+		cfg:  &config.Config{App: config.App{Key: "0123456789abcdef0123456789abcdef"}},
+		repo: repo,
+	}
+
+	return NewOIDCService(auth, nil), repo.identities
+}
+
+func TestOIDCLinksAVerifiedAccount(t *testing.T) {
+	verifiedAt := time.Now()
+	service, identities := newTestOIDCService(&domain.User{Id: 7, Email: "ada@example.com", EmailVerifiedAt: &verifiedAt})
+
+	user, err := service.findOrCreateUser(context.Background(), "fake", oidc.IDToken{Subject: "subject-1", Email: "ada@example.com", EmailVerified: true})
+	if err != nil {
+		t.Fatal(err.Message)
+	}
+
+	if user.Id != 7 || len(identities.created) != 1 || identities.created[0].UserId != 7 {
+		t.Errorf("user = %+v, identities = %+v", user, identities.created)
+	}
+}
+
+func TestOIDCRefusesToLinkAnUnverifiedAccount(t *testing.T) {
+	// Someone registered the address without owning the mailbox
+	service, identities := newTestOIDCService(&domain.User{Id: 7, Email: "ada@example.com"})
+
+	_, err := service.findOrCreateUser(context.Background(), "fake", oidc.IDToken{Subject: "subject-1", Email: "ada@example.com", EmailVerified: true})
+	if err == nil || err.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("findOrCreateUser() = %+v, want a validation error", err)
+	}
+
+	if len(identities.created) != 0 {
+		t.Errorf("an identity was linked: %+v", identities.created)
+	}
+}
+
+func TestOIDCRefusesAnUnverifiedProviderEmail(t *testing.T) {
+	verifiedAt := time.Now()
+	service, identities := newTestOIDCService(&domain.User{Id: 7, Email: "ada@example.com", EmailVerifiedAt: &verifiedAt})
+
+	if _, err := service.findOrCreateUser(context.Background(), "fake", oidc.IDToken{Subject: "subject-1", Email: "ada@example.com"}); err == nil {
+		t.Fatal("an address the provider didn't verify was linked")
+	}
+
+	if len(identities.created) != 0 {
+		t.Errorf("an identity was linked: %+v", identities.created)
+	}
+}
+
+func TestOIDCStateIsBoundToTheBrowser(t *testing.T) {
+	service, _ := newTestOIDCService()
+	key := service.auth.cfg.App.KeyBytes()
+
+	seal := func(provider string, binding string, issuedAt time.Time) string {
+		state, err := helpers.EncryptString(strings.Join([]string{provider, "nonce", "verifier", binding, fmt.Sprint(issuedAt.Unix())}, "|"), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return state
+	}
+
+	nonce, verifier, err := service.openState("fake", seal("fake", "browser", time.Now()), "browser")
+	if err != nil || nonce != "nonce" || verifier != "verifier" {
+		t.Fatalf("openState() = %q, %q, %+v", nonce, verifier, err)
+	}
+
+	tests := []struct {
+		name    string
+		state   string
+		binding string
+	}{
+		{"another browser", seal("fake", "browser", time.Now()), "attacker"},
+		{"no cookie", seal("fake", "browser", time.Now()), ""},
+		{"another provider", seal("other", "browser", time.Now()), "browser"},
+		{"expired", seal("fake", "browser", time.Now().Add(-oidcStateLifetime-time.Minute)), "browser"},
+		{"not encrypted", "fake|nonce|verifier|browser|0", "browser"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := service.openState("fake", tt.state, tt.binding); err == nil {
+				t.Fatal("the state was accepted")
+			}
+		})
+	}
+}
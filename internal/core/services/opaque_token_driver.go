@@ -0,0 +1,43 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-ms-project-store/internal/core/domain"
+	"github.com/go-ms-project-store/internal/core/ports"
+	"github.com/go-ms-project-store/internal/pkg/errs"
+)
+
+// OpaqueTokenDriver issues Sanctum-compatible "id|token" access tokens that
+// are stored hashed and checked against the database on every request
+type OpaqueTokenDriver struct {
+	repo ports.AuthRepository
+}
+
+func (d OpaqueTokenDriver) GetTokenAbilities(ctx context.Context, fullToken string) ([]string, *errs.AppError) {
+	return d.repo.GetTokenAbilities(ctx, fullToken)
+}
+
+func (d OpaqueTokenDriver) IssueAccessToken(ctx context.Context, at domain.Token) (string, *errs.AppError) {
+	ac, err := d.repo.CreateAccessToken(ctx, at)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%d|%s", ac.ID, ac.Token), nil
+}
+
+// RevokeSessions is a no-op: deleting the session rows already revokes them
+func (d OpaqueTokenDriver) RevokeSessions(userId uint64, sessionIds ...uint64) {}
+
+// RevokeUser is a no-op: deleting the token rows already revokes them
+func (d OpaqueTokenDriver) RevokeUser(userId uint64) {}
+
+func (d OpaqueTokenDriver) ValidateToken(ctx context.Context, fullToken string) (*domain.Token, *errs.AppError) {
+	return validateOpaqueToken(ctx, d.repo, d, fullToken)
+}
+
+func NewOpaqueTokenDriver(repository ports.AuthRepository) OpaqueTokenDriver {
+	return OpaqueTokenDriver{repo: repository}
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"net/http"
 	"time"
 
@@ -8,6 +9,8 @@ import (
 	"github.com/go-ms-project-store/internal/core/domain"
 	"github.com/go-ms-project-store/internal/core/ports"
 	"github.com/go-ms-project-store/internal/pkg/errs"
+	"github.com/go-ms-project-store/internal/pkg/metrics"
+	"github.com/go-ms-project-store/internal/pkg/tracing"
 	"github.com/google/uuid"
 )
 
@@ -15,7 +18,10 @@ type DefaultOrderService struct {
 	repo ports.OrderRepository
 }
 
-func (s DefaultOrderService) CreateOrder(req dto.NewOrderRequest, user_id uint64) (*domain.Order, *errs.AppError) {
+func (s DefaultOrderService) CreateOrder(ctx context.Context, req dto.NewOrderRequest, user_id uint64) (*domain.Order, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "OrderService.CreateOrder")
+	defer span.End()
+
 	productUUIDs := make([]string, len(req.Products))
 
 	// Extract UUIDs from the request
@@ -24,8 +30,9 @@ func (s DefaultOrderService) CreateOrder(req dto.NewOrderRequest, user_id uint64
 	}
 
 	// Get products from database using WhereIn
-	products, err := s.repo.ProductRepo().WhereIn(productUUIDs)
+	products, err := s.repo.ProductRepo().WhereIn(ctx, productUUIDs)
 	if err != nil {
+		metrics.CheckoutFailed(checkoutFailureReason(err))
 		return nil, err
 	}
 
@@ -62,8 +69,9 @@ func (s DefaultOrderService) CreateOrder(req dto.NewOrderRequest, user_id uint64
 		OrderItems: orderItems,
 	}
 
-	newOrder, err := s.repo.Create(order)
+	newOrder, err := s.repo.Create(ctx, order)
 	if err != nil {
+		metrics.CheckoutFailed(checkoutFailureReason(err))
 		if err.Code != http.StatusUnprocessableEntity {
 			return nil, errs.NewUnexpectedError("unexpected database error")
 		} else {
@@ -71,9 +79,30 @@ func (s DefaultOrderService) CreateOrder(req dto.NewOrderRequest, user_id uint64
 		}
 	}
 
+	metrics.OrderCreated()
+
 	return newOrder, nil
 }
 
+// RecomputeTotals repairs orders whose amount no longer matches their items
+func (s DefaultOrderService) RecomputeTotals(ctx context.Context) (int64, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "OrderService.RecomputeTotals")
+	defer span.End()
+
+	return s.repo.RecomputeTotals(ctx)
+}
+
+func checkoutFailureReason(err *errs.AppError) string {
+	switch err.Code {
+	case http.StatusNotFound:
+		return metrics.CheckoutNoProducts
+	case http.StatusUnprocessableEntity:
+		return metrics.CheckoutInvalid
+	default:
+		return metrics.CheckoutUnexpected
+	}
+}
+
 func NewOrderService(repository ports.OrderRepository) DefaultOrderService {
 	return DefaultOrderService{repo: repository}
 }
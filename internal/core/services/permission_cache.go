@@ -0,0 +1,71 @@
+package services
+
+import (
+	"sync"
+
+	"github.com/go-ms-project-store/internal/core/domain"
+)
+
+// PermissionCache keeps the role and permissions resolved for each user so
+// permission checks don't hit the database on every request. Any change to
+// roles, permissions or user role assignments must invalidate it.
+type PermissionCache struct {
+	mu    sync.RWMutex
+	roles map[int64]domain.Role
+	users map[uint64]int64
+}
+
+func (c *PermissionCache) Get(userId uint64) (*domain.Role, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	roleId, ok := c.users[userId]
+	if !ok {
+		return nil, false
+	}
+
+	role, ok := c.roles[roleId]
+	if !ok {
+		return nil, false
+	}
+
+	return &role, true
+}
+
+func (c *PermissionCache) Set(userId uint64, role domain.Role) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.users[userId] = role.Id
+	c.roles[role.Id] = role
+}
+
+// ForgetRole drops a role so every user holding it is resolved again
+func (c *PermissionCache) ForgetRole(roleId int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.roles, roleId)
+}
+
+func (c *PermissionCache) ForgetUser(userId uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.users, userId)
+}
+
+func (c *PermissionCache) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.roles = make(map[int64]domain.Role)
+	c.users = make(map[uint64]int64)
+}
+
+func NewPermissionCache() *PermissionCache {
+	return &PermissionCache{
+		roles: make(map[int64]domain.Role),
+		users: make(map[uint64]int64),
+	}
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-ms-project-store/internal/adapters/input/http/dto"
+	"github.com/go-ms-project-store/internal/core/domain"
+	"github.com/go-ms-project-store/internal/core/ports"
+	"github.com/go-ms-project-store/internal/pkg/errs"
+	"github.com/go-ms-project-store/internal/pkg/logger"
+	"github.com/go-ms-project-store/internal/pkg/pagination"
+	"github.com/go-ms-project-store/internal/pkg/tracing"
+)
+
+type DefaultPermissionService struct {
+	repo  ports.PermissionRepository
+	cache *PermissionCache
+}
+
+func (s DefaultPermissionService) GetAllPermissions(r *http.Request) (domain.Permissions, int64, pagination.DataDBFilter, *errs.AppError) {
+	ctx, span := tracing.Start(r.Context(), "PermissionService.GetAllPermissions")
+	defer span.End()
+
+	allowedOrderBy := map[string]bool{
+		"id": true, "name": true, "created_at": true, "updated_at": true,
+	}
+
+	filter := pagination.GetBaseFilterParams(r, allowedOrderBy)
+	permissions, totalRows, err := s.repo.FindAll(ctx, filter)
+
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while finding all permissions")
+		return nil, 0, pagination.DataDBFilter{}, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return permissions, totalRows, filter, nil
+}
+
+func (s DefaultPermissionService) CreatePermission(ctx context.Context, req dto.NewPermissionRequest) (*domain.Permission, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "PermissionService.CreatePermission")
+	defer span.End()
+
+	permission := domain.NewPermission(req)
+
+	newPermission, err := s.repo.Create(ctx, permission)
+	if err != nil {
+		if err.Code != http.StatusUnprocessableEntity {
+			return nil, errs.NewUnexpectedError("unexpected database error")
+		} else {
+			return nil, err
+		}
+	}
+
+	return newPermission, nil
+}
+
+func (s DefaultPermissionService) UpdatePermission(ctx context.Context, id int64, req dto.UpdatePermissionRequest) (*domain.Permission, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "PermissionService.UpdatePermission")
+	defer span.End()
+
+	permission := domain.Permission{
+		Id:        id,
+		Name:      req.Name,
+		UpdatedAt: time.Now(),
+	}
+
+	newPermission, err := s.repo.Update(ctx, permission)
+	if err != nil {
+		if err.Code == http.StatusUnprocessableEntity {
+			return nil, err
+		} else if err.Code == http.StatusNotFound {
+			return nil, err
+		}
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	// A permission can be attached to any number of roles
+	s.cache.Flush()
+
+	return newPermission, nil
+}
+
+func (s DefaultPermissionService) FindPermissionById(ctx context.Context, id int) (*domain.Permission, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "PermissionService.FindPermissionById")
+	defer span.End()
+
+	permission, err := s.repo.FindById(ctx, id)
+	if err != nil {
+		if err.Code != http.StatusNotFound {
+			return nil, errs.NewUnexpectedError("unexpected database error")
+		} else {
+			return nil, err
+		}
+	}
+
+	return permission, nil
+}
+
+func (s DefaultPermissionService) DeletePermission(ctx context.Context, id int) (bool, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "PermissionService.DeletePermission")
+	defer span.End()
+
+	err := s.repo.Delete(ctx, id)
+	if err != nil {
+		if err.Code != http.StatusNotFound {
+			return false, errs.NewUnexpectedError("unexpected database error")
+		} else {
+			return false, err
+		}
+	}
+
+	s.cache.Flush()
+
+	return true, nil
+}
+
+func NewPermissionService(repository ports.PermissionRepository, cache *PermissionCache) DefaultPermissionService {
+	return DefaultPermissionService{repo: repository, cache: cache}
+}
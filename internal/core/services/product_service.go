@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"net/http"
 	"time"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/go-ms-project-store/internal/pkg/errs"
 	"github.com/go-ms-project-store/internal/pkg/logger"
 	"github.com/go-ms-project-store/internal/pkg/pagination"
+	"github.com/go-ms-project-store/internal/pkg/tracing"
 )
 
 type DefaultProductService struct {
@@ -17,25 +19,31 @@ type DefaultProductService struct {
 }
 
 func (s DefaultProductService) GetAllProducts(r *http.Request) (domain.Products, int64, pagination.DataDBFilter, *errs.AppError) {
+	ctx, span := tracing.Start(r.Context(), "ProductService.GetAllProducts")
+	defer span.End()
+
 	allowedOrderBy := map[string]bool{
 		"id": true, "name": true, "slug": true, "category_id": true, "created_at": true, "updated_at": true,
 	}
 
 	filter := pagination.GetBaseFilterParams(r, allowedOrderBy)
-	products, totalRows, err := s.repo.FindAll(filter)
+	products, totalRows, err := s.repo.FindAll(ctx, filter)
 
 	if err != nil {
-		logger.Error("Error while finding all products")
+		logger.FromContext(ctx).Error("Error while finding all products")
 		return nil, 0, pagination.DataDBFilter{}, errs.NewUnexpectedError("unexpected database error")
 	}
 
 	return products, totalRows, filter, nil
 }
 
-func (s DefaultProductService) CreateProduct(req dto.NewProductRequest) (*domain.Product, *errs.AppError) {
+func (s DefaultProductService) CreateProduct(ctx context.Context, req dto.NewProductRequest) (*domain.Product, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "ProductService.CreateProduct")
+	defer span.End()
+
 	product := domain.NewProduct(req)
 
-	newProduct, err := s.repo.Create(product)
+	newProduct, err := s.repo.Create(ctx, product)
 	if err != nil {
 		if err.Code != http.StatusUnprocessableEntity {
 			return nil, errs.NewUnexpectedError("unexpected database error")
@@ -47,7 +55,10 @@ func (s DefaultProductService) CreateProduct(req dto.NewProductRequest) (*domain
 	return newProduct, nil
 }
 
-func (s DefaultProductService) UpdateProduct(id int64, req dto.UpdateProductRequest) (*domain.Product, *errs.AppError) {
+func (s DefaultProductService) UpdateProduct(ctx context.Context, id int64, req dto.UpdateProductRequest) (*domain.Product, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "ProductService.UpdateProduct")
+	defer span.End()
+
 	product := domain.Product{
 		Id:          id,
 		Name:        req.Name,
@@ -58,7 +69,7 @@ func (s DefaultProductService) UpdateProduct(id int64, req dto.UpdateProductRequ
 		UpdatedAt:   time.Now(),
 	}
 
-	newProduct, err := s.repo.Update(product)
+	newProduct, err := s.repo.Update(ctx, product)
 	if err != nil {
 		if err.Code == http.StatusUnprocessableEntity {
 			return nil, err
@@ -71,8 +82,11 @@ func (s DefaultProductService) UpdateProduct(id int64, req dto.UpdateProductRequ
 	return newProduct, nil
 }
 
-func (s DefaultProductService) FindProductById(id int) (*domain.Product, *errs.AppError) {
-	product, err := s.repo.FindById(id)
+func (s DefaultProductService) FindProductById(ctx context.Context, id int) (*domain.Product, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "ProductService.FindProductById")
+	defer span.End()
+
+	product, err := s.repo.FindById(ctx, id)
 	if err != nil {
 		if err.Code != http.StatusNotFound {
 			return nil, errs.NewUnexpectedError("unexpected database error")
@@ -84,8 +98,11 @@ func (s DefaultProductService) FindProductById(id int) (*domain.Product, *errs.A
 	return product, nil
 }
 
-func (s DefaultProductService) FindProductBySlug(slug string) (*domain.Product, *errs.AppError) {
-	product, err := s.repo.FindBySlug(slug)
+func (s DefaultProductService) FindProductBySlug(ctx context.Context, slug string) (*domain.Product, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "ProductService.FindProductBySlug")
+	defer span.End()
+
+	product, err := s.repo.FindBySlug(ctx, slug)
 	if err != nil {
 		if err.Code != http.StatusNotFound {
 			return nil, errs.NewUnexpectedError("unexpected database error")
@@ -97,8 +114,11 @@ func (s DefaultProductService) FindProductBySlug(slug string) (*domain.Product,
 	return product, nil
 }
 
-func (s DefaultProductService) DeleteProduct(id int) (bool, *errs.AppError) {
-	err := s.repo.Delete(id)
+func (s DefaultProductService) DeleteProduct(ctx context.Context, id int) (bool, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "ProductService.DeleteProduct")
+	defer span.End()
+
+	err := s.repo.Delete(ctx, id)
 	if err != nil {
 		if err.Code != http.StatusNotFound {
 			return false, errs.NewUnexpectedError("unexpected database error")
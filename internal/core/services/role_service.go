@@ -0,0 +1,228 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-ms-project-store/internal/adapters/input/http/dto"
+	"github.com/go-ms-project-store/internal/core/domain"
+	"github.com/go-ms-project-store/internal/core/enums"
+	"github.com/go-ms-project-store/internal/core/ports"
+	"github.com/go-ms-project-store/internal/pkg/errs"
+	"github.com/go-ms-project-store/internal/pkg/logger"
+	"github.com/go-ms-project-store/internal/pkg/pagination"
+	"github.com/go-ms-project-store/internal/pkg/tracing"
+)
+
+type DefaultRoleService struct {
+	repo  ports.RoleRepository
+	cache *PermissionCache
+}
+
+func (s DefaultRoleService) GetAllRoles(r *http.Request) (domain.Roles, int64, pagination.DataDBFilter, *errs.AppError) {
+	ctx, span := tracing.Start(r.Context(), "RoleService.GetAllRoles")
+	defer span.End()
+
+	allowedOrderBy := map[string]bool{
+		"id": true, "name": true, "created_at": true, "updated_at": true,
+	}
+
+	filter := pagination.GetBaseFilterParams(r, allowedOrderBy)
+	roles, totalRows, err := s.repo.FindAll(ctx, filter)
+
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while finding all roles")
+		return nil, 0, pagination.DataDBFilter{}, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return roles, totalRows, filter, nil
+}
+
+func (s DefaultRoleService) CreateRole(ctx context.Context, req dto.NewRoleRequest) (*domain.Role, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "RoleService.CreateRole")
+	defer span.End()
+
+	role := domain.NewRole(req)
+
+	newRole, err := s.repo.Create(ctx, role)
+	if err != nil {
+		if err.Code != http.StatusUnprocessableEntity {
+			return nil, errs.NewUnexpectedError("unexpected database error")
+		} else {
+			return nil, err
+		}
+	}
+
+	return newRole, nil
+}
+
+func (s DefaultRoleService) UpdateRole(ctx context.Context, id int64, req dto.UpdateRoleRequest) (*domain.Role, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "RoleService.UpdateRole")
+	defer span.End()
+
+	existing, err := s.FindRoleById(ctx, int(id))
+	if err != nil {
+		return nil, err
+	}
+
+	if existing.IsBuiltIn() && existing.Name != req.Name {
+		return nil, errs.NewValidationError("name", "Built-in roles cannot be renamed")
+	}
+
+	role := domain.Role{
+		Id:                id,
+		Name:              req.Name,
+		RequiresTwoFactor: req.RequiresTwoFactor,
+		UpdatedAt:         time.Now(),
+	}
+
+	newRole, err := s.repo.Update(ctx, role)
+	if err != nil {
+		if err.Code == http.StatusUnprocessableEntity {
+			return nil, err
+		} else if err.Code == http.StatusNotFound {
+			return nil, err
+		}
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	s.cache.ForgetRole(id)
+
+	return newRole, nil
+}
+
+func (s DefaultRoleService) FindRoleById(ctx context.Context, id int) (*domain.Role, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "RoleService.FindRoleById")
+	defer span.End()
+
+	role, err := s.repo.FindById(ctx, id)
+	if err != nil {
+		if err.Code != http.StatusNotFound {
+			return nil, errs.NewUnexpectedError("unexpected database error")
+		} else {
+			return nil, err
+		}
+	}
+
+	return role, nil
+}
+
+func (s DefaultRoleService) DeleteRole(ctx context.Context, id int) (bool, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "RoleService.DeleteRole")
+	defer span.End()
+
+	role, err := s.FindRoleById(ctx, id)
+	if err != nil {
+		return false, err
+	}
+
+	if role.IsBuiltIn() {
+		return false, errs.NewValidationError("role", "Built-in roles cannot be deleted")
+	}
+
+	users, err := s.repo.CountUsers(ctx, role.Id)
+	if err != nil {
+		return false, err
+	}
+
+	if users > 0 {
+		return false, errs.NewValidationError("role", "The role is still assigned to users")
+	}
+
+	err = s.repo.Delete(ctx, id)
+	if err != nil {
+		if err.Code != http.StatusNotFound {
+			return false, errs.NewUnexpectedError("unexpected database error")
+		} else {
+			return false, err
+		}
+	}
+
+	s.cache.ForgetRole(role.Id)
+
+	return true, nil
+}
+
+func (s DefaultRoleService) AttachPermissions(ctx context.Context, id int64, req dto.RolePermissionsRequest) (*domain.Role, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "RoleService.AttachPermissions")
+	defer span.End()
+
+	if _, err := s.FindRoleById(ctx, int(id)); err != nil {
+		return nil, err
+	}
+
+	err := s.repo.AttachPermissions(ctx, id, req.Permissions)
+	if err != nil {
+		if err.Code != http.StatusUnprocessableEntity {
+			return nil, errs.NewUnexpectedError("unexpected database error")
+		} else {
+			return nil, err
+		}
+	}
+
+	s.cache.ForgetRole(id)
+
+	return s.FindRoleById(ctx, int(id))
+}
+
+func (s DefaultRoleService) DetachPermission(ctx context.Context, id int64, permissionId int64) (*domain.Role, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "RoleService.DetachPermission")
+	defer span.End()
+
+	role, err := s.FindRoleById(ctx, int(id))
+	if err != nil {
+		return nil, err
+	}
+
+	// The built-in admin role must keep the permissions to manage roles and
+	// users, or nobody could grant them back
+	if role.Name == string(enums.AdminRole) {
+		for _, permission := range role.Permissions {
+			if permission.Id == permissionId && (permission.Name == string(enums.ManageRolesPermission) || permission.Name == string(enums.ManageUsersPermission)) {
+				return nil, errs.NewValidationError("permission", "The permission cannot be detached from the admin role")
+			}
+		}
+	}
+
+	err = s.repo.DetachPermission(ctx, id, permissionId)
+	if err != nil {
+		if err.Code != http.StatusNotFound {
+			return nil, errs.NewUnexpectedError("unexpected database error")
+		} else {
+			return nil, err
+		}
+	}
+
+	s.cache.ForgetRole(id)
+
+	return s.FindRoleById(ctx, int(id))
+}
+
+// GetUserRole resolves the role and permissions of a user, serving from the
+// permission cache when possible
+func (s DefaultRoleService) GetUserRole(ctx context.Context, userId uint64) (*domain.Role, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "RoleService.GetUserRole")
+	defer span.End()
+
+	if role, ok := s.cache.Get(userId); ok {
+		return role, nil
+	}
+
+	role, err := s.repo.FindByUserId(ctx, userId)
+	if err != nil {
+		if err.Code != http.StatusNotFound {
+			return nil, errs.NewUnexpectedError("unexpected database error")
+		} else {
+			return nil, err
+		}
+	}
+
+	s.cache.Set(userId, *role)
+
+	return role, nil
+}
+
+func NewRoleService(repository ports.RoleRepository, cache *PermissionCache) DefaultRoleService {
+	return DefaultRoleService{repo: repository, cache: cache}
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/go-ms-project-store/internal/core/domain"
+	"github.com/go-ms-project-store/internal/core/ports"
+	"github.com/go-ms-project-store/internal/pkg/errs"
+)
+
+// fakeRolePermissionRepo holds one role and records detached permissions
+type fakeRolePermissionRepo struct {
+	ports.RoleRepository
+	role     domain.Role
+	detached []int64
+}
+
+func (r *fakeRolePermissionRepo) FindById(context.Context, int) (*domain.Role, *errs.AppError) {
+	role := r.role
+	return &role, nil
+}
+
+func (r *fakeRolePermissionRepo) DetachPermission(_ context.Context, _ int64, permissionId int64) *errs.AppError {
+	r.detached = append(r.detached, permissionId)
+	return nil
+}
+
+func TestDetachPermissionProtectsTheAdminRole(t *testing.T) {
+	permissions := []domain.Permission{
+		{Id: 1, Name: "manage-catalog"},
+		{Id: 2, Name: "manage-roles"},
+		{Id: 3, Name: "manage-users"},
+	}
+
+	tests := []struct {
+		name         string
+		role         string
+		permissionId int64
+		allowed      bool
+	}{
+		{"manage-roles from admin", "admin", 2, false},
+		{"manage-users from admin", "admin", 3, false},
+		{"another permission from admin", "admin", 1, true},
+		{"manage-users from another role", "support", 3, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRolePermissionRepo{role: domain.Role{Id: 1, Name: tt.role, Permissions: permissions}}
+			service := NewRoleService(repo, NewPermissionCache())
+
+			_, err := service.DetachPermission(context.Background(), 1, tt.permissionId)
+
+			if tt.allowed {
+				if err != nil || len(repo.detached) != 1 {
+					t.Fatalf("DetachPermission() = %+v, detached %v", err, repo.detached)
+				}
+				return
+			}
+
+			if err == nil || err.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("DetachPermission() = %+v, want a validation error", err)
+			}
+			if len(repo.detached) != 0 {
+				t.Errorf("detached %v from the admin role", repo.detached)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// throttle allows an action at most once per interval for each key
+type throttle struct {
+	mu       sync.Mutex
+	interval time.Duration
+	last     map[string]time.Time
+}
+
+// Allow records the attempt and reports whether it may proceed. When it may
+// not, the remaining wait is returned.
+func (t *throttle) Allow(key string) (bool, time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	if last, ok := t.last[key]; ok {
+		if wait := t.interval - now.Sub(last); wait > 0 {
+			return false, wait
+		}
+	}
+
+	for k, last := range t.last {
+		if now.Sub(last) >= t.interval {
+			delete(t.last, k)
+		}
+	}
+
+	t.last[key] = now
+	return true, 0
+}
+
+func newThrottle(interval time.Duration) *throttle {
+	return &throttle{
+		interval: interval,
+		last:     make(map[string]time.Time),
+	}
+}
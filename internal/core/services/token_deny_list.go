@@ -0,0 +1,52 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// TokenDenyList records revoked sessions and users in memory so stateless
+// access tokens can be rejected without a database lookup. An entry only
+// has to outlive the longest access token issued before it.
+type TokenDenyList struct {
+	mu      sync.RWMutex
+	entries map[string]time.Time
+	ttl     time.Duration
+}
+
+// Revoke denies every token for the key issued up to now
+func (l *TokenDenyList) Revoke(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	for k, revokedAt := range l.entries {
+		if now.Sub(revokedAt) > l.ttl {
+			delete(l.entries, k)
+		}
+	}
+
+	l.entries[key] = now
+}
+
+// IsRevoked reports whether a token for the key issued at the given time has
+// been revoked. Times are compared at the microsecond precision tokens carry,
+// so a token issued in the same microsecond as the revocation is denied.
+func (l *TokenDenyList) IsRevoked(key string, issuedAt time.Time) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	revokedAt, ok := l.entries[key]
+	if !ok {
+		return false
+	}
+
+	return !issuedAt.Truncate(time.Microsecond).After(revokedAt.Truncate(time.Microsecond))
+}
+
+func NewTokenDenyList(ttl time.Duration) *TokenDenyList {
+	return &TokenDenyList{
+		entries: make(map[string]time.Time),
+		ttl:     ttl,
+	}
+}
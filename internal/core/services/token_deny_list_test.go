@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-ms-project-store/internal/core/domain"
+	"github.com/go-ms-project-store/internal/core/enums"
+	"github.com/go-ms-project-store/internal/pkg/jwt"
+)
+
+func TestTokenDenyListComparesBelowASecond(t *testing.T) {
+	list := NewTokenDenyList(time.Hour)
+
+	before := time.Now()
+	list.Revoke("sub:1")
+	after := time.Now().Add(time.Microsecond)
+
+	if !list.IsRevoked("sub:1", before) {
+		t.Error("a token issued before the revocation is still accepted")
+	}
+	if list.IsRevoked("sub:1", after) {
+		t.Error("a token issued after the revocation is denied")
+	}
+	if !list.IsRevoked("sub:1", before.Truncate(time.Second)) {
+		t.Error("a token with a second precision issue time from the same second is accepted")
+	}
+	if list.IsRevoked("sub:2", before) {
+		t.Error("another key is denied")
+	}
+}
+
+func TestJWTTokenDriverAcceptsATokenIssuedRightAfterARevocation(t *testing.T) {
+	key := jwt.Key{ID: "test", Algorithm: jwt.HS256, Secret: []byte("0123456789abcdef0123456789abcdef")}
+	driver := NewJWTTokenDriver(&fakeTokenRepo{role: domain.Role{Name: string(enums.CustomerRole)}}, map[string]jwt.Key{key.ID: key}, key, "https://store.example.com", NewTokenDenyList(time.Hour))
+	ctx := context.Background()
+
+	issue := func() string {
+		token, err := driver.IssueAccessToken(ctx, domain.Token{
+			UserID:    1,
+			Abilities: []string{string(enums.AccessTokenAbility)},
+			CreatedAt: time.Now(),
+			ExpiresAt: time.Now().Add(time.Hour),
+		})
+		if err != nil {
+			t.Fatal(err.Message)
+		}
+		return token
+	}
+
+	old := issue()
+	driver.RevokeUser(1)
+	time.Sleep(time.Millisecond)
+	fresh := issue()
+
+	if _, err := driver.ValidateToken(ctx, old); err == nil {
+		t.Error("the token issued before the revocation is still accepted")
+	}
+	if _, err := driver.ValidateToken(ctx, fresh); err != nil {
+		t.Errorf("the token issued after the revocation is denied: %s", err.Message)
+	}
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/go-ms-project-store/internal/core/domain"
+	"github.com/go-ms-project-store/internal/core/ports"
+	"github.com/go-ms-project-store/internal/pkg/config"
+	"github.com/go-ms-project-store/internal/pkg/errs"
+	"github.com/go-ms-project-store/internal/pkg/jwt"
+	"github.com/go-ms-project-store/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// NewTokenDriver selects the access token driver. The opaque driver is the
+// default; "jwt" enables signed stateless tokens.
+func NewTokenDriver(repository ports.AuthRepository, cfg *config.Config) (ports.TokenDriver, error) {
+	switch cfg.Auth.TokenDriver {
+	case "jwt":
+		keys, err := jwt.ParseKeys(cfg.JWT.Algorithm, cfg.JWT.Keys)
+		if err != nil {
+			return nil, err
+		}
+
+		activeKey, ok := keys[cfg.JWT.ActiveKeyID]
+		if !ok {
+			return nil, fmt.Errorf("JWT_ACTIVE_KEY_ID must name one of the JWT_KEYS")
+		}
+
+		return NewJWTTokenDriver(repository, keys, activeKey, cfg.JWT.Issuer, NewTokenDenyList(cfg.Auth.AccessTokenLifetime)), nil
+	case "opaque":
+		return NewOpaqueTokenDriver(repository), nil
+	default:
+		return nil, fmt.Errorf("unknown token driver %q", cfg.Auth.TokenDriver)
+	}
+}
+
+// validateOpaqueToken checks a stored token. Presenting a refresh token that
+// was already rotated revokes the whole family it belongs to.
+func validateOpaqueToken(ctx context.Context, repository ports.AuthRepository, driver ports.TokenDriver, fullToken string) (*domain.Token, *errs.AppError) {
+	token, err := repository.ValidateToken(ctx, fullToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if token.IsRotated() {
+		return nil, revokeTokenFamily(ctx, repository, driver, *token)
+	}
+
+	return token, nil
+}
+
+// revokeTokenFamily signs out the session a reused refresh token belongs to.
+// Tokens issued outside of a session can't be told apart, so every token of
+// the user is revoked instead.
+func revokeTokenFamily(ctx context.Context, repository ports.AuthRepository, driver ports.TokenDriver, token domain.Token) *errs.AppError {
+	logger.FromContext(ctx).Warn("Security event: rotated refresh token reused, revoking its session",
+		zap.Uint64("token_id", token.ID),
+		zap.Uint64("user_id", token.UserID),
+		zap.Uint64("session_id", token.SessionID),
+	)
+
+	var err *errs.AppError
+	if token.SessionID == 0 {
+		err = repository.SessionRepo().DeleteAll(ctx, token.UserID)
+		driver.RevokeUser(token.UserID)
+	} else {
+		err = repository.SessionRepo().Delete(ctx, token.UserID, token.SessionID)
+		driver.RevokeSessions(token.UserID, token.SessionID)
+	}
+	if err != nil && err.Code != http.StatusNotFound {
+		return err
+	}
+
+	return errs.NewUnauthorizedError("Token has been revoked")
+}
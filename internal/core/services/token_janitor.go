@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-ms-project-store/internal/core/ports"
+	"github.com/go-ms-project-store/internal/pkg/config"
+	"github.com/go-ms-project-store/internal/pkg/errs"
+	"github.com/go-ms-project-store/internal/pkg/logger"
+	"github.com/go-ms-project-store/internal/pkg/metrics"
+	"github.com/go-ms-project-store/internal/pkg/tracing"
+	"go.uber.org/zap"
+)
+
+// TokenJanitor deletes expired tokens in the background. Tokens are otherwise
+// only removed on logout or refresh, so expired ones would pile up forever.
+type TokenJanitor struct {
+	repo      ports.AuthRepository
+	interval  time.Duration
+	batchSize int
+}
+
+// Run cleans up on start and then every interval until ctx is done. Every
+// instance may run it, deleting the same rows twice is harmless.
+func (j TokenJanitor) Run(ctx context.Context) {
+	if j.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		j.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce deletes every token expired by now and reports the run
+func (j TokenJanitor) RunOnce(ctx context.Context) {
+	ctx, span := tracing.Start(ctx, "TokenJanitor.RunOnce")
+	defer span.End()
+
+	start := time.Now()
+	deleted, err := pruneExpiredTokens(ctx, j.repo, j.batchSize)
+	duration := time.Since(start)
+
+	if err != nil {
+		metrics.TokenCleanup(metrics.TokenCleanupFailed, deleted, duration)
+		logger.FromContext(ctx).Error("Token cleanup failed",
+			zap.String("error", err.Message),
+			zap.Int64("deleted", deleted),
+			zap.Duration("duration", duration),
+		)
+		return
+	}
+
+	metrics.TokenCleanup(metrics.TokenCleanupSucceeded, deleted, duration)
+	logger.FromContext(ctx).Info("Token cleanup completed",
+		zap.Int64("deleted", deleted),
+		zap.Duration("duration", duration),
+	)
+}
+
+// pruneExpiredTokens deletes in batches so a large backlog doesn't hold locks
+// on the table for long. It stops early when ctx is done.
+func pruneExpiredTokens(ctx context.Context, repo ports.AuthRepository, batchSize int) (int64, *errs.AppError) {
+	now := time.Now()
+
+	var total int64
+	for {
+		deleted, err := repo.DeleteExpiredTokens(ctx, now, batchSize)
+		if err != nil {
+			return total, err
+		}
+		total += deleted
+
+		if deleted < int64(batchSize) || ctx.Err() != nil {
+			return total, nil
+		}
+	}
+}
+
+func NewTokenJanitor(repository ports.AuthRepository, cfg config.Auth) TokenJanitor {
+	return TokenJanitor{
+		repo:      repository,
+		interval:  cfg.TokenCleanupInterval,
+		batchSize: cfg.TokenCleanupBatchSize,
+	}
+}
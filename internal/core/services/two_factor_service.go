@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-ms-project-store/internal/adapters/input/http/dto"
+	"github.com/go-ms-project-store/internal/core/domain"
+	"github.com/go-ms-project-store/internal/core/ports"
+	"github.com/go-ms-project-store/internal/pkg/config"
+	"github.com/go-ms-project-store/internal/pkg/errs"
+	"github.com/go-ms-project-store/internal/pkg/helpers"
+	"github.com/go-ms-project-store/internal/pkg/logger"
+	"github.com/go-ms-project-store/internal/pkg/tracing"
+	"go.uber.org/zap"
+)
+
+const recoveryCodeCount = 8
+
+type DefaultTwoFactorService struct {
+	repo ports.AuthRepository
+	app  config.App
+}
+
+// ConfirmTwoFactor activates a pending enrollment with a code from the
+// authenticator app and returns the recovery codes, which are only shown once
+func (s DefaultTwoFactorService) ConfirmTwoFactor(ctx context.Context, user_id uint64, req dto.ConfirmTwoFactorRequest) ([]string, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "TwoFactorService.ConfirmTwoFactor")
+	defer span.End()
+
+	twoFactor, err := s.repo.TwoFactorRepo().FindByUserId(ctx, user_id)
+	if err != nil {
+		if err.Code == http.StatusNotFound {
+			return nil, errs.NewValidationError("code", "Two-factor authentication has not been enabled")
+		}
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	if twoFactor.IsConfirmed() {
+		return nil, errs.NewValidationError("code", "Two-factor authentication is already confirmed")
+	}
+
+	step, err := checkTOTP(ctx, *twoFactor, req.Code, s.app.KeyBytes())
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.TwoFactorRepo().Confirm(ctx, user_id, step, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// DisableTwoFactor removes the enrollment after checking the password and a
+// current code. Users whose role requires two-factor can't turn it off.
+func (s DefaultTwoFactorService) DisableTwoFactor(ctx context.Context, user_id uint64, req dto.DisableTwoFactorRequest) *errs.AppError {
+	ctx, span := tracing.Start(ctx, "TwoFactorService.DisableTwoFactor")
+	defer span.End()
+
+	role, err := s.repo.RoleRepo().FindByUserId(ctx, user_id)
+	if err != nil && err.Code != http.StatusNotFound {
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	if role != nil && role.RequiresTwoFactor {
+		return errs.NewValidationError("code", "Two-factor authentication is required for your role")
+	}
+
+	if err := s.repo.VerifyPassword(ctx, user_id, req.Password); err != nil {
+		return err
+	}
+
+	if err := verifyTwoFactorCode(ctx, s.repo.TwoFactorRepo(), s.app.KeyBytes(), user_id, req.Code, ""); err != nil {
+		return err
+	}
+
+	return s.repo.TwoFactorRepo().Delete(ctx, user_id)
+}
+
+// EnableTwoFactor starts enrollment with a new secret. It has no effect on
+// login until it is confirmed.
+func (s DefaultTwoFactorService) EnableTwoFactor(ctx context.Context, user_id uint64) (*dto.TwoFactorSetupResponse, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "TwoFactorService.EnableTwoFactor")
+	defer span.End()
+
+	user, err := s.repo.UserRepo().FindById(ctx, user_id)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.TwoFactorRepo().FindByUserId(ctx, user_id)
+	if err != nil && err.Code != http.StatusNotFound {
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	if existing != nil && existing.IsConfirmed() {
+		return nil, errs.NewValidationError("two_factor", "Two-factor authentication is already enabled")
+	}
+
+	secret, genErr := helpers.GenerateTOTPSecret()
+	if genErr != nil {
+		logger.FromContext(ctx).Error("Error while generating two-factor secret", zap.Error(genErr))
+		return nil, errs.NewUnexpectedError("unexpected error enabling two-factor authentication")
+	}
+
+	encrypted, encErr := helpers.EncryptString(secret, s.app.KeyBytes())
+	if encErr != nil {
+		logger.FromContext(ctx).Error("Error while encrypting two-factor secret", zap.Error(encErr))
+		return nil, errs.NewUnexpectedError("unexpected error enabling two-factor authentication")
+	}
+
+	if err := s.repo.TwoFactorRepo().Save(ctx, domain.NewTwoFactor(user_id, encrypted)); err != nil {
+		return nil, err
+	}
+
+	return &dto.TwoFactorSetupResponse{
+		Secret:          secret,
+		ProvisioningURI: helpers.TOTPProvisioningURI(s.app.Name, user.Email, secret),
+	}, nil
+}
+
+func (s DefaultTwoFactorService) IsTwoFactorEnabled(ctx context.Context, user_id uint64) (bool, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "TwoFactorService.IsTwoFactorEnabled")
+	defer span.End()
+
+	twoFactor, err := s.repo.TwoFactorRepo().FindByUserId(ctx, user_id)
+	if err != nil {
+		if err.Code == http.StatusNotFound {
+			return false, nil
+		}
+		return false, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return twoFactor.IsConfirmed(), nil
+}
+
+// RegenerateRecoveryCodes replaces every recovery code, used or not
+func (s DefaultTwoFactorService) RegenerateRecoveryCodes(ctx context.Context, user_id uint64, req dto.ConfirmTwoFactorRequest) ([]string, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "TwoFactorService.RegenerateRecoveryCodes")
+	defer span.End()
+
+	if err := verifyTwoFactorCode(ctx, s.repo.TwoFactorRepo(), s.app.KeyBytes(), user_id, req.Code, ""); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.TwoFactorRepo().ReplaceRecoveryCodes(ctx, user_id, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// verifyTwoFactorCode accepts either a current TOTP code or an unused
+// recovery code for a confirmed enrollment, consuming whichever was used. A
+// TOTP code is refused when its time step, or a later one, was already
+// accepted, so an observed code can't be replayed within its window.
+func verifyTwoFactorCode(ctx context.Context, repo ports.TwoFactorRepository, key []byte, user_id uint64, code string, recoveryCode string) *errs.AppError {
+	twoFactor, err := repo.FindByUserId(ctx, user_id)
+	if err != nil {
+		if err.Code == http.StatusNotFound {
+			return errs.NewValidationError("code", "Two-factor authentication is not enabled")
+		}
+		return errs.NewUnexpectedError("unexpected database error")
+	}
+
+	if !twoFactor.IsConfirmed() {
+		return errs.NewValidationError("code", "Two-factor authentication is not enabled")
+	}
+
+	if recoveryCode != "" {
+		return repo.UseRecoveryCode(ctx, user_id, helpers.HashToken(normalizeRecoveryCode(recoveryCode)))
+	}
+
+	step, err := checkTOTP(ctx, *twoFactor, code, key)
+	if err != nil {
+		return err
+	}
+
+	if twoFactor.LastUsedStep != nil && step <= *twoFactor.LastUsedStep {
+		return errs.NewValidationError("code", "The two-factor code has already been used")
+	}
+
+	// The update is conditional too, for codes submitted concurrently
+	return repo.MarkStepUsed(ctx, user_id, step)
+}
+
+func checkTOTP(ctx context.Context, twoFactor domain.TwoFactor, code string, key []byte) (int64, *errs.AppError) {
+	secret, err := helpers.DecryptString(twoFactor.Secret, key)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error while decrypting two-factor secret", zap.Error(err))
+		return 0, errs.NewUnexpectedError("unexpected error verifying two-factor code")
+	}
+
+	step, ok := helpers.VerifyTOTP(secret, code, time.Now())
+	if !ok {
+		return 0, errs.NewValidationError("code", "The two-factor code is invalid")
+	}
+
+	return step, nil
+}
+
+// generateRecoveryCodes returns plaintext codes for the user and the hashes
+// to store
+func generateRecoveryCodes(ctx context.Context) ([]string, []string, *errs.AppError) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+
+	for i := range codes {
+		raw := make([]byte, 5)
+		if _, err := rand.Read(raw); err != nil {
+			logger.FromContext(ctx).Error("Error while generating recovery code", zap.Error(err))
+			return nil, nil, errs.NewUnexpectedError("unexpected error generating recovery codes")
+		}
+
+		code := hex.EncodeToString(raw)
+		codes[i] = code[:5] + "-" + code[5:]
+		hashes[i] = helpers.HashToken(normalizeRecoveryCode(codes[i]))
+	}
+
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
+
+func NewTwoFactorService(repository ports.AuthRepository, app config.App) DefaultTwoFactorService {
+	return DefaultTwoFactorService{repo: repository, app: app}
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-ms-project-store/internal/adapters/input/http/dto"
+	"github.com/go-ms-project-store/internal/core/domain"
+	"github.com/go-ms-project-store/internal/core/ports"
+	"github.com/go-ms-project-store/internal/pkg/config"
+	"github.com/go-ms-project-store/internal/pkg/errs"
+	"github.com/go-ms-project-store/internal/pkg/helpers"
+)
+
+const testTOTPSecret = "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+
+// fakeTwoFactorRepo keeps one confirmed enrollment in memory
+type fakeTwoFactorRepo struct {
+	ports.TwoFactorRepository
+	twoFactor domain.TwoFactor
+}
+
+func (r *fakeTwoFactorRepo) FindByUserId(context.Context, uint64) (*domain.TwoFactor, *errs.AppError) {
+	twoFactor := r.twoFactor
+	return &twoFactor, nil
+}
+
+func (r *fakeTwoFactorRepo) MarkStepUsed(_ context.Context, _ uint64, step int64) *errs.AppError {
+	if r.twoFactor.LastUsedStep != nil && *r.twoFactor.LastUsedStep >= step {
+		return errs.NewValidationError("code", "The two-factor code has already been used")
+	}
+	r.twoFactor.LastUsedStep = &step
+
+	return nil
+}
+
+type fakeChallengeRepo struct {
+	ports.AuthRepository
+	twoFactor *fakeTwoFactorRepo
+	deleted   []uint64
+}
+
+func (r *fakeChallengeRepo) TwoFactorRepo() ports.TwoFactorRepository { return r.twoFactor }
+
+func (r *fakeChallengeRepo) DeleteChallengeToken(_ context.Context, _ uint64, tokenId uint64) *errs.AppError {
+	r.deleted = append(r.deleted, tokenId)
+	return nil
+}
+
+func newTestTwoFactorAuth(t *testing.T) (DefaultAuthService, *fakeChallengeRepo, *MemoryAttemptStore) {
+	t.Helper()
+
+	key := "0123456789abcdef0123456789abcdef"
+	secret, err := helpers.EncryptString(testTOTPSecret, []byte(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	confirmedAt := time.Now()
+	repo := &fakeChallengeRepo{twoFactor: &fakeTwoFactorRepo{twoFactor: domain.TwoFactor{UserId: 1, Secret: secret, ConfirmedAt: &confirmedAt}}}
+	store := NewMemoryAttemptStore()
+
+	return DefaultAuthService{
+		cfg:        &config.Config{App: config.App{Key: key}},
+		repo:       repo,
+		loginGuard: newLoginGuard(store, config.Auth{LoginLockoutDuration: time.Hour}),
+	}, repo, store
+}
+
+// totpAt computes the RFC 6238 code an authenticator shows at the time
+func totpAt(t *testing.T, at time.Time) string {
+	t.Helper()
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(testTOTPSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(at.Unix()/30))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	return fmt.Sprintf("%06d", (binary.BigEndian.Uint32(sum[offset:offset+4])&0x7fffffff)%1000000)
+}
+
+func TestVerifyTwoFactorCodeRejectsAReplayedCode(t *testing.T) {
+	service, repo, _ := newTestTwoFactorAuth(t)
+	ctx := context.Background()
+	key := service.cfg.App.KeyBytes()
+	now := time.Now()
+
+	if err := verifyTwoFactorCode(ctx, repo.twoFactor, key, 1, totpAt(t, now), ""); err != nil {
+		t.Fatalf("a current code was refused: %s", err.Message)
+	}
+
+	err := verifyTwoFactorCode(ctx, repo.twoFactor, key, 1, totpAt(t, now), "")
+	if err == nil || err.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("a replayed code = %+v, want a validation error", err)
+	}
+
+	// Still within the allowed drift, but older than the accepted code
+	if err := verifyTwoFactorCode(ctx, repo.twoFactor, key, 1, totpAt(t, now.Add(-30*time.Second)), ""); err == nil {
+		t.Fatal("a code of an earlier step was accepted after a later one")
+	}
+}
+
+func TestVerifyTwoFactorBacksOffAcrossChallenges(t *testing.T) {
+	service, _, store := newTestTwoFactorAuth(t)
+	ctx := context.Background()
+
+	_, err := service.VerifyTwoFactor(ctx, 1, 10, dto.VerifyTwoFactorRequest{Code: "000000"})
+	if err == nil || err.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("an invalid code = %+v, want a validation error", err)
+	}
+	assertBlockedFor(t, store, "login:2fa:1", time.Second)
+
+	// Logging in again for a new challenge doesn't clear the wait
+	_, err = service.VerifyTwoFactor(ctx, 1, 11, dto.VerifyTwoFactorRequest{Code: totpAt(t, time.Now())})
+	if err == nil || err.Code != http.StatusTooManyRequests {
+		t.Fatalf("a code during the backoff = %+v, want a 429", err)
+	}
+}
+
+func TestVerifyTwoFactorLocksOutTheUser(t *testing.T) {
+	service, repo, store := newTestTwoFactorAuth(t)
+	ctx := context.Background()
+
+	// Earlier failures on other challenges, each after its backoff
+	for i := 0; i < maxTwoFactorFailures-1; i++ {
+		service.loginGuard.TwoFactorFailed(ctx, 1)
+	}
+	store.Block("login:2fa:1", 0)
+
+	_, err := service.VerifyTwoFactor(ctx, 1, 12, dto.VerifyTwoFactorRequest{Code: "000000"})
+	if err == nil || err.Code != http.StatusUnauthorized {
+		t.Fatalf("the last allowed failure = %+v, want a 401", err)
+	}
+	if len(repo.deleted) != 1 || repo.deleted[0] != 12 {
+		t.Errorf("deleted challenges = %v, want the current one", repo.deleted)
+	}
+	assertBlockedFor(t, store, "login:2fa:1", time.Hour)
+}
@@ -1,29 +1,39 @@
 package services
 
 import (
+	"context"
 	"net/http"
+	"time"
 
+	"github.com/go-ms-project-store/internal/adapters/input/http/dto"
 	"github.com/go-ms-project-store/internal/core/domain"
+	"github.com/go-ms-project-store/internal/core/enums"
 	"github.com/go-ms-project-store/internal/core/ports"
 	"github.com/go-ms-project-store/internal/pkg/errs"
 	"github.com/go-ms-project-store/internal/pkg/logger"
 	"github.com/go-ms-project-store/internal/pkg/pagination"
+	"github.com/go-ms-project-store/internal/pkg/tracing"
 )
 
 type DefaultUserService struct {
-	repo ports.UserRepository
+	repo   ports.UserRepository
+	cache  *PermissionCache
+	tokens ports.TokenDriver
 }
 
 func (s DefaultUserService) GetAllUsers(r *http.Request) (domain.Users, int64, pagination.DataDBFilter, *errs.AppError) {
+	ctx, span := tracing.Start(r.Context(), "UserService.GetAllUsers")
+	defer span.End()
+
 	allowedOrderBy := map[string]bool{
 		"id": true, "name": true, "slug": true, "role_id": true, "created_at": true, "updated_at": true,
 	}
 
 	filter := pagination.GetBaseFilterParams(r, allowedOrderBy)
-	users, totalRows, err := s.repo.FindAll(filter, "")
+	users, totalRows, err := s.repo.FindAll(ctx, filter, "")
 
 	if err != nil {
-		logger.Error("Error while finding all users")
+		logger.FromContext(ctx).Error("Error while finding all users")
 		return nil, 0, pagination.DataDBFilter{}, errs.NewUnexpectedError("unexpected database error")
 	}
 
@@ -31,15 +41,18 @@ func (s DefaultUserService) GetAllUsers(r *http.Request) (domain.Users, int64, p
 }
 
 func (s DefaultUserService) GetAllUserCustomers(r *http.Request) (domain.Users, int64, pagination.DataDBFilter, *errs.AppError) {
+	ctx, span := tracing.Start(r.Context(), "UserService.GetAllUserCustomers")
+	defer span.End()
+
 	allowedOrderBy := map[string]bool{
 		"id": true, "name": true, "slug": true, "role_id": true, "created_at": true, "updated_at": true,
 	}
 
 	filter := pagination.GetBaseFilterParams(r, allowedOrderBy)
-	users, totalRows, err := s.repo.FindAllCustomers(filter)
+	users, totalRows, err := s.repo.FindAllCustomers(ctx, filter)
 
 	if err != nil {
-		logger.Error("Error while finding all users")
+		logger.FromContext(ctx).Error("Error while finding all users")
 		return nil, 0, pagination.DataDBFilter{}, errs.NewUnexpectedError("unexpected database error")
 	}
 
@@ -47,23 +60,29 @@ func (s DefaultUserService) GetAllUserCustomers(r *http.Request) (domain.Users,
 }
 
 func (s DefaultUserService) GetAllUserAdmins(r *http.Request) (domain.Users, int64, pagination.DataDBFilter, *errs.AppError) {
+	ctx, span := tracing.Start(r.Context(), "UserService.GetAllUserAdmins")
+	defer span.End()
+
 	allowedOrderBy := map[string]bool{
 		"id": true, "name": true, "slug": true, "role_id": true, "created_at": true, "updated_at": true,
 	}
 
 	filter := pagination.GetBaseFilterParams(r, allowedOrderBy)
-	users, totalRows, err := s.repo.FindAllAdmins(filter)
+	users, totalRows, err := s.repo.FindAllAdmins(ctx, filter)
 
 	if err != nil {
-		logger.Error("Error while finding all users")
+		logger.FromContext(ctx).Error("Error while finding all users")
 		return nil, 0, pagination.DataDBFilter{}, errs.NewUnexpectedError("unexpected database error")
 	}
 
 	return users, totalRows, filter, nil
 }
 
-func (s DefaultUserService) FindUserById(id string) (*domain.User, *errs.AppError) {
-	user, err := s.repo.FindByUuid(id)
+func (s DefaultUserService) FindUserById(ctx context.Context, id string) (*domain.User, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "UserService.FindUserById")
+	defer span.End()
+
+	user, err := s.repo.FindByUuid(ctx, id)
 	if err != nil {
 		if err.Code != http.StatusNotFound {
 			return nil, errs.NewUnexpectedError("unexpected database error")
@@ -75,19 +94,218 @@ func (s DefaultUserService) FindUserById(id string) (*domain.User, *errs.AppErro
 	return user, nil
 }
 
-func (s DefaultUserService) DeleteUser(id string) (bool, *errs.AppError) {
-	err := s.repo.Delete(id)
+func (s DefaultUserService) DeleteUser(ctx context.Context, id string) (bool, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "UserService.DeleteUser")
+	defer span.End()
+
+	user, err := s.FindUserById(ctx, id)
+	if err != nil {
+		return false, err
+	}
+
+	last, err := isLastAdmin(ctx, s.repo, user)
+	if err != nil {
+		return false, err
+	}
+	if last {
+		return false, errs.NewValidationError("user", "The last admin cannot be deleted")
+	}
+
+	err = s.repo.Delete(ctx, id)
+	if err != nil {
+		return false, err
+	}
+
+	s.tokens.RevokeUser(uint64(user.Id))
+
+	return true, nil
+}
+
+func (s DefaultUserService) CreateUser(ctx context.Context, req dto.NewUserRequest) (*domain.User, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "UserService.CreateUser")
+	defer span.End()
+
+	role, err := s.findRole(ctx, req.RoleId)
+	if err != nil {
+		return nil, err
+	}
+
+	newUser, err := s.repo.Create(ctx, domain.NewUser(req))
+	if err != nil {
+		if err.Code != http.StatusUnprocessableEntity {
+			return nil, errs.NewUnexpectedError("unexpected database error")
+		} else {
+			return nil, err
+		}
+	}
+
+	newUser.Role = *role
+
+	return newUser, nil
+}
+
+func (s DefaultUserService) UpdateUser(ctx context.Context, id string, req dto.UpdateUserRequest) (*domain.User, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "UserService.UpdateUser")
+	defer span.End()
+
+	user, err := s.FindUserById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := s.findRole(ctx, req.RoleId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.guardLastAdmin(ctx, user, role); err != nil {
+		return nil, err
+	}
+
+	user.Name = req.Name
+	user.Email = req.Email
+	user.RoleId = role.Id
+	user.UpdatedAt = time.Now()
+
+	updatedUser, err := s.repo.Update(ctx, *user)
+	if err != nil {
+		if err.Code == http.StatusUnprocessableEntity {
+			return nil, err
+		} else if err.Code == http.StatusNotFound {
+			return nil, err
+		}
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	s.cache.ForgetUser(uint64(user.Id))
+
+	return updatedUser, nil
+}
+
+func (s DefaultUserService) UpdateUserRole(ctx context.Context, id string, req dto.UpdateUserRoleRequest) (*domain.User, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "UserService.UpdateUserRole")
+	defer span.End()
+
+	user, err := s.FindUserById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := s.findRole(ctx, req.RoleId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.guardLastAdmin(ctx, user, role); err != nil {
+		return nil, err
+	}
+
+	err = s.repo.UpdateRole(ctx, id, role.Id)
 	if err != nil {
 		if err.Code != http.StatusNotFound {
-			return false, errs.NewUnexpectedError("unexpected database error")
+			return nil, errs.NewUnexpectedError("unexpected database error")
 		} else {
-			return false, err
+			return nil, err
 		}
 	}
 
-	return true, nil
+	s.cache.ForgetUser(uint64(user.Id))
+
+	return s.FindUserById(ctx, id)
+}
+
+func (s DefaultUserService) LockUser(ctx context.Context, id string) (*domain.User, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "UserService.LockUser")
+	defer span.End()
+
+	user, err := s.FindUserById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	last, err := isLastAdmin(ctx, s.repo, user)
+	if err != nil {
+		return nil, err
+	}
+	if last {
+		return nil, errs.NewValidationError("user", "The last admin cannot be locked")
+	}
+
+	lockedUser, err := s.setLocked(ctx, id, true)
+	if err != nil {
+		return nil, err
+	}
+
+	s.tokens.RevokeUser(uint64(lockedUser.Id))
+
+	return lockedUser, nil
+}
+
+func (s DefaultUserService) UnlockUser(ctx context.Context, id string) (*domain.User, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "UserService.UnlockUser")
+	defer span.End()
+
+	return s.setLocked(ctx, id, false)
+}
+
+func (s DefaultUserService) setLocked(ctx context.Context, id string, locked bool) (*domain.User, *errs.AppError) {
+	err := s.repo.SetLocked(ctx, id, locked)
+	if err != nil {
+		if err.Code != http.StatusNotFound {
+			return nil, errs.NewUnexpectedError("unexpected database error")
+		} else {
+			return nil, err
+		}
+	}
+
+	return s.FindUserById(ctx, id)
+}
+
+func (s DefaultUserService) findRole(ctx context.Context, id int64) (*domain.Role, *errs.AppError) {
+	role, err := s.repo.RoleRepo().FindById(ctx, int(id))
+	if err != nil {
+		if err.Code == http.StatusNotFound {
+			return nil, errs.NewValidationError("role_id", "The role does not exist")
+		}
+		return nil, errs.NewUnexpectedError("unexpected database error")
+	}
+
+	return role, nil
+}
+
+// guardLastAdmin prevents moving the only remaining admin to another role
+func (s DefaultUserService) guardLastAdmin(ctx context.Context, user *domain.User, role *domain.Role) *errs.AppError {
+	if user.Role.Name != string(enums.AdminRole) || role.Name == string(enums.AdminRole) {
+		return nil
+	}
+
+	last, err := isLastAdmin(ctx, s.repo, user)
+	if err != nil {
+		return err
+	}
+	if last {
+		return errs.NewValidationError("role_id", "The last admin cannot be demoted")
+	}
+
+	return nil
+}
+
+// isLastAdmin tells whether the user is the only admin able to sign in.
+// Locked admins don't count: losing one doesn't take away access, and
+// keeping one as the last admin would leave nobody to unlock it.
+func isLastAdmin(ctx context.Context, repo ports.UserRepository, user *domain.User) (bool, *errs.AppError) {
+	if user.Role.Name != string(enums.AdminRole) || user.IsLocked() {
+		return false, nil
+	}
+
+	admins, err := repo.CountUnlockedByRole(ctx, string(enums.AdminRole))
+	if err != nil {
+		return false, err
+	}
+
+	return admins <= 1, nil
 }
 
-func NewUserService(repository ports.UserRepository) DefaultUserService {
-	return DefaultUserService{repo: repository}
+func NewUserService(repository ports.UserRepository, cache *PermissionCache, tokens ports.TokenDriver) DefaultUserService {
+	return DefaultUserService{repo: repository, cache: cache, tokens: tokens}
 }
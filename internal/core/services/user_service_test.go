@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-ms-project-store/internal/core/domain"
+	"github.com/go-ms-project-store/internal/core/enums"
+	"github.com/go-ms-project-store/internal/core/ports"
+	"github.com/go-ms-project-store/internal/pkg/errs"
+)
+
+type fakeAdminCounter struct {
+	ports.UserRepository
+	unlockedAdmins int64
+}
+
+func (r fakeAdminCounter) CountUnlockedByRole(context.Context, string) (int64, *errs.AppError) {
+	return r.unlockedAdmins, nil
+}
+
+func TestIsLastAdmin(t *testing.T) {
+	lockedAt := time.Now()
+	admin := domain.Role{Name: string(enums.AdminRole)}
+	customer := domain.Role{Name: string(enums.CustomerRole)}
+
+	tests := []struct {
+		name           string
+		user           domain.User
+		unlockedAdmins int64
+		want           bool
+	}{
+		{"only admin", domain.User{Role: admin}, 1, true},
+		{"other admins remain", domain.User{Role: admin}, 2, false},
+		{"the other admin is locked", domain.User{Role: admin}, 1, true},
+		{"locked admin", domain.User{Role: admin, LockedAt: &lockedAt}, 1, false},
+		{"customer", domain.User{Role: customer}, 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := isLastAdmin(context.Background(), fakeAdminCounter{unlockedAdmins: tt.unlockedAdmins}, &tt.user)
+			if err != nil {
+				t.Fatal(err.Message)
+			}
+			if got != tt.want {
+				t.Errorf("isLastAdmin() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+// Config holds every setting of the application. Each field names the
+// environment variable it is read from and its default; see Load for the
+// order in which sources are applied.
+type Config struct {
+	App       App
+	Server    Server
+	DB        DB
+	Auth      Auth
+	JWT       JWT
+	Mail      Mail
+	CORS      CORS
+	RateLimit RateLimit
+	Redis     Redis
+	OIDC      OIDC
+	Metrics   Metrics
+	Tracing   Tracing
+}
+
+type App struct {
+	Name string `env:"APP_NAME" default:"Store"`
+	// URL is the public base URL used for links sent outside of a request
+	URL string `env:"APP_URL" default:"http://localhost:8686"`
+	// Key signs and encrypts application secrets such as email links
+	Key string `env:"APP_KEY" secret:"true"`
+}
+
+// KeyBytes returns the application key for signing and encryption
+func (a App) KeyBytes() []byte {
+	return []byte(a.Key)
+}
+
+type Server struct {
+	Addr              string        `env:"SERVER_ADDR" default:"localhost:8686"`
+	ReadTimeout       time.Duration `env:"SERVER_READ_TIMEOUT" default:"15s"`
+	ReadHeaderTimeout time.Duration `env:"SERVER_READ_HEADER_TIMEOUT" default:"5s"`
+	WriteTimeout      time.Duration `env:"SERVER_WRITE_TIMEOUT" default:"30s"`
+	IdleTimeout       time.Duration `env:"SERVER_IDLE_TIMEOUT" default:"120s"`
+	MaxHeaderBytes    int           `env:"SERVER_MAX_HEADER_BYTES" default:"1048576"`
+	// ShutdownTimeout bounds how long in-flight requests may take to drain
+	ShutdownTimeout time.Duration `env:"SERVER_SHUTDOWN_TIMEOUT" default:"30s"`
+	// DrainDelay keeps serving after readiness turns down on shutdown, giving
+	// load balancers time to stop routing new requests here
+	DrainDelay time.Duration `env:"SERVER_DRAIN_DELAY" default:"0s"`
+	// HealthCheckTimeout bounds each readiness check
+	HealthCheckTimeout time.Duration `env:"SERVER_HEALTH_CHECK_TIMEOUT" default:"2s"`
+	// TrustedProxies lists the addresses or CIDR ranges of the proxies whose
+	// X-Forwarded-For is believed. Leave empty when clients connect directly.
+	TrustedProxies []string `env:"SERVER_TRUSTED_PROXIES"`
+	// TLS is served when both files are set; they are reloaded on SIGHUP
+	TLSCertFile string `env:"SERVER_TLS_CERT_FILE"`
+	TLSKeyFile  string `env:"SERVER_TLS_KEY_FILE"`
+}
+
+// TLSEnabled reports whether the server should serve HTTPS
+func (s Server) TLSEnabled() bool {
+	return s.TLSCertFile != ""
+}
+
+type DB struct {
+	Driver          string        `env:"DB_CON" default:"mysql"`
+	Host            string        `env:"DB_HOST" default:"localhost"`
+	Port            int           `env:"DB_PORT" default:"3306"`
+	Name            string        `env:"DB_NAME" default:"store"`
+	User            string        `env:"DB_USER" default:"root"`
+	Password        string        `env:"DB_PASSWORD" secret:"true"`
+	MaxOpenConns    int           `env:"DB_MAX_OPEN_CONNS" default:"10"`
+	MaxIdleConns    int           `env:"DB_MAX_IDLE_CONNS" default:"10"`
+	ConnMaxLifetime time.Duration `env:"DB_CONN_MAX_LIFETIME" default:"3m"`
+	// RequireMigrations refuses to start while migrations are pending
+	RequireMigrations bool `env:"DB_REQUIRE_MIGRATIONS" default:"false"`
+}
+
+type Auth struct {
+	// TokenDriver is "opaque" for stored tokens or "jwt" for signed ones
+	TokenDriver           string        `env:"TOKEN_DRIVER" default:"opaque"`
+	AccessTokenLifetime   time.Duration `env:"ACCESS_TOKEN_LIFETIME" default:"60m"`
+	RefreshTokenLifetime  time.Duration `env:"REFRESH_TOKEN_LIFETIME" default:"168h"`
+	RequireVerifiedEmail  bool          `env:"REQUIRE_VERIFIED_EMAIL" default:"false"`
+	LoginMaxAttempts      int           `env:"LOGIN_MAX_ATTEMPTS" default:"5"`
+	LoginMaxAttemptsPerIP int           `env:"LOGIN_MAX_ATTEMPTS_PER_IP" default:"20"`
+	LoginLockoutDuration  time.Duration `env:"LOGIN_LOCKOUT_DURATION" default:"15m"`
+	// Lifetimes of the links and challenges sent during sign in
+	PasswordResetLifetime      time.Duration `env:"PASSWORD_RESET_LIFETIME" default:"30m"`
+	EmailVerificationLifetime  time.Duration `env:"EMAIL_VERIFICATION_LIFETIME" default:"24h"`
+	TwoFactorChallengeLifetime time.Duration `env:"TWO_FACTOR_CHALLENGE_LIFETIME" default:"5m"`
+	// TokenCleanupInterval is how often expired tokens are deleted, 0 disables it
+	TokenCleanupInterval time.Duration `env:"TOKEN_CLEANUP_INTERVAL" default:"1h"`
+	// TokenCleanupBatchSize bounds the rows removed by each delete statement
+	TokenCleanupBatchSize int `env:"TOKEN_CLEANUP_BATCH_SIZE" default:"1000"`
+}
+
+type JWT struct {
+	Algorithm string `env:"JWT_ALGORITHM" default:"HS256"`
+	// Keys lists kid:base64key pairs, see jwt.ParseKeys
+	Keys        string `env:"JWT_KEYS" secret:"true"`
+	ActiveKeyID string `env:"JWT_ACTIVE_KEY_ID"`
+	// Issuer defaults to the application URL
+	Issuer string `env:"JWT_ISSUER"`
+}
+
+type Mail struct {
+	// Driver is "log", "smtp" or "file"
+	Driver   string `env:"MAIL_DRIVER" default:"log"`
+	Host     string `env:"MAIL_HOST" default:"localhost"`
+	Port     int    `env:"MAIL_PORT" default:"587"`
+	Username string `env:"MAIL_USERNAME"`
+	Password string `env:"MAIL_PASSWORD" secret:"true"`
+	From     string `env:"MAIL_FROM" default:"no-reply@localhost"`
+	FilePath string `env:"MAIL_FILE_PATH" default:"storage/mail"`
+}
+
+type CORS struct {
+	AllowedOrigins   []string      `env:"CORS_ALLOWED_ORIGINS"`
+	AllowCredentials bool          `env:"CORS_ALLOW_CREDENTIALS" default:"true"`
+	MaxAge           time.Duration `env:"CORS_MAX_AGE" default:"5m"`
+}
+
+// RateLimit holds the rate of each route group as limit/window, e.g. "60/1m"
+type RateLimit struct {
+	// Store is "memory" or "redis"
+	Store    string `env:"RATE_LIMIT_STORE" default:"memory"`
+	Public   string `env:"RATE_LIMIT_PUBLIC" default:"120/1m"`
+	Auth     string `env:"RATE_LIMIT_AUTH" default:"10/1m"`
+	Account  string `env:"RATE_LIMIT_ACCOUNT" default:"60/1m"`
+	Admin    string `env:"RATE_LIMIT_ADMIN" default:"300/1m"`
+	Checkout string `env:"RATE_LIMIT_CHECKOUT" default:"10/1m"`
+	// Token limits each bearer token on routes personal tokens can call
+	Token string `env:"RATE_LIMIT_TOKEN" default:"60/1m"`
+}
+
+type Redis struct {
+	Addr     string `env:"REDIS_ADDR" default:"localhost:6379"`
+	Password string `env:"REDIS_PASSWORD" secret:"true"`
+	DB       int    `env:"REDIS_DB" default:"0"`
+}
+
+// Metrics configures the Prometheus endpoint. It is served on the API
+// listener, so keep it off public ingress or restrict the path upstream.
+type Metrics struct {
+	Enabled bool   `env:"METRICS_ENABLED" default:"true"`
+	Path    string `env:"METRICS_PATH" default:"/metrics"`
+}
+
+// Tracing configures OpenTelemetry. Spans are exported over OTLP/HTTP when an
+// endpoint is set, e.g. http://localhost:4318 for a local collector.
+type Tracing struct {
+	Endpoint    string  `env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	SampleRatio float64 `env:"OTEL_TRACES_SAMPLE_RATIO" default:"1"`
+}
+
+// OIDC lists the enabled OpenID Connect providers. The clients are read from
+// OIDC_<NAME>_ISSUER, _CLIENT_ID, _CLIENT_SECRET, _REDIRECT_URL and _SCOPES.
+type OIDC struct {
+	Providers []string `env:"OIDC_PROVIDERS"`
+	Clients   []OIDCClient
+}
+
+type OIDCClient struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+func oidcKey(provider string, setting string) string {
+	return "OIDC_" + strings.ToUpper(provider) + "_" + setting
+}
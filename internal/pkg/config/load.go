@@ -0,0 +1,219 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// defaultFile is read when present and no other file is given
+const defaultFile = ".env"
+
+// Load builds the configuration from, in increasing precedence: defaults, a
+// configuration file in .env format, environment variables and flags. The
+// file is given with -config; without it .env is used when it exists.
+// Every setting has a flag named after its variable, e.g. -db-host for
+// DB_HOST. The result is validated before it is returned.
+func Load(args []string) (*Config, error) {
+	var cfg Config
+
+	fields := settings(&cfg)
+
+	flags := flag.NewFlagSet("api", flag.ContinueOnError)
+	file := flags.String("config", "", "path to a configuration file in .env format")
+	flagValues := make(map[string]*string, len(fields))
+	for _, f := range fields {
+		flagValues[f.key] = flags.String(flagName(f.key), "", fmt.Sprintf("sets %s", f.key))
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	fileValues, err := readFile(*file)
+	if err != nil {
+		return nil, err
+	}
+
+	setFlags := make(map[string]string)
+	flags.Visit(func(f *flag.Flag) {
+		for key, value := range flagValues {
+			if flagName(key) == f.Name {
+				setFlags[key] = *value
+			}
+		}
+	})
+
+	lookup := func(key string) (string, bool) {
+		if value, ok := setFlags[key]; ok {
+			return value, true
+		}
+		if value, ok := os.LookupEnv(key); ok {
+			return value, true
+		}
+		value, ok := fileValues[key]
+		return value, ok
+	}
+
+	var errs []error
+	for _, f := range fields {
+		// Blank values, common in copied .env files, keep the default
+		raw, ok := lookup(f.key)
+		if !ok || strings.TrimSpace(raw) == "" {
+			raw = f.fallback
+		}
+
+		if err := assign(f.value, raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", f.key, err))
+		}
+	}
+
+	for _, provider := range cfg.OIDC.Providers {
+		name := strings.ToLower(provider)
+		client := OIDCClient{Name: name}
+		client.Issuer, _ = lookup(oidcKey(name, "ISSUER"))
+		client.ClientID, _ = lookup(oidcKey(name, "CLIENT_ID"))
+		client.ClientSecret, _ = lookup(oidcKey(name, "CLIENT_SECRET"))
+		client.RedirectURL, _ = lookup(oidcKey(name, "REDIRECT_URL"))
+		scopes, _ := lookup(oidcKey(name, "SCOPES"))
+		client.Scopes = strings.Fields(scopes)
+		cfg.OIDC.Clients = append(cfg.OIDC.Clients, client)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	cfg.App.URL = strings.TrimSuffix(cfg.App.URL, "/")
+	if cfg.JWT.Issuer == "" {
+		cfg.JWT.Issuer = cfg.App.URL
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+// setting is a configuration field together with its variable and default
+type setting struct {
+	key      string
+	fallback string
+	secret   bool
+	value    reflect.Value
+}
+
+// settings lists the fields of every section that are read from a variable
+func settings(cfg *Config) []setting {
+	var fields []setting
+
+	sections := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < sections.NumField(); i++ {
+		section := sections.Field(i)
+		for j := 0; j < section.NumField(); j++ {
+			field := section.Type().Field(j)
+			key := field.Tag.Get("env")
+			if key == "" {
+				continue
+			}
+
+			fields = append(fields, setting{
+				key:      key,
+				fallback: field.Tag.Get("default"),
+				secret:   field.Tag.Get("secret") == "true",
+				value:    section.Field(j),
+			})
+		}
+	}
+
+	return fields
+}
+
+func readFile(path string) (map[string]string, error) {
+	if path == "" {
+		if _, err := os.Stat(defaultFile); err != nil {
+			return map[string]string{}, nil
+		}
+		path = defaultFile
+	}
+
+	values, err := godotenv.Read(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading configuration file %s: %w", path, err)
+	}
+
+	return values, nil
+}
+
+func assign(value reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+
+	switch value.Interface().(type) {
+	case string:
+		value.SetString(raw)
+	case int:
+		if raw == "" {
+			value.SetInt(0)
+			return nil
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number", raw)
+		}
+		value.SetInt(int64(n))
+	case bool:
+		if raw == "" {
+			value.SetBool(false)
+			return nil
+		}
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", raw)
+		}
+		value.SetBool(b)
+	case float64:
+		if raw == "" {
+			value.SetFloat(0)
+			return nil
+		}
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", raw)
+		}
+		value.SetFloat(f)
+	case time.Duration:
+		if raw == "" {
+			value.SetInt(0)
+			return nil
+		}
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a duration such as 30s, 15m or 1h", raw)
+		}
+		value.SetInt(int64(d))
+	case []string:
+		var list []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		value.Set(reflect.ValueOf(list))
+	default:
+		return fmt.Errorf("unsupported setting type %s", value.Type())
+	}
+
+	return nil
+}
+
+func flagName(key string) string {
+	return strings.ToLower(strings.ReplaceAll(key, "_", "-"))
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+const redacted = "[redacted]"
+
+// String lists every setting as KEY=value with secrets redacted, so the
+// configuration can be logged safely
+func (c Config) String() string {
+	var b strings.Builder
+
+	for _, f := range settings(&c) {
+		value := fmt.Sprint(f.value.Interface())
+		if list, ok := f.value.Interface().([]string); ok {
+			value = strings.Join(list, ",")
+		}
+		if f.secret && value != "" {
+			value = redacted
+		}
+		fmt.Fprintf(&b, "%s=%s\n", f.key, value)
+	}
+
+	for _, client := range c.OIDC.Clients {
+		secret := ""
+		if client.ClientSecret != "" {
+			secret = redacted
+		}
+		fmt.Fprintf(&b, "%s=%s\n", oidcKey(client.Name, "ISSUER"), client.Issuer)
+		fmt.Fprintf(&b, "%s=%s\n", oidcKey(client.Name, "CLIENT_ID"), client.ClientID)
+		fmt.Fprintf(&b, "%s=%s\n", oidcKey(client.Name, "CLIENT_SECRET"), secret)
+		fmt.Fprintf(&b, "%s=%s\n", oidcKey(client.Name, "REDIRECT_URL"), client.RedirectURL)
+		fmt.Fprintf(&b, "%s=%s\n", oidcKey(client.Name, "SCOPES"), strings.Join(client.Scopes, " "))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-ms-project-store/internal/pkg/helpers"
+	"github.com/go-ms-project-store/internal/pkg/jwt"
+	"github.com/go-ms-project-store/internal/pkg/ratelimit"
+)
+
+// Validate reports every invalid setting at once, naming its variable
+func (c Config) Validate() error {
+	var errs []error
+	invalid := func(key string, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: "+format, append([]interface{}{key}, args...)...))
+	}
+
+	if c.App.Key == "" {
+		invalid("APP_KEY", "is required")
+	}
+	if u, err := url.Parse(c.App.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		invalid("APP_URL", "%q is not an absolute http(s) URL", c.App.URL)
+	}
+
+	if c.Server.Addr == "" {
+		invalid("SERVER_ADDR", "is required")
+	}
+	timeouts := map[string]time.Duration{
+		"SERVER_READ_TIMEOUT":         c.Server.ReadTimeout,
+		"SERVER_READ_HEADER_TIMEOUT":  c.Server.ReadHeaderTimeout,
+		"SERVER_WRITE_TIMEOUT":        c.Server.WriteTimeout,
+		"SERVER_IDLE_TIMEOUT":         c.Server.IdleTimeout,
+		"SERVER_SHUTDOWN_TIMEOUT":     c.Server.ShutdownTimeout,
+		"SERVER_HEALTH_CHECK_TIMEOUT": c.Server.HealthCheckTimeout,
+	}
+	for key, timeout := range timeouts {
+		if timeout <= 0 {
+			invalid(key, "must be positive")
+		}
+	}
+	if c.Server.DrainDelay < 0 {
+		invalid("SERVER_DRAIN_DELAY", "can't be negative")
+	}
+	if c.Server.MaxHeaderBytes < 1024 {
+		invalid("SERVER_MAX_HEADER_BYTES", "must be at least 1024")
+	}
+	if _, err := helpers.ParseTrustedProxies(c.Server.TrustedProxies); err != nil {
+		invalid("SERVER_TRUSTED_PROXIES", "%s", err)
+	}
+	if (c.Server.TLSCertFile == "") != (c.Server.TLSKeyFile == "") {
+		invalid("SERVER_TLS_KEY_FILE", "SERVER_TLS_CERT_FILE and SERVER_TLS_KEY_FILE must be set together")
+	}
+
+	if c.DB.Driver != "mysql" {
+		invalid("DB_CON", "unsupported database driver %q", c.DB.Driver)
+	}
+	if c.DB.Port < 1 || c.DB.Port > 65535 {
+		invalid("DB_PORT", "%d is not a valid port", c.DB.Port)
+	}
+	if c.DB.Name == "" {
+		invalid("DB_NAME", "is required")
+	}
+	if c.DB.MaxOpenConns < 1 {
+		invalid("DB_MAX_OPEN_CONNS", "must be at least 1")
+	}
+
+	if c.Auth.AccessTokenLifetime <= 0 {
+		invalid("ACCESS_TOKEN_LIFETIME", "must be positive")
+	}
+	if c.Auth.RefreshTokenLifetime <= c.Auth.AccessTokenLifetime {
+		invalid("REFRESH_TOKEN_LIFETIME", "must be longer than ACCESS_TOKEN_LIFETIME")
+	}
+	if c.Auth.LoginMaxAttempts < 1 {
+		invalid("LOGIN_MAX_ATTEMPTS", "must be at least 1")
+	}
+	if c.Auth.LoginMaxAttemptsPerIP < 1 {
+		invalid("LOGIN_MAX_ATTEMPTS_PER_IP", "must be at least 1")
+	}
+	if c.Auth.LoginLockoutDuration <= 0 {
+		invalid("LOGIN_LOCKOUT_DURATION", "must be positive")
+	}
+	if c.Auth.PasswordResetLifetime <= 0 {
+		invalid("PASSWORD_RESET_LIFETIME", "must be positive")
+	}
+	if c.Auth.EmailVerificationLifetime <= 0 {
+		invalid("EMAIL_VERIFICATION_LIFETIME", "must be positive")
+	}
+	if c.Auth.TwoFactorChallengeLifetime <= 0 {
+		invalid("TWO_FACTOR_CHALLENGE_LIFETIME", "must be positive")
+	}
+	if c.Auth.TokenCleanupInterval < 0 {
+		invalid("TOKEN_CLEANUP_INTERVAL", "can't be negative")
+	}
+	if c.Auth.TokenCleanupBatchSize < 1 {
+		invalid("TOKEN_CLEANUP_BATCH_SIZE", "must be at least 1")
+	}
+
+	switch c.Auth.TokenDriver {
+	case "opaque":
+	case "jwt":
+		keys, err := jwt.ParseKeys(c.JWT.Algorithm, c.JWT.Keys)
+		if err != nil {
+			invalid("JWT_KEYS", "%s", err)
+		} else if _, ok := keys[c.JWT.ActiveKeyID]; !ok {
+			invalid("JWT_ACTIVE_KEY_ID", "must name one of the JWT_KEYS")
+		}
+	default:
+		invalid("TOKEN_DRIVER", "unknown token driver %q, expected opaque or jwt", c.Auth.TokenDriver)
+	}
+
+	switch c.Mail.Driver {
+	case "log", "file":
+	case "smtp":
+		if c.Mail.Host == "" {
+			invalid("MAIL_HOST", "is required for the smtp driver")
+		}
+	default:
+		invalid("MAIL_DRIVER", "unknown mail driver %q, expected log, smtp or file", c.Mail.Driver)
+	}
+
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" && c.CORS.AllowCredentials {
+			invalid("CORS_ALLOWED_ORIGINS", "* can't be combined with CORS_ALLOW_CREDENTIALS")
+		}
+	}
+
+	if c.RateLimit.Store != "memory" && c.RateLimit.Store != "redis" {
+		invalid("RATE_LIMIT_STORE", "unknown store %q, expected memory or redis", c.RateLimit.Store)
+	}
+	rates := map[string]string{
+		"RATE_LIMIT_PUBLIC":   c.RateLimit.Public,
+		"RATE_LIMIT_AUTH":     c.RateLimit.Auth,
+		"RATE_LIMIT_ACCOUNT":  c.RateLimit.Account,
+		"RATE_LIMIT_ADMIN":    c.RateLimit.Admin,
+		"RATE_LIMIT_CHECKOUT": c.RateLimit.Checkout,
+		"RATE_LIMIT_TOKEN":    c.RateLimit.Token,
+	}
+	for key, rate := range rates {
+		if _, _, err := ratelimit.ParseRate(rate); err != nil {
+			invalid(key, "%s", err)
+		}
+	}
+
+	if c.Metrics.Enabled && !strings.HasPrefix(c.Metrics.Path, "/") {
+		invalid("METRICS_PATH", "must start with /")
+	}
+
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		invalid("OTEL_TRACES_SAMPLE_RATIO", "must be between 0 and 1")
+	}
+	if c.Tracing.Endpoint != "" {
+		if u, err := url.Parse(c.Tracing.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			invalid("OTEL_EXPORTER_OTLP_ENDPOINT", "%q is not an absolute http(s) URL", c.Tracing.Endpoint)
+		}
+	}
+
+	for _, client := range c.OIDC.Clients {
+		if client.Issuer == "" {
+			invalid(oidcKey(client.Name, "ISSUER"), "is required")
+		}
+		if client.ClientID == "" {
+			invalid(oidcKey(client.Name, "CLIENT_ID"), "is required")
+		}
+		if client.RedirectURL == "" {
+			invalid(oidcKey(client.Name, "REDIRECT_URL"), "is required")
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -1,26 +1,18 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"os"
-	"time"
 
+	"github.com/go-ms-project-store/internal/pkg/config"
 	"github.com/go-ms-project-store/internal/pkg/errs"
 	"github.com/go-ms-project-store/internal/pkg/logger"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
 )
 
-type DBData struct {
-	DBCon  string
-	DBHost string
-	DBName string
-	DBUser string
-	DBPass string
-	DBPort string
-}
-
 type FieldVerifier struct {
 	DB        *sqlx.DB
 	TableName string
@@ -48,13 +40,13 @@ func ProcessUUID(uuidBytes []byte) (uuid.UUID, error) {
 }
 
 // VerifyUniqueField checks if a field value is unique in the table, excluding a specific ID
-func (fv *FieldVerifier) VerifyUniqueField(fieldName, fieldValue string, excludeID int64) *errs.AppError {
+func (fv *FieldVerifier) VerifyUniqueField(ctx context.Context, fieldName, fieldValue string, excludeID int64) *errs.AppError {
 	query := fmt.Sprintf("SELECT id FROM %s WHERE %s = ? AND id != ?", fv.TableName, fieldName)
 	var existingID int64
-	err := fv.DB.QueryRow(query, fieldValue, excludeID).Scan(&existingID)
+	err := fv.DB.QueryRowContext(ctx, query, fieldValue, excludeID).Scan(&existingID)
 
 	if err != nil && err != sql.ErrNoRows {
-		logger.Error(fmt.Sprintf("Error checking for existing %s: %s", fieldName, err.Error()))
+		logger.FromContext(ctx).Error("Error checking for existing value", zap.String("field", fieldName), zap.Error(err))
 		return errs.NewUnexpectedError("Unexpected database error")
 	}
 
@@ -65,63 +57,24 @@ func (fv *FieldVerifier) VerifyUniqueField(fieldName, fieldValue string, exclude
 	return nil
 }
 
-func GetDBClient() *sqlx.DB {
-	var dbCreds = DBData{}
-	setDBData(&dbCreds)
-
+func GetDBClient(cfg config.DB) *sqlx.DB {
 	db, err := sqlx.Open(
-		dbCreds.DBCon,
-		fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true",
-			dbCreds.DBUser,
-			dbCreds.DBPass,
-			dbCreds.DBHost,
-			dbCreds.DBPort,
-			dbCreds.DBName,
+		cfg.Driver,
+		fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true",
+			cfg.User,
+			cfg.Password,
+			cfg.Host,
+			cfg.Port,
+			cfg.Name,
 		),
 	)
 	if err != nil {
 		panic(err)
 	}
 
-	db.SetConnMaxLifetime(time.Minute * 3)
-	db.SetMaxOpenConns(10)
-	db.SetMaxIdleConns(10)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
 
 	return db
 }
-
-func setDBData(db *DBData) {
-	dbHost := os.Getenv("DB_HOST")
-	if dbHost == "" {
-		dbHost = "localhost"
-	}
-
-	dbName := os.Getenv("DB_NAME")
-	if dbName == "" {
-		dbName = "banking"
-	}
-
-	dbUser := os.Getenv("DB_USER")
-	if dbUser == "" {
-		dbUser = "root"
-	}
-
-	dbPass := os.Getenv("DB_PASSWORD")
-
-	dbPort := os.Getenv("DB_PORT")
-	if dbPort == "" {
-		dbPort = "3307"
-	}
-
-	dbCon := os.Getenv("DB_CON")
-	if dbCon == "" {
-		dbCon = "mysql"
-	}
-
-	db.DBHost = dbHost
-	db.DBName = dbName
-	db.DBUser = dbUser
-	db.DBPass = dbPass
-	db.DBPort = dbPort
-	db.DBCon = dbCon
-}
@@ -1,46 +1,113 @@
 package errs
 
-import "net/http"
+import (
+	"math"
+	"net/http"
+	"time"
+)
+
+// Machine readable error codes. Clients may rely on them, so they must not
+// change once released.
+const (
+	CodeBadRequest        = "bad_request"
+	CodeUnauthorized      = "unauthorized"
+	CodeForbidden         = "forbidden"
+	CodeNotFound          = "not_found"
+	CodeMethodNotAllowed  = "method_not_allowed"
+	CodeValidationFailed  = "validation_failed"
+	CodeTooManyRequests   = "too_many_requests"
+	CodeInternalError     = "internal_error"
+	CodeMissingAbility    = "missing_ability"
+	CodeMissingPermission = "missing_permission"
+	CodeTwoFactorRequired = "two_factor_required"
+	CodeEmailNotVerified  = "email_not_verified"
+)
 
 type AppError struct {
 	Code    int                 `json:"-"`
 	Message string              `json:"message"`
 	Errors  map[string][]string `json:"errors,omitempty"`
+	// ErrorCode is one of the Code* constants
+	ErrorCode string `json:"code,omitempty"`
+	// RetryAfter is the number of seconds to send in the Retry-After header
+	RetryAfter int `json:"-"`
+}
+
+// WithCode replaces the error code with a more specific one
+func (e *AppError) WithCode(code string) *AppError {
+	e.ErrorCode = code
+	return e
 }
 
-func (e AppError) AsMessage() *AppError {
+func NewBadRequestError(message string) *AppError {
 	return &AppError{
-		Message: e.Message,
+		Message:   message,
+		Code:      http.StatusBadRequest,
+		ErrorCode: CodeBadRequest,
 	}
 }
 
 func NewNotFoundError(message string) *AppError {
 	return &AppError{
-		Message: message,
-		Code:    http.StatusNotFound,
+		Message:   message,
+		Code:      http.StatusNotFound,
+		ErrorCode: CodeNotFound,
 	}
 }
 
 func NewUnauthorizedError(message string) *AppError {
 	return &AppError{
-		Message: message,
-		Code:    http.StatusUnauthorized,
+		Message:   message,
+		Code:      http.StatusUnauthorized,
+		ErrorCode: CodeUnauthorized,
 	}
 }
 
 func NewUnexpectedError(message string) *AppError {
 	return &AppError{
-		Message: message,
-		Code:    http.StatusInternalServerError,
+		Message:   message,
+		Code:      http.StatusInternalServerError,
+		ErrorCode: CodeInternalError,
 	}
 }
 
 func NewValidationError(field, message string) *AppError {
+	return NewValidationErrors(message, map[string][]string{
+		field: {message},
+	})
+}
+
+// NewValidationErrors reports several invalid fields at once
+func NewValidationErrors(message string, errors map[string][]string) *AppError {
+	return &AppError{
+		Message:   message,
+		Code:      http.StatusUnprocessableEntity,
+		ErrorCode: CodeValidationFailed,
+		Errors:    errors,
+	}
+}
+
+func NewForbiddenError(message string) *AppError {
+	return &AppError{
+		Message:   message,
+		Code:      http.StatusForbidden,
+		ErrorCode: CodeForbidden,
+	}
+}
+
+func NewTooManyRequestsError(message string) *AppError {
+	return &AppError{
+		Message:   message,
+		Code:      http.StatusTooManyRequests,
+		ErrorCode: CodeTooManyRequests,
+	}
+}
+
+func NewRetryAfterError(message string, retryAfter time.Duration) *AppError {
 	return &AppError{
-		Message: message,
-		Code:    http.StatusUnprocessableEntity,
-		Errors: map[string][]string{
-			field: {message},
-		},
+		Message:    message,
+		Code:       http.StatusTooManyRequests,
+		ErrorCode:  CodeTooManyRequests,
+		RetryAfter: int(math.Ceil(retryAfter.Seconds())),
 	}
 }
@@ -0,0 +1,41 @@
+package errs
+
+import "net/http"
+
+const ProblemContentType = "application/problem+json"
+
+// Problem is the RFC 7807 body every error response is sent as. Code and
+// Errors are extension members.
+type Problem struct {
+	Type      string              `json:"type"`
+	Title     string              `json:"title"`
+	Status    int                 `json:"status"`
+	Detail    string              `json:"detail,omitempty"`
+	Instance  string              `json:"instance,omitempty"`
+	Code      string              `json:"code"`
+	RequestID string              `json:"request_id,omitempty"`
+	Errors    map[string][]string `json:"errors,omitempty"`
+}
+
+// AsProblem describes the error as a problem. Errors built without a status
+// are treated as unexpected.
+func (e AppError) AsProblem() Problem {
+	status := e.Code
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+
+	code := e.ErrorCode
+	if code == "" {
+		code = CodeInternalError
+	}
+
+	return Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: e.Message,
+		Code:   code,
+		Errors: e.Errors,
+	}
+}
@@ -0,0 +1,32 @@
+package health
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-ms-project-store/internal/pkg/migrate"
+	"github.com/jmoiron/sqlx"
+)
+
+// DBChecker pings the database
+func DBChecker(db *sqlx.DB) Checker {
+	return CheckFunc("mysql", func(ctx context.Context) error {
+		return db.PingContext(ctx)
+	})
+}
+
+// MigrationsChecker reports down while migrations are pending, so a release
+// isn't served against a schema it doesn't know
+func MigrationsChecker(migrator *migrate.Migrator) Checker {
+	return CheckFunc("migrations", func(ctx context.Context) error {
+		pending, err := migrator.Pending(ctx)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("%d migrations are pending", len(pending))
+		}
+
+		return nil
+	})
+}
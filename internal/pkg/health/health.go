@@ -0,0 +1,136 @@
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Checker reports whether a dependency the application needs is usable.
+// Subsystems such as the mailer or a cache register one with the Registry.
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+// CheckFunc adapts a function to a named Checker
+func CheckFunc(name string, check func(ctx context.Context) error) Checker {
+	return checkFunc{name: name, check: check}
+}
+
+type checkFunc struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+func (c checkFunc) Name() string {
+	return c.name
+}
+
+func (c checkFunc) Check(ctx context.Context) error {
+	return c.check(ctx)
+}
+
+type CheckResult struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status string        `json:"status"`
+	Checks []CheckResult `json:"checks"`
+}
+
+// Registry runs the registered checks for readiness. It reports down while
+// draining, so traffic moves away before the server stops.
+type Registry struct {
+	timeout  time.Duration
+	draining atomic.Bool
+
+	mu       sync.RWMutex
+	checkers []Checker
+}
+
+func NewRegistry(timeout time.Duration) *Registry {
+	return &Registry{timeout: timeout}
+}
+
+func (r *Registry) Register(checker Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checkers = append(r.checkers, checker)
+}
+
+// SetDraining marks the application as shutting down
+func (r *Registry) SetDraining() {
+	r.draining.Store(true)
+}
+
+// Ready runs every check concurrently, each bounded by the timeout
+func (r *Registry) Ready(ctx context.Context) Report {
+	r.mu.RLock()
+	checkers := append([]Checker(nil), r.checkers...)
+	r.mu.RUnlock()
+
+	results := make([]CheckResult, len(checkers))
+
+	var wg sync.WaitGroup
+	for i, checker := range checkers {
+		wg.Add(1)
+		go func(i int, checker Checker) {
+			defer wg.Done()
+			results[i] = r.run(ctx, checker)
+		}(i, checker)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	report := Report{Status: StatusUp, Checks: results}
+	if r.draining.Load() {
+		report.Status = StatusDown
+		report.Checks = append(report.Checks, CheckResult{
+			Name:   "shutdown",
+			Status: StatusDown,
+			Error:  "the server is draining connections",
+		})
+	}
+	for _, result := range results {
+		if result.Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+
+	return report
+}
+
+func (r *Registry) run(ctx context.Context, checker Checker) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := checker.Check(ctx)
+	result := CheckResult{
+		Name:      checker.Name(),
+		Status:    StatusUp,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+
+	return result
+}
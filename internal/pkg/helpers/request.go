@@ -0,0 +1,114 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+type clientIPKey struct{}
+
+// TrustedProxies lists the networks allowed to report the client address in
+// X-Forwarded-For. Requests from anywhere else are keyed by their peer.
+type TrustedProxies []*net.IPNet
+
+// ParseTrustedProxies reads addresses or CIDR ranges, e.g. "10.0.0.0/8"
+func ParseTrustedProxies(values []string) (TrustedProxies, error) {
+	proxies := make(TrustedProxies, 0, len(values))
+
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return nil, fmt.Errorf("%q is not an IP address or CIDR range", value)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an IP address or CIDR range", value)
+		}
+		proxies = append(proxies, network)
+	}
+
+	return proxies, nil
+}
+
+func (t TrustedProxies) Contains(address string) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range t {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ClientIP resolves the address of the client. X-Forwarded-For is only read
+// when the peer is a trusted proxy, and then from the right: every hop is
+// appended by the proxy in front of it, so the first one that isn't trusted
+// is the last address a trusted proxy saw. Entries to its left are whatever
+// the client sent and can't be relied on.
+func (t TrustedProxies) ClientIP(r *http.Request) string {
+	peer := remoteIP(r)
+	if !t.Contains(peer) {
+		return peer
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		if !t.Contains(hop) {
+			return hop
+		}
+		peer = hop
+	}
+
+	// Every hop is trusted, the leftmost one is the closest to the client
+	return peer
+}
+
+// WithClientIP stores the resolved client address for GetClientIP
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// GetClientIP returns the client address resolved for the request, or the
+// peer address when no proxy was trusted to resolve it
+func GetClientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPKey{}).(string); ok {
+		return ip
+	}
+
+	return remoteIP(r)
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
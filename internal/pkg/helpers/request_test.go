@@ -0,0 +1,74 @@
+package helpers
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.10", "2001:db8::/32"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		want         string
+		trustProxies bool
+	}{
+		{"direct client", "203.0.113.7:5000", nil, "203.0.113.7", true},
+		{"header from an untrusted peer is ignored", "203.0.113.7:5000", []string{"1.1.1.1"}, "203.0.113.7", true},
+		{"no trusted proxies ignores the header", "10.0.0.1:5000", []string{"1.1.1.1"}, "10.0.0.1", false},
+		{"single trusted proxy", "10.0.0.1:5000", []string{"198.51.100.4"}, "198.51.100.4", true},
+		{"spoofed entries left of the client are ignored", "10.0.0.1:5000", []string{"6.6.6.6, 198.51.100.4"}, "198.51.100.4", true},
+		{"chain of trusted proxies", "10.0.0.1:5000", []string{"6.6.6.6, 198.51.100.4, 192.168.1.10, 10.2.3.4"}, "198.51.100.4", true},
+		{"repeated headers are one list", "10.0.0.1:5000", []string{"6.6.6.6", "198.51.100.4, 10.2.3.4"}, "198.51.100.4", true},
+		{"only trusted hops", "10.0.0.1:5000", []string{"10.9.9.9, 10.2.3.4"}, "10.9.9.9", true},
+		{"trusted proxy without the header", "10.0.0.1:5000", nil, "10.0.0.1", true},
+		{"ipv6 proxy", "[2001:db8::1]:5000", []string{"198.51.100.4"}, "198.51.100.4", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwardedFor {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+
+			trusted := proxies
+			if !tt.trustProxies {
+				trusted = nil
+			}
+
+			if got := trusted.ClientIP(r); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetClientIPPrefersTheResolvedAddress(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.0.0.1:5000"
+	r.Header.Set("X-Forwarded-For", "6.6.6.6")
+
+	if got := GetClientIP(r); got != "10.0.0.1" {
+		t.Errorf("GetClientIP() without a resolved address = %q, want the peer", got)
+	}
+
+	r = r.WithContext(WithClientIP(r.Context(), "198.51.100.4"))
+	if got := GetClientIP(r); got != "198.51.100.4" {
+		t.Errorf("GetClientIP() = %q, want the resolved address", got)
+	}
+}
+
+func TestParseTrustedProxiesRejectsInvalidEntries(t *testing.T) {
+	for _, value := range []string{"proxy.internal", "10.0.0.0/33", "10.0.0"} {
+		if _, err := ParseTrustedProxies([]string{value}); err == nil {
+			t.Errorf("ParseTrustedProxies(%q) accepted an invalid entry", value)
+		}
+	}
+}
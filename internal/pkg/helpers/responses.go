@@ -3,17 +3,49 @@ package helpers
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
+
+	"github.com/go-ms-project-store/internal/pkg/errs"
+	"github.com/go-ms-project-store/internal/pkg/logger"
+	"go.uber.org/zap"
 )
 
+// WriteResponse sends data as JSON. The body is encoded before anything is
+// written, so a value that can't be encoded turns into a 500 problem.
 func WriteResponse(w http.ResponseWriter, code int, data interface{}) {
-	w.Header().Add("Content-Type", "application/json")
-	w.WriteHeader(code)
-
 	if code == http.StatusNoContent {
+		w.WriteHeader(code)
 		return
 	}
 
-	if err := json.NewEncoder(w).Encode(data); err != nil {
-		panic(err)
+	writeJSON(w, code, "application/json", data)
+}
+
+// WriteError sends err as an RFC 7807 problem. The request ID set by the
+// request log middleware is included so clients can quote it.
+func WriteError(w http.ResponseWriter, r *http.Request, err *errs.AppError) {
+	problem := err.AsProblem()
+	problem.Instance = r.URL.Path
+	problem.RequestID = w.Header().Get("X-Request-ID")
+
+	if err.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(err.RetryAfter))
 	}
+
+	writeJSON(w, problem.Status, errs.ProblemContentType, problem)
+}
+
+func writeJSON(w http.ResponseWriter, code int, contentType string, data interface{}) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		logger.Error("Error while encoding response", zap.Error(err))
+
+		code = http.StatusInternalServerError
+		contentType = errs.ProblemContentType
+		body, _ = json.Marshal(errs.NewUnexpectedError("unexpected error encoding response").AsProblem())
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(code)
+	w.Write(append(body, '\n'))
 }
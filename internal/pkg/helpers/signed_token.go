@@ -0,0 +1,68 @@
+package helpers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignPayload returns a URL-safe token carrying the payload and its expiry,
+// authenticated with an HMAC-SHA256 signature
+func SignPayload(payload string, expiresAt time.Time, key []byte) (string, error) {
+	if len(key) == 0 {
+		return "", fmt.Errorf("signing key is not configured")
+	}
+
+	body := fmt.Sprintf("%d|%s", expiresAt.Unix(), payload)
+	encodedBody := base64.RawURLEncoding.EncodeToString([]byte(body))
+
+	return encodedBody + "." + sign(encodedBody, key), nil
+}
+
+// VerifySignedPayload checks the signature and expiry of a token created by
+// SignPayload and returns the payload it carries
+func VerifySignedPayload(token string, key []byte) (string, error) {
+	if len(key) == 0 {
+		return "", fmt.Errorf("signing key is not configured")
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return "", fmt.Errorf("invalid token format")
+	}
+
+	if !hmac.Equal([]byte(sign(parts[0], key)), []byte(parts[1])) {
+		return "", fmt.Errorf("invalid token signature")
+	}
+
+	body, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", fmt.Errorf("invalid token encoding")
+	}
+
+	expiry, payload, found := strings.Cut(string(body), "|")
+	if !found {
+		return "", fmt.Errorf("invalid token payload")
+	}
+
+	expiresAt, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid token expiry")
+	}
+
+	if time.Now().Unix() > expiresAt {
+		return "", fmt.Errorf("token expired")
+	}
+
+	return payload, nil
+}
+
+func sign(data string, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"hash/crc32"
 	"strings"
-	"time"
 )
 
 // parseToken splits the token into ID and actual token string
@@ -49,17 +48,3 @@ func GenerateToken() (string, error) {
 	// Note: prefix is empty string by default in Laravel
 	return fmt.Sprintf("%s%s%s", "", tokenEntropy, hash), nil
 }
-
-func GetAccessTokenExpiry() time.Time {
-	now := time.Now()
-	atExpiry := now.Add(time.Minute * 60)
-
-	return atExpiry
-}
-
-func GetRefreshTokenExpiry() time.Time {
-	now := time.Now()
-	atExpiry := now.Add(time.Hour * 24 * 7)
-
-	return atExpiry
-}
@@ -7,8 +7,15 @@ import (
 	"github.com/go-ms-project-store/internal/core/enums"
 )
 
+// GetCurrentUri returns the request path stored by the StoreRoutePattern
+// middleware, or the path of r when none was stored. It is the concrete
+// path, not the chi route pattern, so links built from it can be followed.
 func GetCurrentUri(r *http.Request) string {
-	return r.Context().Value(enums.RoutePatternKey).(string)
+	if pattern, ok := r.Context().Value(enums.RoutePatternKey).(string); ok {
+		return pattern
+	}
+
+	return r.URL.Path
 }
 
 func GetBaseURL(r *http.Request) string {
@@ -0,0 +1,21 @@
+package helpers
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-ms-project-store/internal/core/enums"
+)
+
+func TestGetCurrentUri(t *testing.T) {
+	r := httptest.NewRequest("GET", "/api/v1/products?page=2", nil)
+	if got := GetCurrentUri(r); got != "/api/v1/products" {
+		t.Errorf("GetCurrentUri() without a stored path = %q, want the path", got)
+	}
+
+	r = r.WithContext(context.WithValue(r.Context(), enums.RoutePatternKey, "/api/v1/products/"))
+	if got := GetCurrentUri(r); got != "/api/v1/products/" {
+		t.Errorf("GetCurrentUri() = %q, want the stored path", got)
+	}
+}
@@ -3,6 +3,7 @@ package helpers
 import (
 	"fmt"
 
+	"github.com/go-ms-project-store/internal/pkg/errs"
 	"github.com/go-playground/validator/v10"
 )
 
@@ -11,6 +12,10 @@ type ValidationResponse struct {
 	Errors  map[string][]string `json:"errors"`
 }
 
+func (v ValidationResponse) AsAppError() *errs.AppError {
+	return errs.NewValidationErrors(v.Message, v.Errors)
+}
+
 func ValidateRequests(s interface{}) *ValidationResponse {
 	validate := validator.New()
 	err := validate.Struct(s)
@@ -0,0 +1,57 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// ParseKeys reads a key set in the form "kid1:base64key,kid2:base64key".
+// For EdDSA each key is a base64 encoded 32 byte Ed25519 seed. Keeping
+// retired keys in the set lets tokens they signed verify until they expire.
+func ParseKeys(algorithm string, encoded string) (map[string]Key, error) {
+	if algorithm != HS256 && algorithm != EdDSA {
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+
+	keys := make(map[string]Key)
+
+	for _, entry := range strings.Split(encoded, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		kid, value, found := strings.Cut(entry, ":")
+		if !found || kid == "" {
+			return nil, fmt.Errorf("invalid key entry, expected kid:base64key")
+		}
+
+		material, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", kid, err)
+		}
+
+		key := Key{ID: kid, Algorithm: algorithm}
+		if algorithm == EdDSA {
+			if len(material) != ed25519.SeedSize {
+				return nil, fmt.Errorf("invalid key %q: Ed25519 seed must be %d bytes", kid, ed25519.SeedSize)
+			}
+			key.Private = ed25519.NewKeyFromSeed(material)
+		} else {
+			if len(material) < 32 {
+				return nil, fmt.Errorf("invalid key %q: HS256 secret must be at least 32 bytes", kid)
+			}
+			key.Secret = material
+		}
+
+		keys[kid] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no signing keys configured")
+	}
+
+	return keys, nil
+}
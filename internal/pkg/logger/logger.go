@@ -1,6 +1,9 @@
 package logger
 
 import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -24,6 +27,49 @@ func init() {
 	}
 }
 
+type contextKey struct{}
+
+// WithContext stores a logger for the operation running in ctx, such as a
+// request logger tagged with its request ID
+func WithContext(ctx context.Context, l *zap.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// With adds fields to the logger of ctx
+func With(ctx context.Context, fields ...zap.Field) context.Context {
+	return WithContext(ctx, stored(ctx).With(fields...))
+}
+
+// WithOptions applies options to the logger of ctx, e.g. to wrap its core
+func WithOptions(ctx context.Context, opts ...zap.Option) context.Context {
+	return WithContext(ctx, stored(ctx).WithOptions(opts...))
+}
+
+// FromContext returns the logger for the operation running in ctx, tagged
+// with its trace and span IDs so log lines can be matched with traces
+func FromContext(ctx context.Context) *zap.Logger {
+	l := stored(ctx)
+
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return l
+	}
+
+	return l.With(
+		zap.String("trace_id", spanContext.TraceID().String()),
+		zap.String("span_id", spanContext.SpanID().String()),
+	)
+}
+
+func stored(ctx context.Context) *zap.Logger {
+	if l, ok := ctx.Value(contextKey{}).(*zap.Logger); ok {
+		return l
+	}
+
+	// The package functions skip a caller frame this logger doesn't have
+	return log.WithOptions(zap.AddCallerSkip(-1))
+}
+
 func Fatal(message string, fields ...zap.Field) {
 	log.Fatal(message, fields...)
 }
@@ -36,6 +82,15 @@ func Debug(message string, fields ...zap.Field) {
 	log.Debug(message, fields...)
 }
 
+func Warn(message string, fields ...zap.Field) {
+	log.Warn(message, fields...)
+}
+
 func Error(message string, fields ...zap.Field) {
 	log.Error(message, fields...)
 }
+
+// Sync flushes buffered log entries, it should be called before exiting
+func Sync() error {
+	return log.Sync()
+}
@@ -0,0 +1,175 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "store"
+
+// registry holds every collector of the application. A dedicated registry
+// keeps third party packages from adding metrics behind our back.
+var registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route pattern and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method and route pattern.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	queryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Latency of repository operations, including every query they run.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"repository", "operation"})
+
+	ordersCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_created_total",
+		Help:      "Orders placed through checkout.",
+	})
+
+	checkoutFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "checkout_failures_total",
+		Help:      "Failed checkouts by reason.",
+	}, []string{"reason"})
+
+	logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Login attempts by method and result.",
+	}, []string{"method", "result"})
+
+	registrations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "registrations_total",
+		Help:      "New user accounts by method.",
+	}, []string{"method"})
+
+	tokenCleanups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "token_cleanup_runs_total",
+		Help:      "Runs of the expired token cleanup by result.",
+	}, []string{"result"})
+
+	tokenCleanupDeleted = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "token_cleanup_deleted_total",
+		Help:      "Expired tokens deleted by the cleanup.",
+	})
+
+	tokenCleanupDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "token_cleanup_duration_seconds",
+		Help:      "Duration of the expired token cleanup runs.",
+		Buckets:   []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60},
+	})
+)
+
+// Checkout failure reasons
+const (
+	CheckoutInvalid    = "invalid_request"
+	CheckoutNoProducts = "products_unavailable"
+	CheckoutUnexpected = "unexpected_error"
+)
+
+// Login methods and results
+const (
+	LoginPassword = "password"
+	LoginOIDC     = "oidc"
+
+	LoginSucceeded = "success"
+	LoginFailed    = "failure"
+	LoginThrottled = "throttled"
+	LoginLocked    = "locked"
+)
+
+// Token cleanup results
+const (
+	TokenCleanupSucceeded = "success"
+	TokenCleanupFailed    = "failure"
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		queryDuration,
+		ordersCreated,
+		checkoutFailures,
+		logins,
+		registrations,
+		tokenCleanups,
+		tokenCleanupDeleted,
+		tokenCleanupDuration,
+	)
+}
+
+// Handler serves the metrics in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// RegisterDB exposes the connection pool statistics of the database
+func RegisterDB(db *sql.DB, name string) {
+	registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+func ObserveRequest(method string, route string, status int, duration time.Duration) {
+	httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	httpDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// ObserveQuery starts timing a repository operation, call the returned
+// function when it completes:
+//
+//	defer metrics.ObserveQuery("products", "FindAll")()
+func ObserveQuery(repository string, operation string) func() {
+	start := time.Now()
+
+	return func() {
+		queryDuration.WithLabelValues(repository, operation).Observe(time.Since(start).Seconds())
+	}
+}
+
+func OrderCreated() {
+	ordersCreated.Inc()
+}
+
+func CheckoutFailed(reason string) {
+	checkoutFailures.WithLabelValues(reason).Inc()
+}
+
+func Login(method string, result string) {
+	logins.WithLabelValues(method, result).Inc()
+}
+
+func Registered(method string) {
+	registrations.WithLabelValues(method).Inc()
+}
+
+// TokenCleanup records a run of the expired token cleanup, including the
+// tokens a failed run deleted before it stopped
+func TokenCleanup(result string, deleted int64, duration time.Duration) {
+	tokenCleanups.WithLabelValues(result).Inc()
+	tokenCleanupDeleted.Add(float64(deleted))
+	tokenCleanupDuration.Observe(duration.Seconds())
+}
@@ -0,0 +1,23 @@
+CREATE TABLE personal_access_tokens (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    tokenable_type VARCHAR(255) NOT NULL,
+    tokenable_id BIGINT UNSIGNED NOT NULL,
+    -- Login tokens belong to a session, personal tokens don't
+    session_id BIGINT UNSIGNED NULL,
+    -- The refresh token a token was rotated from
+    parent_id BIGINT UNSIGNED NULL,
+    name VARCHAR(255) NOT NULL,
+    token CHAR(64) NOT NULL,
+    abilities TEXT NOT NULL,
+    last_used_at DATETIME NULL,
+    expires_at DATETIME NULL,
+    rotated_at DATETIME NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE KEY personal_access_tokens_token_unique (token),
+    KEY personal_access_tokens_tokenable_index (tokenable_type, tokenable_id),
+    KEY personal_access_tokens_parent_id_index (parent_id),
+    KEY personal_access_tokens_expires_at_index (expires_at),
+    CONSTRAINT personal_access_tokens_session_id_foreign FOREIGN KEY (session_id) REFERENCES auth_sessions (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,15 @@
+CREATE TABLE orders (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    uuid CHAR(36) NOT NULL,
+    external_id VARCHAR(255) NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    -- In cents, the sum of the order items
+    amount INT NOT NULL,
+    user_id BIGINT UNSIGNED NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE KEY orders_uuid_unique (uuid),
+    KEY orders_external_id_index (external_id),
+    CONSTRAINT orders_user_id_foreign FOREIGN KEY (user_id) REFERENCES users (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
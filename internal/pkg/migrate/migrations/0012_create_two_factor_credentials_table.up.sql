@@ -0,0 +1,11 @@
+CREATE TABLE two_factor_credentials (
+    user_id BIGINT UNSIGNED NOT NULL,
+    -- Encrypted with the application key
+    secret VARCHAR(512) NOT NULL,
+    last_used_step BIGINT NULL,
+    confirmed_at DATETIME NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id),
+    CONSTRAINT two_factor_credentials_user_id_foreign FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,336 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-ms-project-store/internal/pkg/logger"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+const (
+	StateApplied = "applied"
+	StatePending = "pending"
+	// StateModified is an applied migration whose script changed since
+	StateModified = "modified"
+	// StateMissing is an applied migration this build doesn't know, e.g.
+	// one added by a newer release
+	StateMissing = "missing"
+)
+
+// lockName guards against two instances migrating at once
+const lockName = "store_migrations"
+
+const lockTimeoutSeconds = 30
+
+type Status struct {
+	Version   int64
+	Name      string
+	State     string
+	AppliedAt *time.Time
+}
+
+type appliedMigration struct {
+	Version   int64     `db:"version"`
+	Name      string    `db:"name"`
+	Checksum  string    `db:"checksum"`
+	AppliedAt time.Time `db:"applied_at"`
+}
+
+// Migrator applies the embedded migrations and records them in the
+// migrations table. MySQL commits DDL implicitly, so a migration that fails
+// halfway must be repaired by hand before it is run again.
+type Migrator struct {
+	db         *sqlx.DB
+	migrations []Migration
+}
+
+func New(db *sqlx.DB) (*Migrator, error) {
+	migrations, err := load(files)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Status lists every migration known to the build or the database
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx, m.db)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []Status
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name, State: StatePending}
+
+		if record, ok := applied[migration.Version]; ok {
+			status.State = StateApplied
+			if record.Checksum != migration.Checksum {
+				status.State = StateModified
+			}
+			status.AppliedAt = &record.AppliedAt
+			delete(applied, migration.Version)
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	for _, record := range applied {
+		statuses = append(statuses, Status{
+			Version:   record.Version,
+			Name:      record.Name,
+			State:     StateMissing,
+			AppliedAt: &record.AppliedAt,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+
+	return statuses, nil
+}
+
+// Pending returns the migrations that have not been applied yet
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx, m.db)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.pending(applied), nil
+}
+
+// Up applies every pending migration in order. It refuses to run while an
+// applied migration has been modified.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+
+	err := m.locked(ctx, func(conn *sqlx.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if record, ok := applied[migration.Version]; ok && record.Checksum != migration.Checksum {
+				return fmt.Errorf("migration %04d_%s was modified after it was applied", migration.Version, migration.Name)
+			}
+		}
+
+		for _, migration := range m.pending(applied) {
+			if err := m.apply(ctx, conn, migration); err != nil {
+				return err
+			}
+			done = append(done, migration)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+// Down rolls back the last steps applied migrations, newest first
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+
+	err := m.locked(ctx, func(conn *sqlx.Conn) error {
+		var err error
+		done, err = m.rollback(ctx, conn, steps)
+		return err
+	})
+
+	return done, err
+}
+
+// Redo rolls back the last steps migrations and applies them again
+func (m *Migrator) Redo(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+
+	err := m.locked(ctx, func(conn *sqlx.Conn) error {
+		rolledBack, err := m.rollback(ctx, conn, steps)
+		if err != nil {
+			return err
+		}
+
+		for i := len(rolledBack) - 1; i >= 0; i-- {
+			if err := m.apply(ctx, conn, rolledBack[i]); err != nil {
+				return err
+			}
+			done = append(done, rolledBack[i])
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+func (m *Migrator) rollback(ctx context.Context, conn *sqlx.Conn, steps int) ([]Migration, error) {
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]Migration, len(m.migrations))
+	for _, migration := range m.migrations {
+		byVersion[migration.Version] = migration
+	}
+
+	records := make([]appliedMigration, 0, len(applied))
+	for _, record := range applied {
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Version > records[j].Version
+	})
+	if len(records) > steps {
+		records = records[:steps]
+	}
+
+	var done []Migration
+	for _, record := range records {
+		migration, ok := byVersion[record.Version]
+		if !ok {
+			return done, fmt.Errorf("migration %04d_%s can't be rolled back by this build", record.Version, record.Name)
+		}
+		if record.Checksum != migration.Checksum {
+			return done, fmt.Errorf("migration %04d_%s was modified after it was applied", migration.Version, migration.Name)
+		}
+
+		if err := m.revert(ctx, conn, migration); err != nil {
+			return done, err
+		}
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+func (m *Migrator) apply(ctx context.Context, conn *sqlx.Conn, migration Migration) error {
+	start := time.Now()
+
+	for _, statement := range statements(migration.Up) {
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("applying migration %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+	}
+
+	_, err := conn.ExecContext(ctx,
+		`INSERT INTO migrations (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)`,
+		migration.Version, migration.Name, migration.Checksum, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("recording migration %04d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	logger.Info("Applied migration",
+		zap.Int64("version", migration.Version),
+		zap.String("name", migration.Name),
+		zap.Duration("duration", time.Since(start)),
+	)
+
+	return nil
+}
+
+func (m *Migrator) revert(ctx context.Context, conn *sqlx.Conn, migration Migration) error {
+	start := time.Now()
+
+	for _, statement := range statements(migration.Down) {
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("rolling back migration %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+	}
+
+	_, err := conn.ExecContext(ctx, `DELETE FROM migrations WHERE version = ?`, migration.Version)
+	if err != nil {
+		return fmt.Errorf("recording rollback of migration %04d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	logger.Info("Rolled back migration",
+		zap.Int64("version", migration.Version),
+		zap.String("name", migration.Name),
+		zap.Duration("duration", time.Since(start)),
+	)
+
+	return nil
+}
+
+func (m *Migrator) pending(applied map[int64]appliedMigration) []Migration {
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+
+	return pending
+}
+
+// applied reads the migrations table. A database without the table has no
+// migrations applied.
+func (m *Migrator) applied(ctx context.Context, q sqlx.QueryerContext) (map[int64]appliedMigration, error) {
+	var records []appliedMigration
+
+	err := sqlx.SelectContext(ctx, q, &records, `SELECT version, name, checksum, applied_at FROM migrations`)
+	if err != nil {
+		if isMissingTable(err) {
+			return map[int64]appliedMigration{}, nil
+		}
+		return nil, fmt.Errorf("reading the migrations table: %w", err)
+	}
+
+	applied := make(map[int64]appliedMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+
+	return applied, nil
+}
+
+// locked runs fn on a single connection holding the migration lock, after
+// making sure the migrations table exists
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sqlx.Conn) error) error {
+	conn, err := m.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var acquired sql.NullInt64
+	if err := conn.QueryRowxContext(ctx, `SELECT GET_LOCK(?, ?)`, lockName, lockTimeoutSeconds).Scan(&acquired); err != nil {
+		return fmt.Errorf("acquiring the migration lock: %w", err)
+	}
+	if acquired.Int64 != 1 {
+		return errors.New("another instance is running migrations")
+	}
+	defer conn.ExecContext(context.Background(), `SELECT RELEASE_LOCK(?)`, lockName)
+
+	_, err = conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS migrations (
+		version BIGINT UNSIGNED NOT NULL,
+		name VARCHAR(255) NOT NULL,
+		checksum CHAR(64) NOT NULL,
+		applied_at DATETIME NOT NULL,
+		PRIMARY KEY (version)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`)
+	if err != nil {
+		return fmt.Errorf("creating the migrations table: %w", err)
+	}
+
+	return fn(conn)
+}
+
+// MySQL reports ER_NO_SUCH_TABLE before the first migration ran
+func isMissingTable(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1146
+}
@@ -0,0 +1,146 @@
+package oidc
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Allowed clock difference between the provider and this server
+const clockSkew = time.Minute
+
+// IDToken holds the verified claims of an ID token
+type IDToken struct {
+	Issuer        string   `json:"iss"`
+	Subject       string   `json:"sub"`
+	Audience      audience `json:"aud"`
+	ExpiresAt     int64    `json:"exp"`
+	IssuedAt      int64    `json:"iat"`
+	Nonce         string   `json:"nonce"`
+	Email         string   `json:"email"`
+	EmailVerified flexBool `json:"email_verified"`
+	Name          string   `json:"name"`
+}
+
+// audience accepts the aud claim as a single string or a list
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+
+	return nil
+}
+
+// flexBool accepts booleans sent as JSON strings, which some providers do
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	switch strings.Trim(string(data), `"`) {
+	case "true":
+		*b = true
+	case "false", "null":
+		*b = false
+	default:
+		return fmt.Errorf("invalid boolean %s", data)
+	}
+
+	return nil
+}
+
+// IsEmailVerified tells whether the provider vouches for the email address
+func (t IDToken) IsEmailVerified() bool {
+	return bool(t.EmailVerified)
+}
+
+// VerifyIDToken checks the token's signature against the provider's JWKS
+// and validates its issuer, audience, expiry and nonce
+func (p *Provider) VerifyIDToken(raw string, nonce string) (*IDToken, error) {
+	if _, err := p.discover(); err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid id token format")
+	}
+
+	var header struct {
+		Algorithm string `json:"alg"`
+		KeyID     string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("invalid id token header")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid id token signature")
+	}
+
+	if err := p.keys.verify(header.KeyID, header.Algorithm, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, fmt.Errorf("invalid id token signature: %w", err)
+	}
+
+	var token IDToken
+	if err := decodeSegment(parts[1], &token); err != nil {
+		return nil, fmt.Errorf("invalid id token claims")
+	}
+
+	if token.Issuer != p.config.Issuer {
+		return nil, fmt.Errorf("unexpected id token issuer %q", token.Issuer)
+	}
+
+	if !token.hasAudience(p.config.ClientID) {
+		return nil, fmt.Errorf("id token was not issued for this client")
+	}
+
+	now := time.Now()
+	if now.Add(-clockSkew).Unix() >= token.ExpiresAt {
+		return nil, fmt.Errorf("id token expired")
+	}
+
+	if token.IssuedAt > now.Add(clockSkew).Unix() {
+		return nil, fmt.Errorf("id token issued in the future")
+	}
+
+	if token.Nonce == "" || token.Nonce != nonce {
+		return nil, fmt.Errorf("id token nonce mismatch")
+	}
+
+	if token.Subject == "" {
+		return nil, fmt.Errorf("id token has no subject")
+	}
+
+	return &token, nil
+}
+
+func (t IDToken) hasAudience(clientID string) bool {
+	for _, aud := range t.Audience {
+		if aud == clientID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
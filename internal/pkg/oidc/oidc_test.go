@@ -0,0 +1,261 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeProvider serves discovery, the token endpoint and the JWKS of an
+// issuer, and signs ID tokens with its own key
+type fakeProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu       sync.Mutex
+	idToken  string
+	exchange url.Values
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := &fakeProvider{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Metadata{
+			Issuer:                f.issuer(),
+			AuthorizationEndpoint: f.issuer() + "/authorize?prompt=login",
+			TokenEndpoint:         f.issuer() + "/token",
+			JWKSURI:               f.issuer() + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []jsonWebKey{{
+				KeyType:   "RSA",
+				KeyID:     "test-key",
+				Use:       "sig",
+				Algorithm: "RS256",
+				N:         base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("code") != "valid-code" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.exchange = r.PostForm
+		json.NewEncoder(w).Encode(TokenResponse{AccessToken: "access", TokenType: "Bearer", IDToken: f.idToken})
+	})
+
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+
+	return f
+}
+
+func (f *fakeProvider) issuer() string {
+	return f.server.URL
+}
+
+func (f *fakeProvider) provider() *Provider {
+	return NewProvider(Config{
+		Name:         "fake",
+		Issuer:       f.issuer(),
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		RedirectURL:  "https://store.example.com/callback",
+	}, f.server.Client())
+}
+
+// claims returns valid claims for the client and nonce, to be adjusted
+func (f *fakeProvider) claims(nonce string) map[string]interface{} {
+	now := time.Now()
+
+	return map[string]interface{}{
+		"iss":            f.issuer(),
+		"sub":            "subject-1",
+		"aud":            "client-id",
+		"exp":            now.Add(time.Hour).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          nonce,
+		"email":          "ada@example.com",
+		"email_verified": "true",
+		"name":           "Ada",
+	}
+}
+
+func (f *fakeProvider) sign(t *testing.T, kid string, claims map[string]interface{}) string {
+	t.Helper()
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, f.key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestAuthCodeURLUsesTheDiscoveredEndpoint(t *testing.T) {
+	fake := newFakeProvider(t)
+
+	authURL, err := fake.provider().AuthCodeURL("the-state", "the-nonce", CodeChallengeS256("verifier"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(authURL, fake.issuer()+"/authorize?") {
+		t.Errorf("AuthCodeURL() = %q, want the discovered endpoint", authURL)
+	}
+
+	query := parsed.Query()
+	want := map[string]string{
+		"prompt":                "login",
+		"response_type":         "code",
+		"client_id":             "client-id",
+		"state":                 "the-state",
+		"nonce":                 "the-nonce",
+		"code_challenge":        CodeChallengeS256("verifier"),
+		"code_challenge_method": "S256",
+		"scope":                 "openid email profile",
+	}
+	for name, value := range want {
+		if got := query.Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+}
+
+func TestDiscoveryRejectsAnotherIssuer(t *testing.T) {
+	fake := newFakeProvider(t)
+	provider := NewProvider(Config{Issuer: fake.issuer() + "/", ClientID: "client-id"}, fake.server.Client())
+
+	if _, err := provider.AuthCodeURL("state", "nonce", "challenge"); err == nil {
+		t.Fatal("a discovery document for another issuer was accepted")
+	}
+}
+
+func TestExchangeAndVerifyIDToken(t *testing.T) {
+	fake := newFakeProvider(t)
+	provider := fake.provider()
+	fake.idToken = fake.sign(t, "test-key", fake.claims("the-nonce"))
+
+	tokens, err := provider.Exchange("valid-code", "the-verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake.mu.Lock()
+	sent := fake.exchange
+	fake.mu.Unlock()
+	if sent.Get("code_verifier") != "the-verifier" || sent.Get("client_secret") != "client-secret" || sent.Get("grant_type") != "authorization_code" {
+		t.Errorf("token request = %v", sent)
+	}
+
+	idToken, err := provider.VerifyIDToken(tokens.IDToken, "the-nonce")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if idToken.Subject != "subject-1" || idToken.Email != "ada@example.com" || !idToken.IsEmailVerified() {
+		t.Errorf("VerifyIDToken() = %+v", idToken)
+	}
+}
+
+func TestExchangeRejectsAnInvalidCode(t *testing.T) {
+	fake := newFakeProvider(t)
+
+	if _, err := fake.provider().Exchange("stolen-code", "verifier"); err == nil {
+		t.Fatal("a rejected code was exchanged")
+	}
+}
+
+func TestVerifyIDTokenRejectsInvalidTokens(t *testing.T) {
+	fake := newFakeProvider(t)
+	provider := fake.provider()
+
+	tests := []struct {
+		name   string
+		kid    string
+		adjust func(map[string]interface{})
+	}{
+		{"other issuer", "test-key", func(c map[string]interface{}) { c["iss"] = "https://evil.example.com" }},
+		{"other audience", "test-key", func(c map[string]interface{}) { c["aud"] = []string{"another-client"} }},
+		{"expired", "test-key", func(c map[string]interface{}) { c["exp"] = time.Now().Add(-2 * clockSkew).Unix() }},
+		{"issued in the future", "test-key", func(c map[string]interface{}) { c["iat"] = time.Now().Add(2 * clockSkew).Unix() }},
+		{"nonce mismatch", "test-key", func(c map[string]interface{}) { c["nonce"] = "replayed-nonce" }},
+		{"no subject", "test-key", func(c map[string]interface{}) { delete(c, "sub") }},
+		{"unknown key", "other-key", func(c map[string]interface{}) {}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := fake.claims("the-nonce")
+			tt.adjust(claims)
+
+			if _, err := provider.VerifyIDToken(fake.sign(t, tt.kid, claims), "the-nonce"); err == nil {
+				t.Fatal("the token was accepted")
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenRejectsATamperedToken(t *testing.T) {
+	fake := newFakeProvider(t)
+	provider := fake.provider()
+
+	parts := strings.Split(fake.sign(t, "test-key", fake.claims("the-nonce")), ".")
+	claims := fake.claims("the-nonce")
+	claims["email"] = "admin@example.com"
+	payload, _ := json.Marshal(claims)
+	parts[1] = base64.RawURLEncoding.EncodeToString(payload)
+
+	if _, err := provider.VerifyIDToken(strings.Join(parts, "."), "the-nonce"); err == nil {
+		t.Fatal("a token with altered claims was accepted")
+	}
+}
+
+func TestVerifyIDTokenAcceptsAListAudience(t *testing.T) {
+	fake := newFakeProvider(t)
+	claims := fake.claims("the-nonce")
+	claims["aud"] = []string{"another-client", "client-id"}
+	claims["email_verified"] = false
+
+	idToken, err := fake.provider().VerifyIDToken(fake.sign(t, "test-key", claims), "the-nonce")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if idToken.IsEmailVerified() {
+		t.Error("email_verified false was read as verified")
+	}
+}
@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps counters in process memory, so each instance enforces
+// its own limits
+type MemoryStore struct {
+	mu        sync.Mutex
+	counters  map[string]*counter
+	lastSweep time.Time
+}
+
+type counter struct {
+	start    time.Time
+	window   time.Duration
+	current  int
+	previous int
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		counters:  make(map[string]*counter),
+		lastSweep: time.Now(),
+	}
+}
+
+func (s *MemoryStore) Allow(key string, limit int, window time.Duration) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	start := now.Truncate(window)
+
+	c, ok := s.counters[key]
+	if !ok {
+		c = &counter{start: start, window: window}
+		s.counters[key] = c
+	}
+
+	if !c.start.Equal(start) {
+		if start.Sub(c.start) == window {
+			c.previous = c.current
+		} else {
+			c.previous = 0
+		}
+		c.current = 0
+		c.start = start
+	}
+
+	result := slidingWindow(c.previous, c.current, limit, window, now.Sub(start))
+	if result.Allowed {
+		c.current++
+	}
+
+	return result, nil
+}
+
+// sweep drops counters that no longer affect any limit, at most once a minute
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, c := range s.counters {
+		if now.Sub(c.start) >= 2*c.window {
+			delete(s.counters, key)
+		}
+	}
+}
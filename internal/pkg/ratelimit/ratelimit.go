@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Result describes the state of a key's limit after a request was counted
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the limit has room for another request
+	Reset time.Duration
+}
+
+// Store counts requests per key using a sliding window. Implementations must
+// be safe for concurrent use.
+type Store interface {
+	Allow(key string, limit int, window time.Duration) (Result, error)
+}
+
+// ParseRate reads a rate in the form "limit/window", e.g. "60/1m"
+func ParseRate(rate string) (int, time.Duration, error) {
+	limitPart, windowPart, found := strings.Cut(strings.TrimSpace(rate), "/")
+	if !found {
+		return 0, 0, fmt.Errorf("invalid rate %q, expected limit/window", rate)
+	}
+
+	limit, err := strconv.Atoi(limitPart)
+	if err != nil || limit <= 0 {
+		return 0, 0, fmt.Errorf("invalid rate %q: limit must be a positive number", rate)
+	}
+
+	window, err := time.ParseDuration(windowPart)
+	if err != nil || window <= 0 {
+		return 0, 0, fmt.Errorf("invalid rate %q: window must be a positive duration", rate)
+	}
+
+	return limit, window, nil
+}
+
+// slidingWindow estimates the requests in the last window from the counts
+// of the current and previous fixed windows, weighting the previous one by
+// how much of it still overlaps. It returns the result of counting one more.
+func slidingWindow(previous int, current int, limit int, window time.Duration, elapsed time.Duration) Result {
+	weight := 1 - float64(elapsed)/float64(window)
+	estimated := float64(previous)*weight + float64(current)
+
+	result := Result{
+		Allowed: estimated+1 <= float64(limit),
+		Limit:   limit,
+	}
+
+	if result.Allowed {
+		estimated++
+	}
+
+	result.Remaining = limit - int(estimated+0.999999)
+	if result.Remaining < 0 {
+		result.Remaining = 0
+	}
+
+	result.Reset = window - elapsed
+	if !result.Allowed && previous > 0 {
+		// Room frees up as the previous window slides out
+		excess := estimated + 1 - float64(limit)
+		if wait := time.Duration(excess / float64(previous) * float64(window)); wait < result.Reset {
+			result.Reset = wait
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,103 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		rate   string
+		limit  int
+		window time.Duration
+	}{
+		{"60/1m", 60, time.Minute},
+		{" 10/30s ", 10, 30 * time.Second},
+		{"1/1h", 1, time.Hour},
+	}
+
+	for _, tt := range tests {
+		limit, window, err := ParseRate(tt.rate)
+		if err != nil {
+			t.Errorf("ParseRate(%q) returned %v", tt.rate, err)
+			continue
+		}
+		if limit != tt.limit || window != tt.window {
+			t.Errorf("ParseRate(%q) = %d/%s, want %d/%s", tt.rate, limit, window, tt.limit, tt.window)
+		}
+	}
+}
+
+func TestParseRateRejectsInvalidRates(t *testing.T) {
+	for _, rate := range []string{"", "60", "60/", "/1m", "0/1m", "-1/1m", "x/1m", "60/0s", "60/-1m", "60/minute"} {
+		if _, _, err := ParseRate(rate); err == nil {
+			t.Errorf("ParseRate(%q) accepted an invalid rate", rate)
+		}
+	}
+}
+
+func TestSlidingWindowAllowsUpToTheLimit(t *testing.T) {
+	result := slidingWindow(0, 0, 3, time.Minute, 0)
+	if !result.Allowed || result.Remaining != 2 || result.Limit != 3 {
+		t.Fatalf("first request: %+v", result)
+	}
+
+	result = slidingWindow(0, 2, 3, time.Minute, 10*time.Second)
+	if !result.Allowed || result.Remaining != 0 {
+		t.Fatalf("last allowed request: %+v", result)
+	}
+
+	result = slidingWindow(0, 3, 3, time.Minute, 10*time.Second)
+	if result.Allowed || result.Remaining != 0 {
+		t.Fatalf("request over the limit: %+v", result)
+	}
+	if result.Reset != 50*time.Second {
+		t.Errorf("Reset = %s, want the rest of the window", result.Reset)
+	}
+}
+
+func TestSlidingWindowWeighsThePreviousWindow(t *testing.T) {
+	// A quarter into the window, three quarters of the previous one count
+	result := slidingWindow(8, 0, 10, time.Minute, 15*time.Second)
+	if !result.Allowed || result.Remaining != 3 {
+		t.Fatalf("with 6 weighted requests: %+v", result)
+	}
+
+	result = slidingWindow(8, 4, 10, time.Minute, 15*time.Second)
+	if result.Allowed {
+		t.Fatalf("with 10 weighted requests: %+v", result)
+	}
+
+	// 11 estimated against a limit of 10: one previous request must slide
+	// out, which takes 1/8 of the window
+	if want := time.Minute / 8; result.Reset != want {
+		t.Errorf("Reset = %s, want %s", result.Reset, want)
+	}
+}
+
+func TestSlidingWindowForgetsThePreviousWindowAtItsEnd(t *testing.T) {
+	result := slidingWindow(10, 0, 10, time.Minute, time.Minute-time.Nanosecond)
+	if !result.Allowed {
+		t.Fatalf("previous window has slid out: %+v", result)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+
+	for i := 0; i < 3; i++ {
+		result, err := store.Allow("key", 3, time.Hour)
+		if err != nil || !result.Allowed {
+			t.Fatalf("request %d: %+v, %v", i+1, result, err)
+		}
+	}
+
+	result, _ := store.Allow("key", 3, time.Hour)
+	if result.Allowed {
+		t.Fatalf("fourth request was allowed: %+v", result)
+	}
+
+	if result, _ := store.Allow("other", 3, time.Hour); !result.Allowed {
+		t.Fatalf("keys share a counter: %+v", result)
+	}
+}
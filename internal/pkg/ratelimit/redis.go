@@ -0,0 +1,295 @@
+package ratelimit
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// allowScript counts a request in the current window and takes it back out
+// when the sliding window estimate is over the limit, so the whole check is
+// one atomic round trip. It returns the current and previous counts.
+//
+// KEYS: current window, previous window
+// ARGV: limit, elapsed ms, window ms, expiry ms
+const allowScript = `
+local current = redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+local previous = tonumber(redis.call('GET', KEYS[2]) or '0')
+local estimated = previous * (1 - tonumber(ARGV[2]) / tonumber(ARGV[3])) + (current - 1)
+if estimated + 1 > tonumber(ARGV[1]) then
+	redis.call('DECR', KEYS[1])
+end
+return {current, previous}
+`
+
+// maxIdleConns is how many connections the store keeps open between requests
+const maxIdleConns = 8
+
+// RedisStore keeps counters in Redis, or any server speaking its protocol,
+// so limits are shared between instances. It talks RESP over a small pool
+// of connections, dropping any connection that fails.
+type RedisStore struct {
+	addr     string
+	password string
+	db       int
+	prefix   string
+	timeout  time.Duration
+
+	mu   sync.Mutex
+	idle []*redisConn
+}
+
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func NewRedisStore(addr string, password string, db int) *RedisStore {
+	return &RedisStore{
+		addr:     addr,
+		password: password,
+		db:       db,
+		prefix:   "ratelimit:",
+		timeout:  time.Second,
+	}
+}
+
+// Allow runs allowScript against the counters of the current and previous
+// windows. Denied requests are taken back out so they don't extend the
+// limit.
+func (s *RedisStore) Allow(key string, limit int, window time.Duration) (Result, error) {
+	now := time.Now()
+	start := now.Truncate(window)
+	// Whole milliseconds, so the script and slidingWindow reach the same
+	// decision
+	elapsed := now.Sub(start).Truncate(time.Millisecond)
+	window = window.Truncate(time.Millisecond)
+
+	currentKey := s.prefix + key + ":" + strconv.FormatInt(start.UnixMilli(), 10)
+	previousKey := s.prefix + key + ":" + strconv.FormatInt(start.Add(-window).UnixMilli(), 10)
+
+	reply, err := s.do([]string{
+		"EVAL", allowScript, "2", currentKey, previousKey,
+		strconv.Itoa(limit),
+		strconv.FormatInt(elapsed.Milliseconds(), 10),
+		strconv.FormatInt(window.Milliseconds(), 10),
+		strconv.FormatInt((2 * window).Milliseconds(), 10),
+	})
+	if err != nil {
+		return Result{}, err
+	}
+
+	counts, ok := reply.([]interface{})
+	if !ok || len(counts) != 2 {
+		return Result{}, fmt.Errorf("unexpected EVAL reply %v", reply)
+	}
+	current, ok := counts[0].(int64)
+	if !ok {
+		return Result{}, fmt.Errorf("unexpected EVAL reply %v", reply)
+	}
+	previous, ok := counts[1].(int64)
+	if !ok {
+		return Result{}, fmt.Errorf("unexpected EVAL reply %v", reply)
+	}
+
+	return slidingWindow(int(previous), int(current-1), limit, window, elapsed), nil
+}
+
+// Close closes the idle connections. Connections in use are closed when
+// they are returned.
+func (s *RedisStore) Close() error {
+	s.mu.Lock()
+	idle := s.idle
+	s.idle = nil
+	s.mu.Unlock()
+
+	var err error
+	for _, c := range idle {
+		if closeErr := c.conn.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+
+	return err
+}
+
+// do sends one command on a pooled connection and returns its reply
+func (s *RedisStore) do(command []string) (interface{}, error) {
+	c, err := s.get()
+	if err != nil {
+		return nil, err
+	}
+
+	replies, err := c.roundTrip(s.timeout, [][]string{command})
+	if err != nil {
+		c.conn.Close()
+		return nil, err
+	}
+	s.put(c)
+
+	if replyErr, ok := replies[0].(error); ok {
+		return nil, replyErr
+	}
+
+	return replies[0], nil
+}
+
+// get takes an idle connection or dials a new one
+func (s *RedisStore) get() (*redisConn, error) {
+	s.mu.Lock()
+	if n := len(s.idle); n > 0 {
+		c := s.idle[n-1]
+		s.idle = s.idle[:n-1]
+		s.mu.Unlock()
+		return c, nil
+	}
+	s.mu.Unlock()
+
+	return s.dial()
+}
+
+// put returns a connection to the pool, closing it when the pool is full
+func (s *RedisStore) put(c *redisConn) {
+	s.mu.Lock()
+	if len(s.idle) < maxIdleConns {
+		s.idle = append(s.idle, c)
+		s.mu.Unlock()
+		return
+	}
+	s.mu.Unlock()
+
+	c.conn.Close()
+}
+
+func (s *RedisStore) dial() (*redisConn, error) {
+	conn, err := net.DialTimeout("tcp", s.addr, s.timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &redisConn{conn: conn, reader: bufio.NewReader(conn)}
+
+	var setup [][]string
+	if s.password != "" {
+		setup = append(setup, []string{"AUTH", s.password})
+	}
+	if s.db != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(s.db)})
+	}
+
+	if len(setup) == 0 {
+		return c, nil
+	}
+
+	replies, err := c.roundTrip(s.timeout, setup)
+	if err == nil {
+		for _, reply := range replies {
+			if replyErr, ok := reply.(error); ok {
+				err = replyErr
+				break
+			}
+		}
+	}
+
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// roundTrip sends the commands as a pipeline and returns their replies in
+// order
+func (c *redisConn) roundTrip(timeout time.Duration, commands [][]string) ([]interface{}, error) {
+	if err := c.conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+
+	var buf []byte
+	for _, command := range commands {
+		buf = append(buf, '*')
+		buf = strconv.AppendInt(buf, int64(len(command)), 10)
+		buf = append(buf, '\r', '\n')
+		for _, arg := range command {
+			buf = append(buf, '$')
+			buf = strconv.AppendInt(buf, int64(len(arg)), 10)
+			buf = append(buf, '\r', '\n')
+			buf = append(buf, arg...)
+			buf = append(buf, '\r', '\n')
+		}
+	}
+
+	if _, err := c.conn.Write(buf); err != nil {
+		return nil, err
+	}
+
+	replies := make([]interface{}, len(commands))
+	for i := range commands {
+		reply, err := readReply(c.reader)
+		if err != nil {
+			return nil, err
+		}
+		replies[i] = reply
+	}
+
+	return replies, nil
+}
+
+// readReply parses one RESP reply. Error replies are returned as values so
+// the rest of a pipeline can still be read.
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("malformed reply %q", line)
+	}
+	payload := line[1 : len(line)-2]
+
+	switch line[0] {
+	case '+':
+		return payload, nil
+	case '-':
+		return fmt.Errorf("redis: %s", payload), nil
+	case ':':
+		return strconv.ParseInt(payload, 10, 64)
+	case '$':
+		size, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		return string(data[:size]), nil
+	case '*':
+		size, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, size)
+		for i := range items {
+			if items[i], err = readReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("unknown reply type %q", line[0])
+	}
+}
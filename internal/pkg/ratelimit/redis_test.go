@@ -0,0 +1,322 @@
+package ratelimit
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis is a stand-in speaking enough RESP for RedisStore
+type fakeRedis struct {
+	listener net.Listener
+	password string
+
+	mu       sync.Mutex
+	values   map[string]int64
+	expiries map[string]time.Duration
+	commands []string
+	conns    int
+}
+
+func newFakeRedis(t *testing.T, password string) *fakeRedis {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := &fakeRedis{
+		listener: listener,
+		password: password,
+		values:   make(map[string]int64),
+		expiries: make(map[string]time.Duration),
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go f.serve()
+
+	return f
+}
+
+func (f *fakeRedis) addr() string {
+	return f.listener.Addr().String()
+}
+
+func (f *fakeRedis) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		f.mu.Lock()
+		f.conns++
+		f.mu.Unlock()
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeRedis) handle(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	authenticated := f.password == ""
+
+	for {
+		request, err := readReply(reader)
+		if err != nil {
+			return
+		}
+
+		items, _ := request.([]interface{})
+		args := make([]string, len(items))
+		for i, item := range items {
+			args[i], _ = item.(string)
+		}
+		if len(args) == 0 {
+			return
+		}
+
+		f.mu.Lock()
+		// Scripts are recorded by name only
+		if strings.ToUpper(args[0]) == "EVAL" {
+			f.commands = append(f.commands, "EVAL")
+		} else {
+			f.commands = append(f.commands, strings.Join(args, " "))
+		}
+
+		var reply string
+		switch name := strings.ToUpper(args[0]); {
+		case name == "AUTH":
+			authenticated = args[1] == f.password
+			reply = "+OK\r\n"
+			if !authenticated {
+				reply = "-WRONGPASS invalid password\r\n"
+			}
+		case !authenticated:
+			reply = "-NOAUTH Authentication required\r\n"
+		case name == "SELECT":
+			reply = "+OK\r\n"
+		case name == "EVAL" && args[1] == allowScript:
+			reply = f.allow(args[3], args[4], args[5:])
+		case name == "INCR":
+			f.values[args[1]]++
+			reply = fmt.Sprintf(":%d\r\n", f.values[args[1]])
+		case name == "DECR":
+			f.values[args[1]]--
+			reply = fmt.Sprintf(":%d\r\n", f.values[args[1]])
+		case name == "PEXPIRE":
+			ms, _ := strconv.ParseInt(args[2], 10, 64)
+			f.expiries[args[1]] = time.Duration(ms) * time.Millisecond
+			reply = ":1\r\n"
+		case name == "GET":
+			value, ok := f.values[args[1]]
+			reply = "$-1\r\n"
+			if ok {
+				s := strconv.FormatInt(value, 10)
+				reply = fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+			}
+		default:
+			reply = "-ERR unknown command\r\n"
+		}
+		f.mu.Unlock()
+
+		if _, err := conn.Write([]byte(reply)); err != nil {
+			return
+		}
+	}
+}
+
+// allow does what allowScript does, since the stand-in can't run Lua
+func (f *fakeRedis) allow(currentKey string, previousKey string, argv []string) string {
+	limit, _ := strconv.ParseFloat(argv[0], 64)
+	elapsed, _ := strconv.ParseFloat(argv[1], 64)
+	window, _ := strconv.ParseFloat(argv[2], 64)
+	ttl, _ := strconv.ParseInt(argv[3], 10, 64)
+
+	f.values[currentKey]++
+	current := f.values[currentKey]
+	f.expiries[currentKey] = time.Duration(ttl) * time.Millisecond
+	previous := f.values[previousKey]
+
+	if float64(previous)*(1-elapsed/window)+float64(current-1)+1 > limit {
+		f.values[currentKey]--
+	}
+
+	return fmt.Sprintf("*2\r\n:%d\r\n:%d\r\n", current, previous)
+}
+
+func (f *fakeRedis) value(key string) (int64, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	value, ok := f.values[key]
+	return value, ok
+}
+
+func TestRedisStoreCountsUpToTheLimit(t *testing.T) {
+	server := newFakeRedis(t, "")
+	store := NewRedisStore(server.addr(), "", 0)
+	defer store.Close()
+
+	for i := 0; i < 3; i++ {
+		result, err := store.Allow("ip:1.2.3.4", 3, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Allowed || result.Remaining != 2-i {
+			t.Fatalf("request %d: %+v", i+1, result)
+		}
+	}
+
+	result, err := store.Allow("ip:1.2.3.4", 3, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Allowed {
+		t.Fatalf("fourth request was allowed: %+v", result)
+	}
+
+	// The denied request was taken back out
+	key := "ratelimit:ip:1.2.3.4:" + strconv.FormatInt(time.Now().Truncate(time.Hour).UnixMilli(), 10)
+	if value, _ := server.value(key); value != 3 {
+		t.Errorf("counter = %d, want 3", value)
+	}
+	server.mu.Lock()
+	ttl := server.expiries[key]
+	server.mu.Unlock()
+	if ttl != 2*time.Hour {
+		t.Errorf("expiry = %s, want two windows", ttl)
+	}
+}
+
+func TestRedisStoreReadsThePreviousWindow(t *testing.T) {
+	server := newFakeRedis(t, "")
+	store := NewRedisStore(server.addr(), "", 0)
+	defer store.Close()
+
+	window := time.Hour
+	previous := time.Now().Truncate(window).Add(-window)
+	server.values["ratelimit:key:"+strconv.FormatInt(previous.UnixMilli(), 10)] = 1000
+
+	result, err := store.Allow("key", 10, window)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Allowed {
+		t.Fatalf("the previous window wasn't counted: %+v", result)
+	}
+}
+
+func TestRedisStoreAuthenticatesAndSelectsTheDatabase(t *testing.T) {
+	server := newFakeRedis(t, "secret")
+	store := NewRedisStore(server.addr(), "secret", 2)
+	defer store.Close()
+
+	if _, err := store.Allow("key", 1, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if len(server.commands) < 2 || server.commands[0] != "AUTH secret" || server.commands[1] != "SELECT 2" {
+		t.Errorf("commands = %q, want AUTH then SELECT first", server.commands)
+	}
+}
+
+func TestRedisStoreReportsErrorReplies(t *testing.T) {
+	server := newFakeRedis(t, "secret")
+	store := NewRedisStore(server.addr(), "wrong", 0)
+	defer store.Close()
+
+	if _, err := store.Allow("key", 1, time.Minute); err == nil || !strings.Contains(err.Error(), "WRONGPASS") {
+		t.Fatalf("err = %v, want the server's error", err)
+	}
+}
+
+func TestRedisStoreReconnectsAfterAFailure(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	store := NewRedisStore(addr, "", 0)
+	defer store.Close()
+
+	if _, err := store.Allow("key", 1, time.Minute); err == nil {
+		t.Fatal("expected an error without a server")
+	}
+
+	listener, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("address was taken in the meantime: %v", err)
+	}
+	server := &fakeRedis{listener: listener, values: map[string]int64{}, expiries: map[string]time.Duration{}}
+	t.Cleanup(func() { listener.Close() })
+	go server.serve()
+
+	if _, err := store.Allow("key", 1, time.Minute); err != nil {
+		t.Fatalf("store didn't reconnect: %v", err)
+	}
+}
+
+func TestRedisStoreChecksInOneCommandOnAPooledConnection(t *testing.T) {
+	server := newFakeRedis(t, "")
+	store := NewRedisStore(server.addr(), "", 0)
+	defer store.Close()
+
+	for i := 0; i < 5; i++ {
+		if _, err := store.Allow("key", 3, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if len(server.commands) != 5 {
+		t.Errorf("commands = %q, want one EVAL per request", server.commands)
+	}
+	if server.conns != 1 {
+		t.Errorf("connections = %d, want the first one reused", server.conns)
+	}
+}
+
+func TestRedisStoreIsSafeForConcurrentUse(t *testing.T) {
+	server := newFakeRedis(t, "")
+	store := NewRedisStore(server.addr(), "", 0)
+	defer store.Close()
+
+	var wg sync.WaitGroup
+	allowed := make(chan bool, 50)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := store.Allow("key", 20, time.Hour)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			allowed <- result.Allowed
+		}()
+	}
+	wg.Wait()
+	close(allowed)
+
+	count := 0
+	for ok := range allowed {
+		if ok {
+			count++
+		}
+	}
+	if count != 20 {
+		t.Errorf("allowed %d requests, want 20", count)
+	}
+}
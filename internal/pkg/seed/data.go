@@ -0,0 +1,48 @@
+package seed
+
+var categoryNames = []string{
+	"Electronics", "Books", "Home & Kitchen", "Garden", "Toys", "Sports",
+	"Clothing", "Shoes", "Beauty", "Health", "Automotive", "Office",
+	"Music", "Movies", "Games", "Pet Supplies", "Baby", "Jewelry",
+	"Tools", "Grocery",
+}
+
+var productAdjectives = []string{
+	"Classic", "Compact", "Deluxe", "Eco", "Ergonomic", "Essential", "Handmade",
+	"Lightweight", "Modern", "Portable", "Premium", "Rugged", "Sleek", "Smart",
+	"Vintage", "Wireless",
+}
+
+var productMaterials = []string{
+	"Bamboo", "Ceramic", "Cotton", "Glass", "Leather", "Linen", "Oak",
+	"Steel", "Wool", "Aluminium", "Copper", "Rubber",
+}
+
+var productNouns = []string{
+	"Backpack", "Blender", "Bottle", "Chair", "Clock", "Desk Lamp", "Headphones",
+	"Jacket", "Kettle", "Keyboard", "Mug", "Notebook", "Pan", "Planter",
+	"Speaker", "Sneakers", "Table", "Tent", "Toolkit", "Watch",
+}
+
+var descriptionSentences = []string{
+	"Built to last through years of daily use.",
+	"A customer favourite since its first release.",
+	"Designed with comfort and simplicity in mind.",
+	"Ships in recyclable packaging.",
+	"Easy to clean and simple to maintain.",
+	"Backed by a two year warranty.",
+	"Fits in with any style of home.",
+	"Tested by our team before it goes on sale.",
+}
+
+var firstNames = []string{
+	"Ada", "Alan", "Amara", "Bruno", "Chen", "Diego", "Elena", "Farah", "Grace",
+	"Hiro", "Ines", "Jonas", "Kemi", "Lena", "Marco", "Nadia", "Omar", "Priya",
+	"Rosa", "Sami", "Tomas", "Uma", "Viktor", "Yara",
+}
+
+var lastNames = []string{
+	"Almeida", "Berg", "Costa", "Diaz", "Evans", "Fischer", "Garcia", "Haddad",
+	"Ito", "Jensen", "Kowalski", "Lopez", "Moreau", "Nakamura", "Okafor",
+	"Petrov", "Rossi", "Silva", "Tanaka", "Weber",
+}
@@ -0,0 +1,393 @@
+package seed
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-ms-project-store/internal/adapters/input/http/dto"
+	"github.com/go-ms-project-store/internal/core/domain"
+	"github.com/go-ms-project-store/internal/core/enums"
+	"github.com/go-ms-project-store/internal/core/ports"
+	"github.com/go-ms-project-store/internal/core/repositories"
+	"github.com/go-ms-project-store/internal/pkg/errs"
+	"github.com/go-ms-project-store/internal/pkg/logger"
+	"github.com/go-ms-project-store/internal/pkg/pagination"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Password of every seeded user
+const Password = "password"
+
+// Profile sets how much data is seeded
+type Profile struct {
+	Admins     int
+	Customers  int
+	Categories int
+	Products   int
+	Orders     int
+	// Orders are spread over this many days before today
+	HistoryDays int
+}
+
+var Profiles = map[string]Profile{
+	// Just enough to sign in as an admin and as a customer
+	"minimal": {Admins: 1, Customers: 1},
+	"demo": {
+		Admins:      2,
+		Customers:   25,
+		Categories:  8,
+		Products:    250,
+		Orders:      400,
+		HistoryDays: 365,
+	},
+	"load-test": {
+		Admins:      2,
+		Customers:   200,
+		Categories:  len(categoryNames),
+		Products:    5000,
+		Orders:      20000,
+		HistoryDays: 730,
+	},
+}
+
+type Summary struct {
+	Admins     []string
+	Customers  int
+	Categories int
+	Products   int
+	Orders     int
+}
+
+// Seeder creates development data through the domain constructors and the
+// repositories, so it follows the same rules as the API. The same seed
+// always produces the same data.
+type Seeder struct {
+	authRepo       ports.AuthRepository
+	userRepo       ports.UserRepository
+	roleRepo       ports.RoleRepository
+	permissionRepo ports.PermissionRepository
+	categoryRepo   ports.CategoryRepository
+	productRepo    ports.ProductRepository
+	orderRepo      ports.OrderRepository
+
+	source *rand.ChaCha8
+	rng    *rand.Rand
+	today  time.Time
+}
+
+func New(dbClient *sqlx.DB, seed uint64) *Seeder {
+	var key [32]byte
+	binary.LittleEndian.PutUint64(key[:], seed)
+	source := rand.NewChaCha8(key)
+
+	return &Seeder{
+		authRepo:       repositories.NewAuthRepositoryDB(dbClient),
+		userRepo:       repositories.NewUserRepositoryDB(dbClient),
+		roleRepo:       repositories.NewRoleRepositoryDB(dbClient),
+		permissionRepo: repositories.NewPermissionRepositoryDB(dbClient),
+		categoryRepo:   repositories.NewCategoryRepositoryDB(dbClient),
+		productRepo:    repositories.NewProductRepositoryDB(dbClient),
+		orderRepo:      repositories.NewOrderRepositoryDB(dbClient),
+		source:         source,
+		rng:            rand.New(source),
+		today:          time.Now().Truncate(24 * time.Hour),
+	}
+}
+
+// Run seeds the profile. Roles, permissions and users that already exist are
+// kept, and the catalog and orders are only seeded into an empty catalog, so
+// running it twice is safe.
+func (s *Seeder) Run(ctx context.Context, profile Profile) (*Summary, error) {
+	summary := &Summary{}
+
+	adminRole, err := s.seedRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 1; i <= profile.Admins; i++ {
+		email := "admin@example.com"
+		if i > 1 {
+			email = fmt.Sprintf("admin%d@example.com", i)
+		}
+
+		admin := domain.NewUser(dto.NewUserRequest{
+			Name:     fmt.Sprintf("Admin %d", i),
+			Email:    email,
+			Password: Password,
+			RoleId:   adminRole.Id,
+		})
+		admin.UUID = s.uuid()
+		admin.CreatedAt = s.today
+		admin.UpdatedAt = s.today
+
+		if _, err := s.seedUser(ctx, admin, s.userRepo.Create); err != nil {
+			return nil, err
+		}
+		summary.Admins = append(summary.Admins, email)
+	}
+
+	customers := make([]*domain.User, 0, profile.Customers)
+	for i := 1; i <= profile.Customers; i++ {
+		first := pick(s.rng, firstNames)
+		last := pick(s.rng, lastNames)
+		email := fmt.Sprintf("%s.%s%d@example.com", strings.ToLower(first), strings.ToLower(last), i)
+		// Customers sign up before their first order
+		createdAt := s.daysAgo(profile.HistoryDays + s.rng.IntN(30))
+
+		register := domain.NewUserRegister(dto.NewUserRegisterRequest{
+			Name:     first + " " + last,
+			Email:    email,
+			Password: Password,
+		})
+		register.UUID = s.uuid()
+		register.CreatedAt = createdAt
+		register.UpdatedAt = createdAt
+
+		// Customers sign up like they would through the API, which gives
+		// them the customer role
+		customer, err := s.seedUser(ctx, register, s.authRepo.Register)
+		if err != nil {
+			return nil, err
+		}
+		customers = append(customers, customer)
+	}
+	summary.Customers = len(customers)
+	logger.Info("Seeded users", zap.Int("admins", len(summary.Admins)), zap.Int("customers", len(customers)))
+
+	_, total, appErr := s.productRepo.FindAll(ctx, pagination.DataDBFilter{OrderBy: "id", OrderDir: "asc", Page: 1, PerPage: 1})
+	if appErr != nil {
+		return nil, asError("counting products", appErr)
+	}
+	if total > 0 {
+		logger.Info("Skipped the catalog and orders, the catalog isn't empty", zap.Int64("products", total))
+		return summary, nil
+	}
+
+	products, err := s.seedCatalog(ctx, profile, summary)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(customers) > 0 && len(products) > 0 {
+		for i := 0; i < profile.Orders; i++ {
+			if err := s.seedOrder(ctx, profile, pick(s.rng, customers), products); err != nil {
+				return nil, err
+			}
+			summary.Orders++
+		}
+		logger.Info("Seeded orders", zap.Int("orders", summary.Orders))
+	}
+
+	return summary, nil
+}
+
+// seedRoles creates the roles the application depends on, and the
+// permissions, which are all granted to admins
+func (s *Seeder) seedRoles(ctx context.Context) (*domain.Role, error) {
+	adminRole, err := s.seedRole(ctx, string(enums.AdminRole))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.seedRole(ctx, string(enums.CustomerRole)); err != nil {
+		return nil, err
+	}
+
+	existing, _, appErr := s.permissionRepo.FindAll(ctx, pagination.DataDBFilter{OrderBy: "id", OrderDir: "asc", Page: 1, PerPage: 100})
+	if appErr != nil {
+		return nil, asError("listing permissions", appErr)
+	}
+
+	var ids []int64
+	for _, name := range []enums.Permission{enums.ManageCatalogPermission, enums.ManageRolesPermission, enums.ManageUsersPermission} {
+		var permission *domain.Permission
+		for i := range existing {
+			if existing[i].Name == string(name) {
+				permission = &existing[i]
+				break
+			}
+		}
+
+		if permission == nil {
+			permission, appErr = s.permissionRepo.Create(ctx, domain.NewPermission(dto.NewPermissionRequest{Name: string(name)}))
+			if appErr != nil {
+				return nil, asError("creating permission "+string(name), appErr)
+			}
+		}
+		ids = append(ids, permission.Id)
+	}
+
+	if appErr := s.roleRepo.AttachPermissions(ctx, adminRole.Id, ids); appErr != nil {
+		return nil, asError("granting permissions to admins", appErr)
+	}
+
+	return adminRole, nil
+}
+
+func (s *Seeder) seedRole(ctx context.Context, name string) (*domain.Role, error) {
+	role, appErr := s.roleRepo.FindByName(ctx, name)
+	if appErr == nil {
+		return role, nil
+	}
+	if appErr.Code != http.StatusNotFound {
+		return nil, asError("finding role "+name, appErr)
+	}
+
+	role, appErr = s.roleRepo.Create(ctx, domain.NewRole(dto.NewRoleRequest{Name: name}))
+	if appErr != nil {
+		return nil, asError("creating role "+name, appErr)
+	}
+
+	return role, nil
+}
+
+// seedUser creates the user with a verified email, or returns the existing
+// one. The user is built either way so the random sequence doesn't depend on
+// what the database already holds.
+func (s *Seeder) seedUser(ctx context.Context, register domain.UserRegister, create func(context.Context, domain.UserRegister) (*domain.User, *errs.AppError)) (*domain.User, error) {
+	user, appErr := s.userRepo.FindByEmail(ctx, register.Email)
+	if appErr == nil {
+		return user, nil
+	}
+	if appErr.Code != http.StatusNotFound {
+		return nil, asError("finding user "+register.Email, appErr)
+	}
+
+	user, appErr = create(ctx, register)
+	if appErr != nil {
+		return nil, asError("creating user "+register.Email, appErr)
+	}
+
+	if appErr := s.userRepo.MarkEmailVerified(ctx, uint64(user.Id), user.Email); appErr != nil {
+		return nil, asError("verifying user "+register.Email, appErr)
+	}
+
+	return user, nil
+}
+
+func (s *Seeder) seedCatalog(ctx context.Context, profile Profile, summary *Summary) ([]*domain.Product, error) {
+	categories := make([]*domain.Category, 0, profile.Categories)
+	for i := 0; i < profile.Categories && i < len(categoryNames); i++ {
+		category, appErr := s.categoryRepo.Create(ctx, domain.NewCategory(dto.NewCategoryRequest{Name: categoryNames[i]}))
+		if appErr != nil {
+			return nil, asError("creating category "+categoryNames[i], appErr)
+		}
+		categories = append(categories, category)
+	}
+	summary.Categories = len(categories)
+
+	if len(categories) == 0 {
+		return nil, nil
+	}
+
+	products := make([]*domain.Product, 0, profile.Products)
+	for i := 0; i < profile.Products; i++ {
+		name := fmt.Sprintf("%s %s %s", pick(s.rng, productAdjectives), pick(s.rng, productMaterials), pick(s.rng, productNouns))
+
+		product := domain.NewProduct(dto.NewProductRequest{
+			Name:        name,
+			CategoryId:  pick(s.rng, categories).Id,
+			Description: s.description(),
+			// Between 1.99 and 499.99
+			Amount: int32(s.rng.IntN(499)*100 + 199),
+		})
+		product.UUID = s.uuid()
+
+		created, appErr := s.productRepo.Create(ctx, product)
+		if appErr != nil {
+			return nil, asError("creating product "+name, appErr)
+		}
+		products = append(products, created)
+	}
+	summary.Products = len(products)
+	logger.Info("Seeded catalog", zap.Int("categories", len(categories)), zap.Int("products", len(products)))
+
+	return products, nil
+}
+
+// seedOrder places an order for a few products the way the order service
+// does, at a random time in the history of the profile
+func (s *Seeder) seedOrder(ctx context.Context, profile Profile, customer *domain.User, products []*domain.Product) error {
+	placedAt := s.daysAgo(s.rng.IntN(profile.HistoryDays + 1)).Add(time.Duration(s.rng.IntN(24*60)) * time.Minute)
+
+	count := 1 + s.rng.IntN(4)
+	seen := make(map[int64]bool, count)
+
+	var total int32
+	items := make([]domain.OrderItem, 0, count)
+	for len(items) < count && len(seen) < len(products) {
+		product := pick(s.rng, products)
+		if seen[product.Id] {
+			continue
+		}
+		seen[product.Id] = true
+
+		quantity := int32(1 + s.rng.IntN(3))
+		total += product.Amount * quantity
+		items = append(items, domain.OrderItem{
+			ProductId: uint64(product.Id),
+			Quantity:  quantity,
+			Amount:    product.Amount,
+			CreatedAt: placedAt,
+			UpdatedAt: placedAt,
+		})
+	}
+
+	order := domain.Order{
+		UUID:       s.uuid(),
+		Status:     "pending",
+		Amount:     total,
+		UserId:     uint64(customer.Id),
+		ExternalId: s.uuid().String(),
+		CreatedAt:  placedAt,
+		UpdatedAt:  placedAt,
+		OrderItems: items,
+	}
+
+	if _, appErr := s.orderRepo.Create(ctx, order); appErr != nil {
+		return asError("creating order", appErr)
+	}
+
+	return nil
+}
+
+func (s *Seeder) description() string {
+	sentences := make([]string, 2+s.rng.IntN(2))
+	for i := range sentences {
+		sentences[i] = pick(s.rng, descriptionSentences)
+	}
+
+	return strings.Join(sentences, " ")
+}
+
+func (s *Seeder) daysAgo(days int) time.Time {
+	return s.today.AddDate(0, 0, -days)
+}
+
+// uuid draws from the seeded source, where the constructors would use a
+// random one
+func (s *Seeder) uuid() uuid.UUID {
+	id, err := uuid.NewRandomFromReader(s.source)
+	if err != nil {
+		// ChaCha8 reads never fail
+		panic(err)
+	}
+
+	return id
+}
+
+func pick[T any](rng *rand.Rand, values []T) T {
+	return values[rng.IntN(len(values))]
+}
+
+func asError(action string, err *errs.AppError) error {
+	return fmt.Errorf("%s: %s", action, err.Message)
+}
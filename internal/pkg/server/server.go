@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net/http"
+
+	"github.com/go-ms-project-store/internal/pkg/config"
+)
+
+// Server wraps http.Server with the configured timeouts and optional TLS
+type Server struct {
+	http  *http.Server
+	certs *CertReloader
+}
+
+// New builds a server for the handler. When TLS is configured the
+// certificate is loaded immediately so a bad pair fails at startup.
+func New(cfg config.Server, handler http.Handler) (*Server, error) {
+	srv := &Server{
+		http: &http.Server{
+			Addr:              cfg.Addr,
+			Handler:           handler,
+			ReadTimeout:       cfg.ReadTimeout,
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+			WriteTimeout:      cfg.WriteTimeout,
+			IdleTimeout:       cfg.IdleTimeout,
+			MaxHeaderBytes:    cfg.MaxHeaderBytes,
+		},
+	}
+
+	if cfg.TLSEnabled() {
+		certs, err := NewCertReloader(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		srv.certs = certs
+		srv.http.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: certs.GetCertificate,
+		}
+	}
+
+	return srv, nil
+}
+
+// ListenAndServe blocks until the server fails or is shut down. A shutdown
+// is not reported as an error.
+func (s *Server) ListenAndServe() error {
+	var err error
+	if s.certs != nil {
+		// The certificate comes from TLSConfig.GetCertificate
+		err = s.http.ListenAndServeTLS("", "")
+	} else {
+		err = s.http.ListenAndServe()
+	}
+
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
+	return err
+}
+
+// Shutdown stops accepting connections and waits for in-flight requests
+// until the context is done
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.http.Shutdown(ctx)
+}
+
+// ReloadTLS reads the certificate files again, it does nothing without TLS
+func (s *Server) ReloadTLS() error {
+	if s.certs == nil {
+		return nil
+	}
+
+	return s.certs.Reload()
+}
+
+// Addr returns the address the server listens on
+func (s *Server) Addr() string {
+	return s.http.Addr
+}